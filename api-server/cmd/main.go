@@ -18,6 +18,7 @@ import (
 	"github.com/bison/api-server/internal/opencost"
 	"github.com/bison/api-server/internal/scheduler"
 	"github.com/bison/api-server/internal/service"
+	"github.com/bison/api-server/internal/store"
 	"github.com/bison/api-server/pkg/logger"
 )
 
@@ -49,6 +50,14 @@ func main() {
 	}
 	logger.Info("Kubernetes client initialized")
 
+	// Initialize state store
+	st, err := store.New(cfg, k8sClient)
+	if err != nil {
+		logger.Fatal("Failed to create state store", "backend", cfg.StorageBackend, "error", err)
+	}
+	defer st.Close()
+	logger.Info("State store initialized", "backend", cfg.StorageBackend)
+
 	// Initialize OpenCost client
 	var opencostClient *opencost.Client
 	if cfg.OpenCostURL != "" {
@@ -61,22 +70,35 @@ func main() {
 	resourceSvc := service.NewResourceService(k8sClient, resourceConfigSvc)
 	tenantSvc := service.NewTenantService(k8sClient)
 	projectSvc := service.NewProjectService(k8sClient)
-	costSvc := service.NewCostService(cfg.OpenCostURL, k8sClient)
 	settingsSvc := service.NewSettingsService(cfg.PrometheusURL, cfg.OpenCostURL)
-	balanceSvc := service.NewBalanceService(k8sClient)
-	userSvc := service.NewUserService(k8sClient, opencostClient)
-	auditSvc := service.NewAuditService(k8sClient)
-	alertSvc := service.NewAlertService(k8sClient, balanceSvc)
-	billingSvc := service.NewBillingService(k8sClient, opencostClient, balanceSvc, tenantSvc, projectSvc, resourceConfigSvc)
-	reportSvc := service.NewReportService(opencostClient, tenantSvc, projectSvc, billingSvc)
+	balanceSvc := service.NewBalanceService(st)
+	userSvc := service.NewUserService(st, opencostClient)
+	auditSvc := service.NewAuditService(st)
+	alertSvc := service.NewAlertService(st, balanceSvc)
+	teamPricingSvc := service.NewTeamPricingService(st)
+	// Usage comes from OpenCost when configured, otherwise from built-in metering
+	var usageSource service.UsageSource
+	var meteringSvc *service.MeteringService
+	if cfg.UsageSource == service.UsageSourceOpenCost {
+		usageSource = service.NewOpenCostUsageSource(opencostClient)
+	} else {
+		meteringSvc = service.NewMeteringService(k8sClient, st, resourceConfigSvc, cfg.MeteringInterval, cfg.MeteringRetentionDays)
+		usageSource = meteringSvc
+	}
+	logger.Info("Usage source initialized", "source", usageSource.Name())
+	reservationSvc := service.NewReservationService(k8sClient, st, resourceConfigSvc, cfg.MeteringRetentionDays)
+	billingSvc := service.NewBillingService(k8sClient, st, usageSource, reservationSvc, balanceSvc, tenantSvc, projectSvc, resourceConfigSvc, teamPricingSvc, auditSvc, alertSvc)
+	costSvc := service.NewCostService(usageSource, opencostClient, k8sClient, billingSvc)
+	reportSvc := service.NewReportService(opencostClient, tenantSvc, projectSvc, billingSvc, balanceSvc)
+	invoiceSvc := service.NewInvoiceService(st, billingSvc, balanceSvc, tenantSvc, projectSvc)
 	nodeSvc := service.NewNodeService(k8sClient)
 	workloadSvc := service.NewWorkloadService(k8sClient)
-	initScriptSvc := service.NewInitScriptService(k8sClient)
-	onboardingSvc := service.NewOnboardingService(k8sClient, nodeSvc, initScriptSvc)
-	configTransferSvc := service.NewConfigTransferService(billingSvc, alertSvc, resourceConfigSvc, initScriptSvc)
+	initScriptSvc := service.NewInitScriptService(st)
+	onboardingSvc := service.NewOnboardingService(k8sClient, st, nodeSvc, initScriptSvc)
+	configTransferSvc := service.NewConfigTransferService(billingSvc, alertSvc, resourceConfigSvc, initScriptSvc, teamPricingSvc)
 
 	// Initialize scheduler
-	sched := scheduler.NewScheduler(billingSvc, balanceSvc, alertSvc)
+	sched := scheduler.NewScheduler(billingSvc, balanceSvc, alertSvc, invoiceSvc, meteringSvc, reservationSvc)
 
 	// Initialize status service (needs scheduler)
 	statusSvc := service.NewStatusService(
@@ -101,11 +123,13 @@ func main() {
 	statsHandler := handler.NewStatsHandler(k8sClient, tenantSvc, projectSvc, costSvc, resourceSvc, nodeSvc)
 	settingsHandler := handler.NewSettingsHandler(settingsSvc)
 	clusterHandler := handler.NewClusterHandler(k8sClient)
-	billingHandler := handler.NewBillingHandler(billingSvc, balanceSvc)
+	billingHandler := handler.NewBillingHandler(billingSvc, balanceSvc, teamPricingSvc, auditSvc)
+	admissionHandler := handler.NewAdmissionHandler(service.NewAdmissionService(k8sClient, billingSvc, projectSvc))
 	userHandler := handler.NewUserHandler(userSvc, tenantSvc, projectSvc)
 	auditHandler := handler.NewAuditHandler(auditSvc)
 	alertHandler := handler.NewAlertHandler(alertSvc)
 	reportHandler := handler.NewReportHandler(reportSvc)
+	invoiceHandler := handler.NewInvoiceHandler(invoiceSvc, auditSvc)
 	statusHandler := handler.NewStatusHandler(statusSvc)
 	nodeHandler := handler.NewNodeHandler(nodeSvc)
 	workloadHandler := handler.NewWorkloadHandler(workloadSvc, projectSvc)
@@ -174,11 +198,33 @@ func main() {
 			protected.GET("/teams/:name/balance", billingHandler.GetTeamBalance)
 			protected.POST("/teams/:name/recharge", billingHandler.RechargeTeam)
 			protected.GET("/teams/:name/balance/history", billingHandler.GetRechargeHistory)
+			protected.GET("/teams/:name/balance/ledger", billingHandler.GetLedger)
 			protected.GET("/teams/:name/bill", billingHandler.GetTeamBill)
 			protected.GET("/teams/:name/auto-recharge", billingHandler.GetAutoRechargeConfig)
 			protected.PUT("/teams/:name/auto-recharge", billingHandler.UpdateAutoRechargeConfig)
+			protected.POST("/teams/:name/adjustments", billingHandler.AdjustBalance)
+			protected.POST("/teams/:name/transfer", billingHandler.TransferBalance)
+			protected.GET("/teams/:name/grants", billingHandler.GetCreditGrants)
+			protected.POST("/teams/:name/grants", billingHandler.GrantCredit)
+			protected.GET("/teams/:name/credit-policy", billingHandler.GetCreditPolicy)
+			protected.PUT("/teams/:name/credit-policy", billingHandler.UpdateCreditPolicy)
+			protected.DELETE("/teams/:name/credit-policy", billingHandler.DeleteCreditPolicy)
+			protected.GET("/teams/:name/project-budgets", billingHandler.ListProjectBudgets)
+			protected.GET("/teams/:name/user-costs", billingHandler.GetTeamUserCosts)
+			protected.GET("/teams/:name/user-limits", billingHandler.ListUserLimits)
+			protected.PUT("/teams/:name/user-limits/:user", billingHandler.UpdateUserLimit)
+			protected.DELETE("/teams/:name/user-limits/:user", billingHandler.DeleteUserLimit)
 			protected.POST("/teams/:name/suspend", billingHandler.SuspendTeam)
 			protected.POST("/teams/:name/resume", billingHandler.ResumeTeam)
+			protected.GET("/teams/:name/suspension", billingHandler.GetSuspension)
+			protected.GET("/teams/:name/invoices", invoiceHandler.ListInvoices)
+			protected.POST("/teams/:name/invoices", invoiceHandler.GenerateInvoice)
+			protected.GET("/teams/:name/invoices/:number", invoiceHandler.GetInvoice)
+			protected.POST("/teams/:name/invoices/:number/finalize", invoiceHandler.FinalizeInvoice)
+			protected.GET("/teams/:name/invoices/:number/download", invoiceHandler.DownloadInvoice)
+			protected.GET("/teams/:name/pricing", billingHandler.GetTeamPricing)
+			protected.PUT("/teams/:name/pricing", billingHandler.UpdateTeamPricing)
+			protected.DELETE("/teams/:name/pricing", billingHandler.DeleteTeamPricing)
 
 			// Project management (Namespaces)
 			protected.GET("/projects", projectHandler.ListProjects)
@@ -187,6 +233,9 @@ func main() {
 			protected.PUT("/projects/:name", projectHandler.UpdateProject)
 			protected.DELETE("/projects/:name", projectHandler.DeleteProject)
 			protected.GET("/projects/:name/usage", projectHandler.GetProjectUsage)
+			protected.GET("/projects/:name/budget", billingHandler.GetProjectBudget)
+			protected.PUT("/projects/:name/budget", billingHandler.UpdateProjectBudget)
+			protected.DELETE("/projects/:name/budget", billingHandler.DeleteProjectBudget)
 
 			// Project workloads
 			protected.GET("/projects/:name/workloads", workloadHandler.ListWorkloads)
@@ -252,6 +301,12 @@ func main() {
 			protected.GET("/settings", settingsHandler.GetSettings)
 			protected.GET("/settings/billing", billingHandler.GetBillingConfig)
 			protected.PUT("/settings/billing", billingHandler.UpdateBillingConfig)
+			protected.GET("/settings/billing/state", billingHandler.GetBillingState)
+			protected.GET("/settings/billing/team-pricing", billingHandler.ListTeamPricing)
+			protected.GET("/billing/reconcile", billingHandler.ReconcileBalances)
+			protected.POST("/billing/reconcile/repair", billingHandler.RepairBalances)
+			protected.POST("/billing/simulate", billingHandler.SimulateBilling)
+			protected.POST("/billing/replay", billingHandler.ReplayBilling)
 			protected.GET("/settings/alerts", alertHandler.GetAlertConfig)
 			protected.PUT("/settings/alerts", alertHandler.UpdateAlertConfig)
 			protected.POST("/settings/alerts/test", alertHandler.TestChannel)
@@ -300,6 +355,20 @@ func main() {
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// The admission webhook is called by the Kubernetes API server, which requires TLS
+	var admissionServer *http.Server
+	if cfg.AdmissionCertFile != "" {
+		admissionRouter := gin.New()
+		admissionRouter.Use(middleware.Recovery())
+		admissionRouter.POST("/admission/mutate", admissionHandler.Mutate)
+		admissionServer = &http.Server{
+			Addr:         fmt.Sprintf(":%d", cfg.AdmissionPort),
+			Handler:      admissionRouter,
+			ReadTimeout:  10 * time.Second,
+			WriteTimeout: 10 * time.Second,
+		}
+	}
+
 	// Start scheduler
 	ctx, cancel := context.WithCancel(context.Background())
 	sched.Start(ctx)
@@ -312,6 +381,15 @@ func main() {
 		}
 	}()
 
+	if admissionServer != nil {
+		go func() {
+			logger.Info("Admission webhook started", "addr", admissionServer.Addr)
+			if err := admissionServer.ListenAndServeTLS(cfg.AdmissionCertFile, cfg.AdmissionKeyFile); err != nil && err != http.ErrServerClosed {
+				logger.Fatal("Admission webhook error", "error", err)
+			}
+		}()
+	}
+
 	// Wait for shutdown signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -326,6 +404,11 @@ func main() {
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer shutdownCancel()
 
+	if admissionServer != nil {
+		if err := admissionServer.Shutdown(shutdownCtx); err != nil {
+			logger.Error("Admission webhook forced to shutdown", "error", err)
+		}
+	}
 	if err := server.Shutdown(shutdownCtx); err != nil {
 		logger.Error("Server forced to shutdown", "error", err)
 		os.Exit(1)
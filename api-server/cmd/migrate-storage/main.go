@@ -0,0 +1,91 @@
+// Command migrate-storage copies Bison state from the ConfigMaps in
+// bison-system into the storage backend selected by STORAGE_BACKEND.
+// It is meant to be run once before switching the API server to the new
+// backend; ConfigMaps are left untouched.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/bison/api-server/internal/config"
+	"github.com/bison/api-server/internal/k8s"
+	"github.com/bison/api-server/internal/service"
+	"github.com/bison/api-server/internal/store"
+	"github.com/bison/api-server/pkg/logger"
+)
+
+func main() {
+	overwrite := flag.Bool("overwrite", false, "Replace collections that already hold data in the target store")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to load config:", err)
+		os.Exit(1)
+	}
+	logger.Init(cfg.Mode != "release")
+	defer logger.Sync()
+
+	if cfg.StorageBackend == store.BackendConfigMap {
+		logger.Fatal("STORAGE_BACKEND must name the target backend, not configmap")
+	}
+
+	k8sClient, err := k8s.NewClient()
+	if err != nil {
+		logger.Fatal("Failed to create k8s client", "error", err)
+	}
+
+	dst, err := store.New(cfg, k8sClient)
+	if err != nil {
+		logger.Fatal("Failed to open target store", "backend", cfg.StorageBackend, "error", err)
+	}
+	defer dst.Close()
+	src := store.NewConfigMapStore(k8sClient, store.Namespace)
+
+	// Metered usage and reserved nodes are kept in one collection per day
+	collections := append(append([]string{}, service.StoreCollections...), service.MeteringCollections(time.Now(), cfg.MeteringRetentionDays)...)
+	collections = append(collections, service.ReservationCollections(time.Now(), cfg.MeteringRetentionDays)...)
+
+	// Invoices are kept in one collection per team
+	ctx := context.Background()
+	invoices, err := src.Collections(ctx, service.InvoiceConfigMapPrefix)
+	if err != nil {
+		logger.Fatal("Failed to list invoice collections", "error", err)
+	}
+	collections = append(collections, invoices...)
+	if *overwrite {
+		// Clear the target collections so they end up with exactly what is copied
+		for _, collection := range collections {
+			err := dst.Modify(ctx, collection, func(data map[string]string) error {
+				for key := range data {
+					delete(data, key)
+				}
+				return nil
+			})
+			if err != nil {
+				logger.Fatal("Failed to clear target collection", "collection", collection, "error", err)
+			}
+		}
+	} else {
+		for _, collection := range collections {
+			data, err := dst.Load(ctx, collection)
+			if err != nil {
+				logger.Fatal("Failed to inspect target store", "collection", collection, "error", err)
+			}
+			if len(data) > 0 {
+				logger.Fatal("Target store already holds data, rerun with -overwrite to replace it", "collection", collection)
+			}
+		}
+	}
+
+	copied, err := store.Copy(ctx, src, dst, collections)
+	if err != nil {
+		logger.Fatal("Migration failed", "copied", copied, "error", err)
+	}
+
+	logger.Info("Migration complete", "backend", cfg.StorageBackend, "collections", len(collections), "keys", copied)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 )
 
 // Config holds the API server configuration
@@ -24,6 +25,20 @@ type Config struct {
 
 	// Feature toggles
 	CapsuleEnabled bool
+
+	// State storage
+	StorageBackend string // "configmap" or "postgres"
+	StorageDSN     string // Connection string for the postgres backend
+
+	// Usage metering
+	UsageSource           string        // "opencost" or "metering"
+	MeteringInterval      time.Duration // Pod request sampling cadence for built-in metering
+	MeteringRetentionDays int           // Days of metered usage and reserved node history to keep
+
+	// Admission webhook, served over TLS when a certificate is configured
+	AdmissionPort     int
+	AdmissionCertFile string
+	AdmissionKeyFile  string
 }
 
 // Load reads configuration from environment variables
@@ -38,6 +53,10 @@ func Load() (*Config, error) {
 		OpenCostURL:    "",
 		PrometheusURL:  "",
 		CapsuleEnabled: true,
+		StorageBackend: "configmap",
+		MeteringInterval:      time.Minute,
+		MeteringRetentionDays: 90,
+		AdmissionPort:         8443,
 	}
 
 	if port := os.Getenv("PORT"); port != "" {
@@ -79,5 +98,70 @@ func Load() (*Config, error) {
 		cfg.CapsuleEnabled = false
 	}
 
+	// State storage
+	if backend := os.Getenv("STORAGE_BACKEND"); backend != "" {
+		cfg.StorageBackend = backend
+	}
+	cfg.StorageDSN = os.Getenv("STORAGE_DSN")
+
+	switch cfg.StorageBackend {
+	case "configmap":
+	case "postgres":
+		if cfg.StorageDSN == "" {
+			return nil, fmt.Errorf("STORAGE_DSN is required for the postgres storage backend")
+		}
+	default:
+		return nil, fmt.Errorf("invalid STORAGE_BACKEND: %s", cfg.StorageBackend)
+	}
+
+	// Usage metering: OpenCost when configured, otherwise built-in metering
+	cfg.UsageSource = os.Getenv("USAGE_SOURCE")
+	if cfg.UsageSource == "" {
+		cfg.UsageSource = "metering"
+		if cfg.OpenCostURL != "" {
+			cfg.UsageSource = "opencost"
+		}
+	}
+	switch cfg.UsageSource {
+	case "metering":
+	case "opencost":
+		if cfg.OpenCostURL == "" {
+			return nil, fmt.Errorf("OPENCOST_URL is required for the opencost usage source")
+		}
+	default:
+		return nil, fmt.Errorf("invalid USAGE_SOURCE: %s", cfg.UsageSource)
+	}
+	if interval := os.Getenv("METERING_INTERVAL"); interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid METERING_INTERVAL: %v", err)
+		}
+		cfg.MeteringInterval = d
+	}
+	if cfg.MeteringInterval < 10*time.Second || time.Hour%cfg.MeteringInterval != 0 {
+		return nil, fmt.Errorf("METERING_INTERVAL must be at least 10s and divide an hour evenly")
+	}
+	if days := os.Getenv("METERING_RETENTION_DAYS"); days != "" {
+		d, err := strconv.Atoi(days)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid METERING_RETENTION_DAYS: %s", days)
+		}
+		cfg.MeteringRetentionDays = d
+	}
+
+	// Admission webhook
+	if port := os.Getenv("ADMISSION_PORT"); port != "" {
+		p, err := strconv.Atoi(port)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ADMISSION_PORT: %v", err)
+		}
+		cfg.AdmissionPort = p
+	}
+	cfg.AdmissionCertFile = os.Getenv("ADMISSION_TLS_CERT_FILE")
+	cfg.AdmissionKeyFile = os.Getenv("ADMISSION_TLS_KEY_FILE")
+	if (cfg.AdmissionCertFile == "") != (cfg.AdmissionKeyFile == "") {
+		return nil, fmt.Errorf("ADMISSION_TLS_CERT_FILE and ADMISSION_TLS_KEY_FILE must be set together")
+	}
+
 	return cfg, nil
 }
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	admissionv1 "k8s.io/api/admission/v1"
+
+	"github.com/bison/api-server/internal/service"
+)
+
+// AdmissionHandler serves the admission webhook called by the Kubernetes API server
+type AdmissionHandler struct {
+	admissionSvc *service.AdmissionService
+}
+
+// NewAdmissionHandler creates a new AdmissionHandler
+func NewAdmissionHandler(admissionSvc *service.AdmissionService) *AdmissionHandler {
+	return &AdmissionHandler{
+		admissionSvc: admissionSvc,
+	}
+}
+
+// Mutate answers an AdmissionReview for a pod or workload
+func (h *AdmissionHandler) Mutate(c *gin.Context) {
+	var review admissionv1.AdmissionReview
+	if err := c.ShouldBindJSON(&review); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if review.Request == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "admission review has no request"})
+		return
+	}
+
+	review.Response = h.admissionSvc.Review(c.Request.Context(), review.Request)
+	review.Request = nil
+
+	c.JSON(http.StatusOK, review)
+}
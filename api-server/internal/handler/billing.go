@@ -2,6 +2,7 @@ package handler
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -11,15 +12,19 @@ import (
 
 // BillingHandler handles billing-related requests
 type BillingHandler struct {
-	billingSvc *service.BillingService
-	balanceSvc *service.BalanceService
+	billingSvc     *service.BillingService
+	balanceSvc     *service.BalanceService
+	teamPricingSvc *service.TeamPricingService
+	auditSvc       *service.AuditService
 }
 
 // NewBillingHandler creates a new BillingHandler
-func NewBillingHandler(billingSvc *service.BillingService, balanceSvc *service.BalanceService) *BillingHandler {
+func NewBillingHandler(billingSvc *service.BillingService, balanceSvc *service.BalanceService, teamPricingSvc *service.TeamPricingService, auditSvc *service.AuditService) *BillingHandler {
 	return &BillingHandler{
-		billingSvc: billingSvc,
-		balanceSvc: balanceSvc,
+		billingSvc:     billingSvc,
+		balanceSvc:     balanceSvc,
+		teamPricingSvc: teamPricingSvc,
+		auditSvc:       auditSvc,
 	}
 }
 
@@ -51,11 +56,22 @@ func (h *BillingHandler) UpdateBillingConfig(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "config updated"})
 }
 
-// GetTeamBalance returns the balance for a team
+// GetBillingState returns the billing progress (last billed period end)
+func (h *BillingHandler) GetBillingState(c *gin.Context) {
+	state, err := h.billingSvc.GetBillingState(c.Request.Context())
+	if err != nil {
+		logger.Error("Failed to get billing state", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, state)
+}
+
+// GetTeamBalance returns the balance for a team with its effective credit policy
 func (h *BillingHandler) GetTeamBalance(c *gin.Context) {
 	teamName := c.Param("name")
 
-	balance, err := h.balanceSvc.GetBalance(c.Request.Context(), teamName)
+	balance, err := h.billingSvc.GetTeamBalance(c.Request.Context(), teamName)
 	if err != nil {
 		logger.Error("Failed to get balance", "team", teamName, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -92,6 +108,123 @@ func (h *BillingHandler) RechargeTeam(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "recharged successfully"})
 }
 
+// AdjustBalance records a refund, credit, manual charge or correction for a team
+func (h *BillingHandler) AdjustBalance(c *gin.Context) {
+	teamName := c.Param("name")
+
+	var adj service.Adjustment
+	if err := c.ShouldBindJSON(&adj); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := adj.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	operator := "admin"
+	if username, exists := c.Get("username"); exists {
+		operator = username.(string)
+	}
+
+	record, err := h.balanceSvc.Adjust(c.Request.Context(), teamName, &adj, operator)
+	if err != nil {
+		logger.Error("Failed to adjust balance", "team", teamName, "type", adj.Type, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, record)
+}
+
+// TransferBalance moves balance from a team to another team
+func (h *BillingHandler) TransferBalance(c *gin.Context) {
+	teamName := c.Param("name")
+
+	var req struct {
+		ToTeam string  `json:"toTeam" binding:"required"`
+		Amount float64 `json:"amount" binding:"required,gt=0"`
+		Reason string  `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	operator := "admin"
+	if username, exists := c.Get("username"); exists {
+		operator = username.(string)
+	}
+
+	record, err := h.balanceSvc.Transfer(c.Request.Context(), teamName, req.ToTeam, req.Amount, operator, req.Reason)
+	if err != nil {
+		logger.Error("Failed to transfer balance", "from", teamName, "to", req.ToTeam, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.auditSvc.LogAction(c.Request.Context(), operator, "transfer", "team", teamName, map[string]interface{}{
+		"toTeam": req.ToTeam,
+		"amount": req.Amount,
+		"reason": req.Reason,
+		"refId":  record.ID,
+	})
+
+	c.JSON(http.StatusOK, record)
+}
+
+// GrantCredit adds an expiring credit grant to a team
+func (h *BillingHandler) GrantCredit(c *gin.Context) {
+	teamName := c.Param("name")
+
+	var grant service.CreditGrant
+	if err := c.ShouldBindJSON(&grant); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := grant.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	operator := "admin"
+	if username, exists := c.Get("username"); exists {
+		operator = username.(string)
+	}
+
+	created, err := h.balanceSvc.GrantCredit(c.Request.Context(), teamName, &grant, operator)
+	if err != nil {
+		logger.Error("Failed to grant credit", "team", teamName, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.auditSvc.LogAction(c.Request.Context(), operator, "grant_credit", "team", teamName, map[string]interface{}{
+		"amount":    created.Amount,
+		"source":    created.Source,
+		"expiresAt": created.ExpiresAt,
+		"grantId":   created.ID,
+	})
+
+	c.JSON(http.StatusOK, created)
+}
+
+// GetCreditGrants returns the credit grants of a team
+func (h *BillingHandler) GetCreditGrants(c *gin.Context) {
+	teamName := c.Param("name")
+
+	grants, err := h.balanceSvc.GetCreditGrants(c.Request.Context(), teamName)
+	if err != nil {
+		logger.Error("Failed to get credit grants", "team", teamName, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": grants})
+}
+
 // GetRechargeHistory returns recharge history for a team
 func (h *BillingHandler) GetRechargeHistory(c *gin.Context) {
 	teamName := c.Param("name")
@@ -107,6 +240,99 @@ func (h *BillingHandler) GetRechargeHistory(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"items": history})
 }
 
+// GetLedger returns the full balance ledger for a team, including checkpoints
+func (h *BillingHandler) GetLedger(c *gin.Context) {
+	teamName := c.Param("name")
+
+	ledger, err := h.balanceSvc.GetLedger(c.Request.Context(), teamName)
+	if err != nil {
+		logger.Error("Failed to get ledger", "team", teamName, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": ledger})
+}
+
+// ReconcileBalances reports teams whose stored balance differs from their ledger
+func (h *BillingHandler) ReconcileBalances(c *gin.Context) {
+	report, err := h.balanceSvc.Reconcile(c.Request.Context(), false)
+	if err != nil {
+		logger.Error("Failed to reconcile balances", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// RepairBalances resets mismatched stored balances to their ledger sum
+func (h *BillingHandler) RepairBalances(c *gin.Context) {
+	report, err := h.balanceSvc.Reconcile(c.Request.Context(), true)
+	if err != nil {
+		logger.Error("Failed to repair balances", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// billingWindowRequest is a [start, end) window to simulate or replay billing for
+type billingWindowRequest struct {
+	Start  time.Time `json:"start" binding:"required"`
+	End    time.Time `json:"end" binding:"required"`
+	Reason string    `json:"reason"`
+}
+
+// SimulateBilling returns what billing would charge each team for a window, without charging
+func (h *BillingHandler) SimulateBilling(c *gin.Context) {
+	var req billingWindowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	run, err := h.billingSvc.SimulateBilling(c.Request.Context(), req.Start, req.End)
+	if err != nil {
+		logger.Error("Failed to simulate billing", "start", req.Start, "end", req.End, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
+
+// ReplayBilling charges usage for a past window that regular billing missed
+func (h *BillingHandler) ReplayBilling(c *gin.Context) {
+	var req billingWindowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	operator := "admin"
+	if username, exists := c.Get("username"); exists {
+		operator = username.(string)
+	}
+
+	run, err := h.billingSvc.ReplayBilling(c.Request.Context(), req.Start, req.End, operator, req.Reason)
+	if err != nil {
+		logger.Error("Failed to replay billing", "start", req.Start, "end", req.End, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	window := req.Start.Format(time.RFC3339) + "," + req.End.Format(time.RFC3339)
+	h.auditSvc.LogAction(c.Request.Context(), operator, "replay_billing", "billing", window, map[string]interface{}{
+		"reason": req.Reason,
+		"total":  run.Total,
+		"teams":  len(run.Teams),
+	})
+
+	c.JSON(http.StatusOK, run)
+}
+
 // GetTeamBill returns a bill for a team
 func (h *BillingHandler) GetTeamBill(c *gin.Context) {
 	teamName := c.Param("name")
@@ -146,7 +372,12 @@ func (h *BillingHandler) UpdateAutoRechargeConfig(c *gin.Context) {
 		return
 	}
 
-	if err := h.balanceSvc.SetAutoRechargeConfig(c.Request.Context(), teamName, &config); err != nil {
+	if err := config.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.balanceSvc.UpdateAutoRechargeConfig(c.Request.Context(), teamName, &config); err != nil {
 		logger.Error("Failed to update auto-recharge config", "team", teamName, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -155,24 +386,272 @@ func (h *BillingHandler) UpdateAutoRechargeConfig(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "config updated"})
 }
 
+// GetCreditPolicy returns the credit policy of a team
+func (h *BillingHandler) GetCreditPolicy(c *gin.Context) {
+	teamName := c.Param("name")
+
+	policy, err := h.balanceSvc.GetCreditPolicy(c.Request.Context(), teamName)
+	if err != nil {
+		logger.Error("Failed to get credit policy", "team", teamName, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if policy == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no credit policy for team"})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// UpdateCreditPolicy creates or replaces the credit policy of a team
+func (h *BillingHandler) UpdateCreditPolicy(c *gin.Context) {
+	teamName := c.Param("name")
+
+	var policy service.CreditPolicy
+	if err := c.ShouldBindJSON(&policy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := policy.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	operator := "admin"
+	if username, exists := c.Get("username"); exists {
+		operator = username.(string)
+	}
+
+	if err := h.balanceSvc.SetCreditPolicy(c.Request.Context(), teamName, &policy, operator); err != nil {
+		logger.Error("Failed to update credit policy", "team", teamName, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// DeleteCreditPolicy reverts a team to the default credit policy
+func (h *BillingHandler) DeleteCreditPolicy(c *gin.Context) {
+	teamName := c.Param("name")
+
+	if err := h.balanceSvc.DeleteCreditPolicy(c.Request.Context(), teamName); err != nil {
+		logger.Error("Failed to delete credit policy", "team", teamName, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "credit policy deleted"})
+}
+
+// ListProjectBudgets returns the budgets of a team's projects
+func (h *BillingHandler) ListProjectBudgets(c *gin.Context) {
+	teamName := c.Param("name")
+
+	budgets, err := h.billingSvc.ListProjectBudgets(c.Request.Context(), teamName)
+	if err != nil {
+		logger.Error("Failed to list project budgets", "team", teamName, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": budgets})
+}
+
+// GetProjectBudget returns the budget of a project
+func (h *BillingHandler) GetProjectBudget(c *gin.Context) {
+	projectName := c.Param("name")
+
+	budget, err := h.billingSvc.GetProjectBudget(c.Request.Context(), projectName)
+	if err != nil {
+		logger.Error("Failed to get project budget", "project", projectName, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if budget == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no budget for project"})
+		return
+	}
+
+	c.JSON(http.StatusOK, budget)
+}
+
+// UpdateProjectBudget creates or replaces the budget of a project
+func (h *BillingHandler) UpdateProjectBudget(c *gin.Context) {
+	projectName := c.Param("name")
+
+	var budget service.ProjectBudget
+	if err := c.ShouldBindJSON(&budget); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := budget.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	operator := "admin"
+	if username, exists := c.Get("username"); exists {
+		operator = username.(string)
+	}
+
+	updated, err := h.billingSvc.SetProjectBudget(c.Request.Context(), projectName, &budget, operator)
+	if err != nil {
+		logger.Error("Failed to update project budget", "project", projectName, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.auditSvc.LogAction(c.Request.Context(), operator, "set_project_budget", "project", projectName, map[string]interface{}{
+		"monthlyAmount": updated.MonthlyAmount,
+		"warnPercent":   updated.WarnPercent,
+		"hardStop":      updated.HardStop,
+	})
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteProjectBudget removes the budget of a project
+func (h *BillingHandler) DeleteProjectBudget(c *gin.Context) {
+	projectName := c.Param("name")
+
+	operator := "admin"
+	if username, exists := c.Get("username"); exists {
+		operator = username.(string)
+	}
+
+	if err := h.billingSvc.DeleteProjectBudget(c.Request.Context(), projectName, operator); err != nil {
+		logger.Error("Failed to delete project budget", "project", projectName, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.auditSvc.LogAction(c.Request.Context(), operator, "delete_project_budget", "project", projectName, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "project budget deleted"})
+}
+
+// GetTeamUserCosts returns a team's costs by the user who created each pod
+func (h *BillingHandler) GetTeamUserCosts(c *gin.Context) {
+	teamName := c.Param("name")
+	window := c.DefaultQuery("window", "month")
+
+	costs, err := h.billingSvc.GetTeamUserCosts(c.Request.Context(), teamName, window)
+	if err != nil {
+		logger.Error("Failed to get user costs", "team", teamName, "window", window, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": costs})
+}
+
+// ListUserLimits returns the per-user limits of a team
+func (h *BillingHandler) ListUserLimits(c *gin.Context) {
+	teamName := c.Param("name")
+
+	limits, err := h.billingSvc.GetUserLimits(c.Request.Context(), teamName)
+	if err != nil {
+		logger.Error("Failed to list user limits", "team", teamName, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": limits})
+}
+
+// UpdateUserLimit creates or replaces the limit of a user within a team
+func (h *BillingHandler) UpdateUserLimit(c *gin.Context) {
+	teamName := c.Param("name")
+	user := c.Param("user")
+
+	var limit service.UserLimit
+	if err := c.ShouldBindJSON(&limit); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := limit.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	operator := "admin"
+	if username, exists := c.Get("username"); exists {
+		operator = username.(string)
+	}
+
+	updated, err := h.billingSvc.SetUserLimit(c.Request.Context(), teamName, user, &limit, operator)
+	if err != nil {
+		logger.Error("Failed to update user limit", "team", teamName, "user", user, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.auditSvc.LogAction(c.Request.Context(), operator, "set_user_limit", "team", teamName, map[string]interface{}{
+		"user":         user,
+		"monthlySpend": updated.MonthlySpend,
+		"maxGPUs":      updated.MaxGPUs,
+		"enforce":      updated.Enforce,
+	})
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteUserLimit removes the limit of a user within a team
+func (h *BillingHandler) DeleteUserLimit(c *gin.Context) {
+	teamName := c.Param("name")
+	user := c.Param("user")
+
+	if err := h.billingSvc.DeleteUserLimit(c.Request.Context(), teamName, user); err != nil {
+		logger.Error("Failed to delete user limit", "team", teamName, "user", user, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	operator := "admin"
+	if username, exists := c.Get("username"); exists {
+		operator = username.(string)
+	}
+	h.auditSvc.LogAction(c.Request.Context(), operator, "delete_user_limit", "team", teamName, map[string]interface{}{
+		"user": user,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "user limit deleted"})
+}
+
 // SuspendTeam suspends a team
 func (h *BillingHandler) SuspendTeam(c *gin.Context) {
 	teamName := c.Param("name")
 
-	if err := h.billingSvc.SuspendTeam(c.Request.Context(), teamName); err != nil {
+	operator := "admin"
+	if username, exists := c.Get("username"); exists {
+		operator = username.(string)
+	}
+
+	result, err := h.billingSvc.SuspendTeam(c.Request.Context(), teamName, operator)
+	if err != nil {
 		logger.Error("Failed to suspend team", "team", teamName, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "team suspended"})
+	c.JSON(http.StatusOK, gin.H{"message": "team suspended", "result": result})
 }
 
 // ResumeTeam resumes a suspended team
 func (h *BillingHandler) ResumeTeam(c *gin.Context) {
 	teamName := c.Param("name")
 
-	if err := h.billingSvc.ResumeTeam(c.Request.Context(), teamName); err != nil {
+	operator := "admin"
+	if username, exists := c.Get("username"); exists {
+		operator = username.(string)
+	}
+
+	if err := h.billingSvc.ResumeTeam(c.Request.Context(), teamName, operator); err != nil {
 		logger.Error("Failed to resume team", "team", teamName, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -180,3 +659,90 @@ func (h *BillingHandler) ResumeTeam(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "team resumed"})
 }
+
+// GetSuspension returns what is currently stopped in a team's namespaces
+func (h *BillingHandler) GetSuspension(c *gin.Context) {
+	teamName := c.Param("name")
+
+	records, err := h.billingSvc.GetSuspensionRecords(c.Request.Context(), teamName)
+	if err != nil {
+		logger.Error("Failed to get suspension records", "team", teamName, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": records})
+}
+
+// ListTeamPricing returns all team pricing policies
+func (h *BillingHandler) ListTeamPricing(c *gin.Context) {
+	policies, err := h.teamPricingSvc.List(c.Request.Context())
+	if err != nil {
+		logger.Error("Failed to list team pricing", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": policies})
+}
+
+// GetTeamPricing returns the pricing policy of a team
+func (h *BillingHandler) GetTeamPricing(c *gin.Context) {
+	teamName := c.Param("name")
+
+	policy, err := h.teamPricingSvc.Get(c.Request.Context(), teamName)
+	if err != nil {
+		logger.Error("Failed to get team pricing", "team", teamName, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if policy == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no pricing policy for team"})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// UpdateTeamPricing creates or replaces the pricing policy of a team
+func (h *BillingHandler) UpdateTeamPricing(c *gin.Context) {
+	teamName := c.Param("name")
+
+	var policy service.TeamPricingPolicy
+	if err := c.ShouldBindJSON(&policy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	policy.Team = teamName
+
+	if err := policy.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	operator := "admin"
+	if username, exists := c.Get("username"); exists {
+		operator = username.(string)
+	}
+
+	if err := h.teamPricingSvc.Set(c.Request.Context(), &policy, operator); err != nil {
+		logger.Error("Failed to update team pricing", "team", teamName, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// DeleteTeamPricing removes the pricing policy of a team
+func (h *BillingHandler) DeleteTeamPricing(c *gin.Context) {
+	teamName := c.Param("name")
+
+	if err := h.teamPricingSvc.Delete(c.Request.Context(), teamName); err != nil {
+		logger.Error("Failed to delete team pricing", "team", teamName, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "pricing policy deleted"})
+}
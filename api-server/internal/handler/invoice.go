@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bison/api-server/internal/service"
+	"github.com/bison/api-server/pkg/logger"
+)
+
+// InvoiceHandler handles invoice-related requests
+type InvoiceHandler struct {
+	invoiceSvc *service.InvoiceService
+	auditSvc   *service.AuditService
+}
+
+// NewInvoiceHandler creates a new InvoiceHandler
+func NewInvoiceHandler(invoiceSvc *service.InvoiceService, auditSvc *service.AuditService) *InvoiceHandler {
+	return &InvoiceHandler{
+		invoiceSvc: invoiceSvc,
+		auditSvc:   auditSvc,
+	}
+}
+
+// ListInvoices returns a team's invoices
+func (h *InvoiceHandler) ListInvoices(c *gin.Context) {
+	teamName := c.Param("name")
+
+	invoices, err := h.invoiceSvc.ListInvoices(c.Request.Context(), teamName)
+	if err != nil {
+		logger.Error("Failed to list invoices", "team", teamName, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": invoices})
+}
+
+// GenerateInvoice creates or regenerates a team's invoice for a closed period
+func (h *InvoiceHandler) GenerateInvoice(c *gin.Context) {
+	teamName := c.Param("name")
+
+	var req struct {
+		Date string `json:"date"` // Any day in the period, YYYY-MM-DD; empty = last closed period
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	invoice, err := h.invoiceSvc.GenerateInvoice(c.Request.Context(), teamName, req.Date)
+	if err != nil {
+		logger.Error("Failed to generate invoice", "team", teamName, "date", req.Date, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, invoice)
+}
+
+// GetInvoice returns an invoice
+func (h *InvoiceHandler) GetInvoice(c *gin.Context) {
+	invoice, ok := h.loadInvoice(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, invoice)
+}
+
+// FinalizeInvoice locks an invoice so it can no longer be regenerated
+func (h *InvoiceHandler) FinalizeInvoice(c *gin.Context) {
+	teamName := c.Param("name")
+	number := c.Param("number")
+
+	operator := "admin"
+	if username, exists := c.Get("username"); exists {
+		operator = username.(string)
+	}
+
+	invoice, err := h.invoiceSvc.FinalizeInvoice(c.Request.Context(), teamName, number, operator)
+	if err != nil {
+		logger.Error("Failed to finalize invoice", "team", teamName, "number", number, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.auditSvc.LogAction(c.Request.Context(), operator, "finalize_invoice", "team", teamName, map[string]interface{}{
+		"number": invoice.Number,
+		"period": invoice.Period,
+		"total":  invoice.Total,
+	})
+
+	c.JSON(http.StatusOK, invoice)
+}
+
+// DownloadInvoice returns an invoice as a JSON, CSV or PDF file
+func (h *InvoiceHandler) DownloadInvoice(c *gin.Context) {
+	format := c.DefaultQuery("format", service.InvoiceFormatPDF)
+
+	invoice, ok := h.loadInvoice(c)
+	if !ok {
+		return
+	}
+
+	data, contentType, err := h.invoiceSvc.ExportInvoice(invoice, format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.%s", invoice.Number, format))
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// loadInvoice fetches the invoice named in the path, writing the error response if it fails
+func (h *InvoiceHandler) loadInvoice(c *gin.Context) (*service.Invoice, bool) {
+	teamName := c.Param("name")
+	number := c.Param("number")
+
+	invoice, err := h.invoiceSvc.GetInvoice(c.Request.Context(), teamName, number)
+	if err != nil {
+		logger.Error("Failed to get invoice", "team", teamName, "number", number, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if invoice == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "invoice not found"})
+		return nil, false
+	}
+	return invoice, true
+}
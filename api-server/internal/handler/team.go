@@ -73,13 +73,14 @@ func (h *TeamHandler) GetTeam(c *gin.Context) {
 // CreateTeam creates a new team
 func (h *TeamHandler) CreateTeam(c *gin.Context) {
 	var req struct {
-		Name           string             `json:"name" binding:"required"`
-		DisplayName    string             `json:"displayName"`
-		Description    string             `json:"description"`
-		Owners         []service.OwnerRef `json:"owners" binding:"required"`
-		Mode           service.TeamMode   `json:"mode"` // "shared" or "exclusive"
-		ExclusiveNodes []string           `json:"exclusiveNodes"`
-		Quota          map[string]string  `json:"quota"` // Dynamic quota
+		Name           string              `json:"name" binding:"required"`
+		DisplayName    string              `json:"displayName"`
+		Description    string              `json:"description"`
+		Owners         []service.OwnerRef  `json:"owners" binding:"required"`
+		Mode           service.TeamMode    `json:"mode"`        // "shared" or "exclusive"
+		BillingMode    service.BillingMode `json:"billingMode"` // "usage", "reservation" or "committed"
+		ExclusiveNodes []string            `json:"exclusiveNodes"`
+		Quota          map[string]string   `json:"quota"` // Dynamic quota
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -88,10 +89,13 @@ func (h *TeamHandler) CreateTeam(c *gin.Context) {
 		return
 	}
 
-	// Default to shared mode
+	// Default to shared mode and usage billing
 	if req.Mode == "" {
 		req.Mode = service.TeamModeShared
 	}
+	if req.BillingMode == "" {
+		req.BillingMode = service.BillingModeUsage
+	}
 
 	team := &service.Team{
 		Name:           req.Name,
@@ -99,6 +103,7 @@ func (h *TeamHandler) CreateTeam(c *gin.Context) {
 		Description:    req.Description,
 		Owners:         req.Owners,
 		Mode:           req.Mode,
+		BillingMode:    req.BillingMode,
 		ExclusiveNodes: req.ExclusiveNodes,
 		Quota:          req.Quota,
 	}
@@ -112,6 +117,10 @@ func (h *TeamHandler) CreateTeam(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "exclusive mode requires at least one node"})
 		return
 	}
+	if err := team.ValidateBillingMode(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	// Create the tenant first
 	if err := h.tenantSvc.Create(c.Request.Context(), team); err != nil {
@@ -138,12 +147,13 @@ func (h *TeamHandler) UpdateTeam(c *gin.Context) {
 	name := c.Param("name")
 
 	var req struct {
-		DisplayName    string             `json:"displayName"`
-		Description    string             `json:"description"`
-		Owners         []service.OwnerRef `json:"owners"`
-		Mode           service.TeamMode   `json:"mode"`
-		ExclusiveNodes []string           `json:"exclusiveNodes"`
-		Quota          map[string]string  `json:"quota"` // Dynamic quota
+		DisplayName    string              `json:"displayName"`
+		Description    string              `json:"description"`
+		Owners         []service.OwnerRef  `json:"owners"`
+		Mode           service.TeamMode    `json:"mode"`
+		BillingMode    service.BillingMode `json:"billingMode"`
+		ExclusiveNodes []string            `json:"exclusiveNodes"`
+		Quota          map[string]string   `json:"quota"` // Dynamic quota
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -160,10 +170,13 @@ func (h *TeamHandler) UpdateTeam(c *gin.Context) {
 		return
 	}
 
-	// Default mode if not specified
+	// Default modes if not specified
 	if req.Mode == "" {
 		req.Mode = existingTeam.Mode
 	}
+	if req.BillingMode == "" {
+		req.BillingMode = existingTeam.BillingMode
+	}
 
 	team := &service.Team{
 		Name:           name,
@@ -171,6 +184,7 @@ func (h *TeamHandler) UpdateTeam(c *gin.Context) {
 		Description:    req.Description,
 		Owners:         req.Owners,
 		Mode:           req.Mode,
+		BillingMode:    req.BillingMode,
 		ExclusiveNodes: req.ExclusiveNodes,
 		Quota:          req.Quota,
 	}
@@ -180,6 +194,10 @@ func (h *TeamHandler) UpdateTeam(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "exclusive mode requires at least one node"})
 		return
 	}
+	if err := team.ValidateBillingMode(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	// Handle node assignments based on mode change
 	if h.nodeSvc != nil {
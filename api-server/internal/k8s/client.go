@@ -2,9 +2,11 @@ package k8s
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
@@ -14,17 +16,19 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/retry"
 
 	"github.com/bison/api-server/pkg/logger"
 )
 
 // Client wraps Kubernetes client operations
 type Client struct {
-	clientset     *kubernetes.Clientset
+	clientset     kubernetes.Interface
 	dynamicClient dynamic.Interface
 }
 
@@ -70,6 +74,15 @@ func NewClient() (*Client, error) {
 	}, nil
 }
 
+// NewClientFromInterfaces creates a Client around existing clients, such as the fakes
+// used in tests
+func NewClientFromInterfaces(clientset kubernetes.Interface, dynamicClient dynamic.Interface) *Client {
+	return &Client{
+		clientset:     clientset,
+		dynamicClient: dynamicClient,
+	}
+}
+
 // Namespace operations
 
 func (c *Client) CreateNamespace(ctx context.Context, name string, labels map[string]string) error {
@@ -146,6 +159,12 @@ func (c *Client) DeleteJob(ctx context.Context, namespace, name string) error {
 	})
 }
 
+func (c *Client) UpdateJob(ctx context.Context, namespace string, job *batchv1.Job) error {
+	logger.Debug("K8s: Updating Job", "namespace", namespace, "name", job.Name)
+	_, err := c.clientset.BatchV1().Jobs(namespace).Update(ctx, job, metav1.UpdateOptions{})
+	return err
+}
+
 // CronJob operations
 
 func (c *Client) ListCronJobs(ctx context.Context, namespace string) (*batchv1.CronJobList, error) {
@@ -156,6 +175,12 @@ func (c *Client) GetCronJob(ctx context.Context, namespace, name string) (*batch
 	return c.clientset.BatchV1().CronJobs(namespace).Get(ctx, name, metav1.GetOptions{})
 }
 
+func (c *Client) UpdateCronJob(ctx context.Context, namespace string, cronJob *batchv1.CronJob) error {
+	logger.Debug("K8s: Updating CronJob", "namespace", namespace, "name", cronJob.Name)
+	_, err := c.clientset.BatchV1().CronJobs(namespace).Update(ctx, cronJob, metav1.UpdateOptions{})
+	return err
+}
+
 // Pod operations
 
 func (c *Client) ListPods(ctx context.Context, namespace, labelSelector string) (*corev1.PodList, error) {
@@ -208,6 +233,10 @@ func (c *Client) ListNodes(ctx context.Context) (*corev1.NodeList, error) {
 	return c.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
 }
 
+func (c *Client) ListPersistentVolumes(ctx context.Context) (*corev1.PersistentVolumeList, error) {
+	return c.clientset.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
+}
+
 func (c *Client) GetNode(ctx context.Context, name string) (*corev1.Node, error) {
 	return c.clientset.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
 }
@@ -504,6 +533,22 @@ func (c *Client) DeleteTenant(ctx context.Context, name string) error {
 	return c.dynamicClient.Resource(tenantGVR).Delete(ctx, name, metav1.DeleteOptions{})
 }
 
+// Custom resource operations (for suspend/resume of operator-managed workloads)
+
+func (c *Client) ListCustomResources(ctx context.Context, gvr schema.GroupVersionResource, namespace string) (*unstructured.UnstructuredList, error) {
+	return c.dynamicClient.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{})
+}
+
+func (c *Client) GetCustomResource(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, error) {
+	return c.dynamicClient.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+func (c *Client) UpdateCustomResource(ctx context.Context, gvr schema.GroupVersionResource, namespace string, obj *unstructured.Unstructured) error {
+	logger.Debug("K8s: Updating custom resource", "resource", gvr.Resource, "namespace", namespace, "name", obj.GetName())
+	_, err := c.dynamicClient.Resource(gvr).Namespace(namespace).Update(ctx, obj, metav1.UpdateOptions{})
+	return err
+}
+
 // ResourceQuota operations
 
 func (c *Client) CreateResourceQuota(ctx context.Context, namespace string, quota *corev1.ResourceQuota) error {
@@ -548,6 +593,10 @@ func (c *Client) GetConfigMap(ctx context.Context, namespace, name string) (*cor
 	return c.clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
 }
 
+func (c *Client) ListConfigMaps(ctx context.Context, namespace string) (*corev1.ConfigMapList, error) {
+	return c.clientset.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{})
+}
+
 func (c *Client) CreateConfigMap(ctx context.Context, namespace string, cm *corev1.ConfigMap) error {
 	logger.Debug("K8s: Creating ConfigMap", "namespace", namespace, "name", cm.Name)
 	_, err := c.clientset.CoreV1().ConfigMaps(namespace).Create(ctx, cm, metav1.CreateOptions{})
@@ -565,6 +614,63 @@ func (c *Client) DeleteConfigMap(ctx context.Context, namespace, name string) er
 	return c.clientset.CoreV1().ConfigMaps(namespace).Delete(ctx, name, metav1.DeleteOptions{})
 }
 
+// ConfigMapConflictRetry is the backoff used when a ConfigMap update loses a
+// resourceVersion race (or a create races with another creator)
+var ConfigMapConflictRetry = wait.Backoff{
+	Steps:    10,
+	Duration: 10 * time.Millisecond,
+	Factor:   1.5,
+	Jitter:   0.5,
+}
+
+// ModifyConfigMap performs an optimistic read-modify-write on a ConfigMap.
+// The ConfigMap is read fresh on every attempt and passed to mutate; the update
+// carries the read resourceVersion, so concurrent writers never overwrite each
+// other and a conflicting attempt is retried. A missing ConfigMap is created
+// with the given labels. mutate must be safe to call more than once.
+func (c *Client) ModifyConfigMap(ctx context.Context, namespace, name string, labels map[string]string, mutate func(cm *corev1.ConfigMap) error) error {
+	attempts := 0
+	err := retry.OnError(ConfigMapConflictRetry, isWriteRace, func() error {
+		attempts++
+
+		cm, err := c.GetConfigMap(ctx, namespace, name)
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				return err
+			}
+			cm = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+					Labels:    labels,
+				},
+			}
+		}
+		if cm.Data == nil {
+			cm.Data = make(map[string]string)
+		}
+
+		if err := mutate(cm); err != nil {
+			return err
+		}
+
+		if cm.ResourceVersion == "" {
+			return c.CreateConfigMap(ctx, namespace, cm)
+		}
+		return c.UpdateConfigMap(ctx, namespace, cm)
+	})
+	if err != nil && isWriteRace(err) {
+		logger.Warn("K8s: ConfigMap update conflict retries exhausted", "namespace", namespace, "name", name, "attempts", attempts)
+		return fmt.Errorf("ConfigMap %s/%s is being modified concurrently, gave up after %d attempts: %w", namespace, name, attempts, err)
+	}
+	return err
+}
+
+// isWriteRace reports whether a write failed because another writer got there first
+func isWriteRace(err error) bool {
+	return errors.IsConflict(err) || errors.IsAlreadyExists(err)
+}
+
 // Deployment operations (for suspend/resume)
 
 func (c *Client) ListDeployments(ctx context.Context, namespace string) (*appsv1.DeploymentList, error) {
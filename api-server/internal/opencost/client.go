@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/bison/api-server/pkg/logger"
@@ -33,6 +34,12 @@ func (c *Client) IsEnabled() bool {
 	return c.baseURL != ""
 }
 
+// Special allocation names used by OpenCost
+const (
+	IdleAllocation      = "__idle__"      // Cluster capacity not requested by any workload
+	UnmountedAllocation = "__unmounted__" // Volumes not mounted by any pod
+)
+
 // Allocation represents a cost allocation from OpenCost
 type Allocation struct {
 	Name            string          `json:"name"`
@@ -57,6 +64,45 @@ type Allocation struct {
 	NetworkCost     float64         `json:"networkCost"`
 	TotalCost       float64         `json:"totalCost"`
 	TotalEfficiency float64         `json:"totalEfficiency"`
+
+	PVs                  map[string]PVAllocation `json:"pvs"`                  // Keyed by "cluster=<c>:name=<pv>"
+	NetworkTransferBytes float64                 `json:"networkTransferBytes"` // Bytes sent
+	NetworkReceiveBytes  float64                 `json:"networkReceiveBytes"`  // Bytes received
+	LoadBalancerCost     float64                 `json:"loadBalancerCost"`
+	LoadBalancers        map[string]LBAllocation `json:"lbAllocations"` // Keyed by service
+
+	// Extended resource-hours by resource name. OpenCost reports accelerators only as
+	// GPUHours; this is set by sources that meter each resource, such as built-in metering.
+	ResourceHours map[string]float64 `json:"resourceHours,omitempty"`
+}
+
+// PVAllocation is the share of one persistent volume in an allocation
+type PVAllocation struct {
+	ByteHours float64 `json:"byteHours"`
+	Cost      float64 `json:"cost"`
+}
+
+// LBAllocation is the share of one load balancer in an allocation
+type LBAllocation struct {
+	Service string  `json:"service"`
+	Cost    float64 `json:"cost"`
+	Hours   float64 `json:"hours"`
+}
+
+// PVName extracts the persistent volume name from an allocation PV key
+func PVName(key string) string {
+	if i := strings.LastIndex(key, "name="); i >= 0 {
+		return key[i+len("name="):]
+	}
+	if i := strings.LastIndexAny(key, "/:"); i >= 0 {
+		return key[i+1:]
+	}
+	return key
+}
+
+// IsIdle reports whether an allocation name is an idle entry (optionally cluster-prefixed)
+func IsIdle(name string) bool {
+	return strings.HasSuffix(name, IdleAllocation)
 }
 
 // AllocationProps contains allocation properties
@@ -84,11 +130,31 @@ type AllocationResponse struct {
 	Message string                   `json:"message"`
 }
 
+// FormatWindow formats an explicit [start, end) window accepted by the OpenCost API
+func FormatWindow(start, end time.Time) string {
+	return start.UTC().Format(time.RFC3339) + "," + end.UTC().Format(time.RFC3339)
+}
+
 // GetAllocationByNamespace returns allocations aggregated by namespace
 func (c *Client) GetAllocationByNamespace(ctx context.Context, window string) ([]Allocation, error) {
 	return c.getAllocation(ctx, window, "namespace", "")
 }
 
+// GetAllocationByNamespaceWithIdle returns allocations aggregated by namespace, plus
+// the cluster's idle cost as IdleAllocation entries
+func (c *Client) GetAllocationByNamespaceWithIdle(ctx context.Context, window string) ([]Allocation, error) {
+	sets, err := c.queryAllocation(ctx, window, "namespace", "", queryOptions{includeIdle: true})
+	if err != nil {
+		return nil, err
+	}
+
+	var allocations []Allocation
+	for _, set := range sets {
+		allocations = append(allocations, flattenAllocations(set)...)
+	}
+	return allocations, nil
+}
+
 // GetAllocationByPod returns allocations aggregated by pod
 func (c *Client) GetAllocationByPod(ctx context.Context, window string) ([]Allocation, error) {
 	return c.getAllocation(ctx, window, "pod", "")
@@ -109,8 +175,76 @@ func (c *Client) GetAllocationForNamespace(ctx context.Context, window, namespac
 	return c.getAllocation(ctx, window, "namespace", fmt.Sprintf("namespace:\"%s\"", namespace))
 }
 
-// getAllocation is the internal method to query allocations
+// GetPodAllocationsForNamespace returns per-pod allocations within a specific namespace
+func (c *Client) GetPodAllocationsForNamespace(ctx context.Context, window, namespace string) ([]Allocation, error) {
+	return c.getAllocation(ctx, window, "pod", fmt.Sprintf("namespace:\"%s\"", namespace))
+}
+
+// AllocationStep holds the allocations of one step of a non-accumulated query
+type AllocationStep struct {
+	Start       time.Time
+	End         time.Time
+	Allocations []Allocation
+}
+
+// GetPodAllocationSteps returns per-pod allocations split into consecutive steps (e.g. "1h").
+// An empty namespace queries all namespaces.
+func (c *Client) GetPodAllocationSteps(ctx context.Context, window, namespace, step string) ([]AllocationStep, error) {
+	filter := ""
+	if namespace != "" {
+		filter = fmt.Sprintf("namespace:\"%s\"", namespace)
+	}
+
+	sets, err := c.queryAllocation(ctx, window, "pod", filter, queryOptions{step: step})
+	if err != nil {
+		return nil, err
+	}
+
+	var steps []AllocationStep
+	for _, set := range sets {
+		allocations := flattenAllocations(set)
+		if len(allocations) == 0 {
+			continue
+		}
+		first := allocations[0]
+		start, err := time.Parse(time.RFC3339, first.Start)
+		if err != nil {
+			start, err = time.Parse(time.RFC3339, first.Window.Start)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse allocation step start %q: %w", first.Start, err)
+			}
+		}
+		end, _ := time.Parse(time.RFC3339, first.End)
+		steps = append(steps, AllocationStep{Start: start, End: end, Allocations: allocations})
+	}
+
+	return steps, nil
+}
+
+// getAllocation is the internal method to query accumulated allocations
 func (c *Client) getAllocation(ctx context.Context, window, aggregate, filter string) ([]Allocation, error) {
+	sets, err := c.queryAllocation(ctx, window, aggregate, filter, queryOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	// Flatten the response
+	var allocations []Allocation
+	for _, set := range sets {
+		allocations = append(allocations, flattenAllocations(set)...)
+	}
+
+	return allocations, nil
+}
+
+// queryOptions are optional allocation query parameters
+type queryOptions struct {
+	step        string // Empty accumulates the whole window into one set; otherwise one set per step
+	includeIdle bool   // Include unallocated cluster cost as "__idle__" entries
+}
+
+// queryAllocation calls the allocation API
+func (c *Client) queryAllocation(ctx context.Context, window, aggregate, filter string, opts queryOptions) ([]map[string]*Allocation, error) {
 	if !c.IsEnabled() {
 		return nil, fmt.Errorf("opencost not configured")
 	}
@@ -119,7 +253,15 @@ func (c *Client) getAllocation(ctx context.Context, window, aggregate, filter st
 	params := url.Values{}
 	params.Set("window", window)
 	params.Set("aggregate", aggregate)
-	params.Set("accumulate", "true")
+	if opts.step == "" {
+		params.Set("accumulate", "true")
+	} else {
+		params.Set("accumulate", "false")
+		params.Set("step", opts.step)
+	}
+	if opts.includeIdle {
+		params.Set("includeIdle", "true")
+	}
 	if filter != "" {
 		params.Set("filter", filter)
 	}
@@ -153,22 +295,23 @@ func (c *Client) getAllocation(ctx context.Context, window, aggregate, filter st
 		return nil, fmt.Errorf("opencost error: %s", result.Message)
 	}
 
-	// Flatten the response
+	return result.Data, nil
+}
+
+// flattenAllocations converts one allocation set into a list
+func flattenAllocations(set map[string]*Allocation) []Allocation {
 	var allocations []Allocation
-	for _, dataMap := range result.Data {
-		for name, alloc := range dataMap {
-			if alloc != nil {
-				alloc.Name = name
-				// Calculate RAMGBHours from RAMByteHours
-				if alloc.RAMGBHours == 0 && alloc.RAMByteHours > 0 {
-					alloc.RAMGBHours = alloc.RAMByteHours / (1024 * 1024 * 1024)
-				}
-				allocations = append(allocations, *alloc)
+	for name, alloc := range set {
+		if alloc != nil {
+			alloc.Name = name
+			// Calculate RAMGBHours from RAMByteHours
+			if alloc.RAMGBHours == 0 && alloc.RAMByteHours > 0 {
+				alloc.RAMGBHours = alloc.RAMByteHours / (1024 * 1024 * 1024)
 			}
+			allocations = append(allocations, *alloc)
 		}
 	}
-
-	return allocations, nil
+	return allocations
 }
 
 // UsageSummary represents a summary of usage for display
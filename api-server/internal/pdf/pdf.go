@@ -0,0 +1,144 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// A4 page size and layout, in points
+const (
+	pageWidth  = 595.0
+	pageHeight = 842.0
+	margin     = 50.0
+)
+
+// Fonts are the standard PDF fonts, which every reader provides
+const (
+	fontRegular = "F1" // Helvetica
+	fontBold    = "F2" // Helvetica-Bold
+	fontMono    = "F3" // Courier, for aligned columns
+)
+
+type line struct {
+	font string
+	size float64
+	y    float64
+	text string
+}
+
+// Document is a minimal text-only PDF writer for generated documents such as invoices.
+// Lines flow top to bottom and onto new pages as needed. Text is encoded as WinAnsi,
+// so characters outside Latin-1 are replaced with "?".
+type Document struct {
+	pages [][]line
+	y     float64
+}
+
+// New creates an empty document
+func New() *Document {
+	d := &Document{}
+	d.newPage()
+	return d
+}
+
+// Title adds a large bold line
+func (d *Document) Title(text string) {
+	d.add(fontBold, 16, text)
+}
+
+// Heading adds a bold line
+func (d *Document) Heading(text string) {
+	d.add(fontBold, 11, text)
+}
+
+// Text adds a line of regular text
+func (d *Document) Text(text string) {
+	d.add(fontRegular, 10, text)
+}
+
+// Mono adds a line of fixed-width text; pad columns with fmt widths to align them
+func (d *Document) Mono(text string) {
+	d.add(fontMono, 9, text)
+}
+
+// Space adds vertical space
+func (d *Document) Space() {
+	d.y -= 8
+}
+
+// Bytes renders the document
+func (d *Document) Bytes() []byte {
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	// Objects 1-5 are the catalog, page tree and fonts; each page adds a page and a content stream
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 6+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /%s 3 0 R /%s 4 0 R /%s 5 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, fontRegular, fontBold, fontMono, 7+2*i))
+
+		var content bytes.Buffer
+		for _, l := range page {
+			fmt.Fprintf(&content, "BT /%s %.1f Tf %.1f %.1f Td (%s) Tj ET\n", l.font, l.size, margin, l.y, escape(l.text))
+		}
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.Bytes()
+}
+
+func (d *Document) add(font string, size float64, text string) {
+	height := size * 1.5
+	if d.y-height < margin {
+		d.newPage()
+	}
+	d.y -= height
+	d.pages[len(d.pages)-1] = append(d.pages[len(d.pages)-1], line{font: font, size: size, y: d.y, text: text})
+}
+
+func (d *Document) newPage() {
+	d.pages = append(d.pages, nil)
+	d.y = pageHeight - margin
+}
+
+// escape encodes text as a WinAnsi PDF string literal body
+func escape(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
@@ -2,6 +2,7 @@ package scheduler
 
 import (
 	"context"
+	"strings"
 	"sync"
 	"time"
 
@@ -11,9 +12,12 @@ import (
 
 // Scheduler handles scheduled tasks
 type Scheduler struct {
-	billingSvc *service.BillingService
-	balanceSvc *service.BalanceService
-	alertSvc   *service.AlertService
+	billingSvc     *service.BillingService
+	balanceSvc     *service.BalanceService
+	alertSvc       *service.AlertService
+	invoiceSvc     *service.InvoiceService
+	meteringSvc    *service.MeteringService // nil when usage comes from OpenCost
+	reservationSvc *service.ReservationService
 
 	executions   []service.TaskExecution
 	executionsMu sync.RWMutex
@@ -27,13 +31,19 @@ func NewScheduler(
 	billingSvc *service.BillingService,
 	balanceSvc *service.BalanceService,
 	alertSvc *service.AlertService,
+	invoiceSvc *service.InvoiceService,
+	meteringSvc *service.MeteringService,
+	reservationSvc *service.ReservationService,
 ) *Scheduler {
 	return &Scheduler{
-		billingSvc: billingSvc,
-		balanceSvc: balanceSvc,
-		alertSvc:   alertSvc,
-		executions: make([]service.TaskExecution, 0),
-		stopCh:     make(chan struct{}),
+		billingSvc:     billingSvc,
+		balanceSvc:     balanceSvc,
+		alertSvc:       alertSvc,
+		invoiceSvc:     invoiceSvc,
+		meteringSvc:    meteringSvc,
+		reservationSvc: reservationSvc,
+		executions:     make([]service.TaskExecution, 0),
+		stopCh:         make(chan struct{}),
 	}
 }
 
@@ -41,7 +51,7 @@ func NewScheduler(
 func (s *Scheduler) Start(ctx context.Context) {
 	logger.Info("Starting scheduler")
 
-	// Start billing task (every hour)
+	// Start billing task (on startup to catch up missed periods, then every 15 minutes)
 	s.wg.Add(1)
 	go s.runBillingTask(ctx)
 
@@ -52,6 +62,16 @@ func (s *Scheduler) Start(ctx context.Context) {
 	// Start alert check task (every 15 minutes)
 	s.wg.Add(1)
 	go s.runAlertTask(ctx)
+
+	// Start metering task (every metering interval) when usage is metered natively
+	if s.meteringSvc != nil {
+		s.wg.Add(1)
+		go s.runMeteringTask(ctx)
+	}
+
+	// Start reservation sampling task (every 5 minutes) for reservation billing
+	s.wg.Add(1)
+	go s.runReservationTask(ctx)
 }
 
 // Stop stops all scheduled tasks
@@ -90,7 +110,11 @@ func (s *Scheduler) GetExecutions(limit int) []service.TaskExecution {
 func (s *Scheduler) runBillingTask(ctx context.Context) {
 	defer s.wg.Done()
 
-	ticker := time.NewTicker(1 * time.Hour)
+	// Billing only charges closed periods past the persisted watermark,
+	// so running it often is safe and keeps catch-up latency low
+	s.executeBillingTask(ctx)
+
+	ticker := time.NewTicker(15 * time.Minute)
 	defer ticker.Stop()
 
 	for {
@@ -118,6 +142,11 @@ func (s *Scheduler) executeBillingTask(ctx context.Context) {
 			exec.Status = "failed"
 			exec.Error = err.Error()
 			logger.Error("Billing task failed", "error", err)
+		} else if err := s.invoiceSvc.GenerateDueInvoices(ctx); err != nil {
+			// Invoices are cut once billing has charged the whole period
+			exec.Status = "failed"
+			exec.Error = err.Error()
+			logger.Error("Invoice generation failed", "error", err)
 		} else {
 			logger.Info("Billing task completed")
 		}
@@ -194,10 +223,26 @@ func (s *Scheduler) executeAlertTask(ctx context.Context) {
 		exec.Status = "skipped"
 		exec.Error = "alert service not configured"
 	} else {
-		if err := s.alertSvc.CheckAndNotify(ctx); err != nil {
+		// Each check runs even if an earlier one fails
+		checks := []struct {
+			name string
+			run  func(ctx context.Context) error
+		}{
+			{"Alert check", s.alertSvc.CheckAndNotify},
+			{"Suspension countdown check", s.billingSvc.NotifySuspensionCountdowns},
+			{"Project budget check", s.billingSvc.CheckProjectBudgets},
+			{"User limit check", s.billingSvc.CheckUserLimits},
+		}
+		var failures []string
+		for _, check := range checks {
+			if err := check.run(ctx); err != nil {
+				logger.Error(check.name+" failed", "error", err)
+				failures = append(failures, check.name+": "+err.Error())
+			}
+		}
+		if len(failures) > 0 {
 			exec.Status = "failed"
-			exec.Error = err.Error()
-			logger.Error("Alert check task failed", "error", err)
+			exec.Error = strings.Join(failures, "; ")
 		} else {
 			logger.Debug("Alert check task completed")
 		}
@@ -207,6 +252,78 @@ func (s *Scheduler) executeAlertTask(ctx context.Context) {
 	s.recordExecution(exec)
 }
 
+func (s *Scheduler) runMeteringTask(ctx context.Context) {
+	defer s.wg.Done()
+
+	s.executeMeteringTask(ctx)
+
+	ticker := time.NewTicker(s.meteringSvc.Interval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			s.executeMeteringTask(ctx)
+		}
+	}
+}
+
+func (s *Scheduler) executeMeteringTask(ctx context.Context) {
+	start := time.Now()
+	err := s.meteringSvc.Sample(ctx)
+	if err == nil {
+		// Samples run every minute or so; only failures are recorded to keep the history readable
+		return
+	}
+
+	logger.Error("Metering task failed", "error", err)
+	s.recordExecution(service.TaskExecution{
+		TaskName:  "metering",
+		StartTime: start,
+		EndTime:   time.Now(),
+		Status:    "failed",
+		Error:     err.Error(),
+	})
+}
+
+func (s *Scheduler) runReservationTask(ctx context.Context) {
+	defer s.wg.Done()
+
+	s.executeReservationTask(ctx)
+
+	ticker := time.NewTicker(service.ReservationSampleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			s.executeReservationTask(ctx)
+		}
+	}
+}
+
+func (s *Scheduler) executeReservationTask(ctx context.Context) {
+	start := time.Now()
+	err := s.reservationSvc.Sample(ctx)
+	if err == nil {
+		// Like metering, only failures are recorded
+		return
+	}
+
+	logger.Error("Reservation sampling task failed", "error", err)
+	s.recordExecution(service.TaskExecution{
+		TaskName:  "reservation_sampling",
+		StartTime: start,
+		EndTime:   time.Now(),
+		Status:    "failed",
+		Error:     err.Error(),
+	})
+}
+
 func (s *Scheduler) recordExecution(exec service.TaskExecution) {
 	s.executionsMu.Lock()
 	defer s.executionsMu.Unlock()
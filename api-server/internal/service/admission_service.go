@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/bison/api-server/internal/k8s"
+	"github.com/bison/api-server/pkg/logger"
+)
+
+// patchOperation is one JSON patch operation
+type patchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// podTemplateKind is a workload kind that creates pods, or workloads, from a pod template
+type podTemplateKind struct {
+	resource       schema.GroupVersionResource
+	templateFields []string
+}
+
+// podTemplateKinds are the workload kinds the webhook attributes, by kind
+var podTemplateKinds = map[string]podTemplateKind{
+	"Deployment":  {schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}, []string{"spec", "template"}},
+	"StatefulSet": {schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "statefulsets"}, []string{"spec", "template"}},
+	"DaemonSet":   {schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "daemonsets"}, []string{"spec", "template"}},
+	"ReplicaSet":  {schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "replicasets"}, []string{"spec", "template"}},
+	"Job":         {schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "jobs"}, []string{"spec", "template"}},
+	"CronJob":     {schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "cronjobs"}, []string{"spec", "jobTemplate", "spec", "template"}},
+}
+
+// AdmissionService serves the mutating admission webhook that records who created each pod
+// and workload, and rejects pods from users over their enforced limits
+type AdmissionService struct {
+	k8sClient  *k8s.Client
+	billingSvc *BillingService
+	projectSvc *ProjectService
+}
+
+// NewAdmissionService creates a new AdmissionService
+func NewAdmissionService(k8sClient *k8s.Client, billingSvc *BillingService, projectSvc *ProjectService) *AdmissionService {
+	return &AdmissionService{
+		k8sClient:  k8sClient,
+		billingSvc: billingSvc,
+		projectSvc: projectSvc,
+	}
+}
+
+// Review handles an admission request. Pods and workloads get their creator as
+// AnnotationCreatedBy and LabelUser; workloads also get them on their pod template, so the
+// pods their controllers create are attributed to the same user. Values set by the requester
+// are overwritten, so users cannot attribute their pods to someone else. Errors never reject
+// a request, so attribution problems cannot stop workloads from starting.
+func (s *AdmissionService) Review(ctx context.Context, req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	response := &admissionv1.AdmissionResponse{UID: req.UID, Allowed: true}
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return response
+	}
+
+	var object map[string]interface{}
+	if err := json.Unmarshal(req.Object.Raw, &object); err != nil {
+		logger.Warn("Failed to decode admitted object", "kind", req.Kind.Kind, "namespace", req.Namespace, "error", err)
+		return response
+	}
+	metadata, _ := object["metadata"].(map[string]interface{})
+
+	creator, ok := s.creator(ctx, req, metadata)
+	if !ok {
+		return response
+	}
+
+	patch := attributionPatch("/metadata", metadata, creator)
+	if kind, ok := podTemplateKinds[req.Kind.Kind]; ok {
+		patch = append(patch, templatePatch(object, kind.templateFields, creator)...)
+	}
+
+	if req.Kind.Kind == "Pod" && req.Operation == admissionv1.Create && creator != "" {
+		if reason := s.admitPod(ctx, req, creator); reason != "" {
+			response.Allowed = false
+			response.Result = &metav1.Status{
+				Status:  metav1.StatusFailure,
+				Code:    http.StatusForbidden,
+				Reason:  metav1.StatusReasonForbidden,
+				Message: reason,
+			}
+			return response
+		}
+	}
+
+	if len(patch) > 0 {
+		raw, err := json.Marshal(patch)
+		if err != nil {
+			logger.Warn("Failed to marshal attribution patch", "kind", req.Kind.Kind, "error", err)
+			return response
+		}
+		patchType := admissionv1.PatchTypeJSONPatch
+		response.Patch = raw
+		response.PatchType = &patchType
+	}
+	return response
+}
+
+// creator returns who an admitted object is attributed to, or "" to clear any attribution
+// it carries; ok is false if the object is to be left alone.
+//   - Updates keep the creator recorded when the object was created.
+//   - Objects created by people are attributed to them, whatever they set themselves.
+//   - Objects created by controllers and service accounts take the creator from the pod
+//     template of the workload that owns them, which was attributed when it was created.
+//     Objects without such an owner, like the pods of operator custom resources, are not
+//     attributed.
+func (s *AdmissionService) creator(ctx context.Context, req *admissionv1.AdmissionRequest, metadata map[string]interface{}) (string, bool) {
+	if req.Operation == admissionv1.Update {
+		var old map[string]interface{}
+		if err := json.Unmarshal(req.OldObject.Raw, &old); err != nil {
+			logger.Warn("Failed to decode previous object", "kind", req.Kind.Kind, "namespace", req.Namespace, "error", err)
+			return "", false
+		}
+		oldMetadata, _ := old["metadata"].(map[string]interface{})
+		return annotationValue(oldMetadata, AnnotationCreatedBy), true
+	}
+
+	if attributable(req.UserInfo.Username) {
+		return req.UserInfo.Username, true
+	}
+	return s.ownerCreator(ctx, req.Namespace, metadata), true
+}
+
+// ownerCreator returns the creator recorded on the pod template of the workload controlling
+// an object, or "" if it has none
+func (s *AdmissionService) ownerCreator(ctx context.Context, namespace string, metadata map[string]interface{}) string {
+	owners, _ := metadata["ownerReferences"].([]interface{})
+	for _, item := range owners {
+		owner, _ := item.(map[string]interface{})
+		if controller, _ := owner["controller"].(bool); !controller {
+			continue
+		}
+
+		kindName, _ := owner["kind"].(string)
+		name, _ := owner["name"].(string)
+		apiVersion, _ := owner["apiVersion"].(string)
+		kind, ok := podTemplateKinds[kindName]
+		if !ok || apiVersion != kind.resource.GroupVersion().String() {
+			return ""
+		}
+
+		workload, err := s.k8sClient.GetCustomResource(ctx, kind.resource, namespace, name)
+		if err != nil {
+			logger.Warn("Failed to get owner for attribution", "kind", kindName, "namespace", namespace, "name", name, "error", err)
+			return ""
+		}
+		template := templateMetadata(workload.Object, kind.templateFields)
+		return annotationValue(template, AnnotationCreatedBy)
+	}
+	return ""
+}
+
+// admitPod checks a new pod against its creator's limits in the team owning the namespace,
+// returning why it is rejected or ""
+func (s *AdmissionService) admitPod(ctx context.Context, req *admissionv1.AdmissionRequest, creator string) string {
+	project, err := s.projectSvc.Get(ctx, req.Namespace)
+	if err != nil || project.Team == "" {
+		return ""
+	}
+
+	var pod corev1.Pod
+	if err := json.Unmarshal(req.Object.Raw, &pod); err != nil {
+		logger.Warn("Failed to decode admitted pod", "namespace", req.Namespace, "error", err)
+		return ""
+	}
+	pod.Namespace = req.Namespace
+
+	dryRun := req.DryRun != nil && *req.DryRun
+	reason, err := s.billingSvc.AdmitUserPod(ctx, project.Team, creator, &pod, dryRun)
+	if err != nil {
+		logger.Warn("Failed to check user limits at admission", "team", project.Team, "user", creator, "error", err)
+		return ""
+	}
+	return reason
+}
+
+// attributable reports whether a requesting user is a person to attribute objects to.
+// Control plane components and service accounts, which include the controllers and
+// operators that create pods on behalf of workloads, are not.
+func attributable(username string) bool {
+	return username != "" && !strings.HasPrefix(username, "system:")
+}
+
+// templatePatch records the creator on the pod template found at the given fields of a
+// workload, if it has one
+func templatePatch(object map[string]interface{}, templateFields []string, creator string) []patchOperation {
+	template := podTemplate(object, templateFields)
+	if template == nil {
+		return nil
+	}
+	metadata, _ := template["metadata"].(map[string]interface{})
+	return attributionPatch("/"+strings.Join(templateFields, "/")+"/metadata", metadata, creator)
+}
+
+// templateMetadata returns the metadata of the pod template at the given fields of a workload
+func templateMetadata(object map[string]interface{}, templateFields []string) map[string]interface{} {
+	metadata, _ := podTemplate(object, templateFields)["metadata"].(map[string]interface{})
+	return metadata
+}
+
+// podTemplate returns the pod template at the given fields of a workload, or nil
+func podTemplate(object map[string]interface{}, templateFields []string) map[string]interface{} {
+	for _, field := range templateFields {
+		next, ok := object[field].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		object = next
+	}
+	return object
+}
+
+// attributionPatch returns the operations that set the creator annotation and user label on
+// the object metadata at path, replacing any other values. An empty creator removes them.
+func attributionPatch(path string, metadata map[string]interface{}, creator string) []patchOperation {
+	if creator == "" {
+		var patch []patchOperation
+		for _, field := range []struct{ name, key string }{{"annotations", AnnotationCreatedBy}, {"labels", LabelUser}} {
+			existing, _ := metadata[field.name].(map[string]interface{})
+			if _, set := existing[field.key]; set {
+				patch = append(patch, patchOperation{Op: "remove", Path: path + "/" + field.name + "/" + escapePatchKey(field.key)})
+			}
+		}
+		return patch
+	}
+
+	if metadata == nil {
+		return []patchOperation{{Op: "add", Path: path, Value: map[string]interface{}{
+			"annotations": map[string]string{AnnotationCreatedBy: creator},
+			"labels":      map[string]string{LabelUser: UserLabelValue(creator)},
+		}}}
+	}
+
+	var patch []patchOperation
+	set := func(field, key, value string) {
+		existing, ok := metadata[field].(map[string]interface{})
+		if !ok {
+			patch = append(patch, patchOperation{Op: "add", Path: path + "/" + field, Value: map[string]string{key: value}})
+			return
+		}
+		current, present := existing[key]
+		switch {
+		case !present:
+			patch = append(patch, patchOperation{Op: "add", Path: path + "/" + field + "/" + escapePatchKey(key), Value: value})
+		case current != value:
+			patch = append(patch, patchOperation{Op: "replace", Path: path + "/" + field + "/" + escapePatchKey(key), Value: value})
+		}
+	}
+	set("annotations", AnnotationCreatedBy, creator)
+	set("labels", LabelUser, UserLabelValue(creator))
+	return patch
+}
+
+// annotationValue returns an annotation from decoded object metadata
+func annotationValue(metadata map[string]interface{}, key string) string {
+	annotations, _ := metadata["annotations"].(map[string]interface{})
+	value, _ := annotations[key].(string)
+	return value
+}
+
+// escapePatchKey escapes a map key for use in a JSON pointer
+func escapePatchKey(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/bison/api-server/internal/k8s"
+)
+
+const (
+	createdByPath = "/annotations/bison.io~1created-by"
+	userPath      = "/labels/bison.io~1user"
+)
+
+func TestAttributionPatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata map[string]interface{}
+		creator  string
+		want     []patchOperation
+	}{
+		{
+			name:    "no metadata",
+			creator: "alice",
+			want: []patchOperation{{Op: "add", Path: "/metadata", Value: map[string]interface{}{
+				"annotations": map[string]string{AnnotationCreatedBy: "alice"},
+				"labels":      map[string]string{LabelUser: UserLabelValue("alice")},
+			}}},
+		},
+		{
+			name:     "no annotations or labels",
+			metadata: map[string]interface{}{"name": "web"},
+			creator:  "alice",
+			want: []patchOperation{
+				{Op: "add", Path: "/metadata/annotations", Value: map[string]string{AnnotationCreatedBy: "alice"}},
+				{Op: "add", Path: "/metadata/labels", Value: map[string]string{LabelUser: UserLabelValue("alice")}},
+			},
+		},
+		{
+			name: "other annotations and labels",
+			metadata: map[string]interface{}{
+				"annotations": map[string]interface{}{"team": "a"},
+				"labels":      map[string]interface{}{"app": "web"},
+			},
+			creator: "alice",
+			want: []patchOperation{
+				{Op: "add", Path: "/metadata" + createdByPath, Value: "alice"},
+				{Op: "add", Path: "/metadata" + userPath, Value: UserLabelValue("alice")},
+			},
+		},
+		{
+			name: "preset values are overwritten",
+			metadata: map[string]interface{}{
+				"annotations": map[string]interface{}{AnnotationCreatedBy: "mallory"},
+				"labels":      map[string]interface{}{LabelUser: "mallory"},
+			},
+			creator: "alice",
+			want: []patchOperation{
+				{Op: "replace", Path: "/metadata" + createdByPath, Value: "alice"},
+				{Op: "replace", Path: "/metadata" + userPath, Value: UserLabelValue("alice")},
+			},
+		},
+		{
+			name: "values already set",
+			metadata: map[string]interface{}{
+				"annotations": map[string]interface{}{AnnotationCreatedBy: "alice"},
+				"labels":      map[string]interface{}{LabelUser: UserLabelValue("alice")},
+			},
+			creator: "alice",
+		},
+		{
+			name: "preset values are removed without a creator",
+			metadata: map[string]interface{}{
+				"annotations": map[string]interface{}{AnnotationCreatedBy: "mallory"},
+				"labels":      map[string]interface{}{LabelUser: "mallory"},
+			},
+			want: []patchOperation{
+				{Op: "remove", Path: "/metadata" + createdByPath},
+				{Op: "remove", Path: "/metadata" + userPath},
+			},
+		},
+		{
+			name:     "nothing to remove without a creator",
+			metadata: map[string]interface{}{"labels": map[string]interface{}{"app": "web"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := attributionPatch("/metadata", tt.metadata, tt.creator)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got patch %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReviewAttributesCreator(t *testing.T) {
+	deployment := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "web", "namespace": "ns-a"},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]interface{}{AnnotationCreatedBy: "alice"},
+				},
+			},
+		},
+	}}
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), deployment)
+	svc := NewAdmissionService(k8s.NewClientFromInterfaces(fake.NewSimpleClientset(), dynamicClient), nil, nil)
+
+	presetTemplate := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{AnnotationCreatedBy: "mallory"},
+			"labels":      map[string]interface{}{"app": "web"},
+		},
+	}
+	owner := func(apiVersion, kind, name string) []interface{} {
+		return []interface{}{map[string]interface{}{"apiVersion": apiVersion, "kind": kind, "name": name, "controller": true}}
+	}
+
+	tests := []struct {
+		name      string
+		operation admissionv1.Operation
+		kind      string
+		username  string
+		object    map[string]interface{}
+		old       map[string]interface{}
+		want      []patchOperation
+	}{
+		{
+			name:      "requester overwrites preset values",
+			operation: admissionv1.Create,
+			kind:      "Deployment",
+			username:  "bob",
+			object: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]interface{}{AnnotationCreatedBy: "mallory"},
+					"labels":      map[string]interface{}{LabelUser: "mallory"},
+				},
+				"spec": map[string]interface{}{"template": presetTemplate},
+			},
+			want: []patchOperation{
+				{Op: "replace", Path: "/metadata" + createdByPath, Value: "bob"},
+				{Op: "replace", Path: "/metadata" + userPath, Value: UserLabelValue("bob")},
+				{Op: "replace", Path: "/spec/template/metadata" + createdByPath, Value: "bob"},
+				{Op: "add", Path: "/spec/template/metadata" + userPath, Value: UserLabelValue("bob")},
+			},
+		},
+		{
+			name:      "controller takes the creator of the owning workload",
+			operation: admissionv1.Create,
+			kind:      "ReplicaSet",
+			username:  "system:serviceaccount:kube-system:deployment-controller",
+			object: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "web-1", "ownerReferences": owner("apps/v1", "Deployment", "web")},
+				"spec":     map[string]interface{}{"template": presetTemplate},
+			},
+			want: []patchOperation{
+				{Op: "add", Path: "/metadata/annotations", Value: map[string]string{AnnotationCreatedBy: "alice"}},
+				{Op: "add", Path: "/metadata/labels", Value: map[string]string{LabelUser: UserLabelValue("alice")}},
+				{Op: "replace", Path: "/spec/template/metadata" + createdByPath, Value: "alice"},
+				{Op: "add", Path: "/spec/template/metadata" + userPath, Value: UserLabelValue("alice")},
+			},
+		},
+		{
+			name:      "controller without an attributable owner clears preset values",
+			operation: admissionv1.Create,
+			kind:      "ReplicaSet",
+			username:  "system:serviceaccount:operators:example-operator",
+			object: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "db-1", "ownerReferences": owner("example.com/v1", "Database", "db")},
+				"spec":     map[string]interface{}{"template": presetTemplate},
+			},
+			want: []patchOperation{
+				{Op: "remove", Path: "/spec/template/metadata" + createdByPath},
+			},
+		},
+		{
+			name:      "update keeps the recorded creator",
+			operation: admissionv1.Update,
+			kind:      "Deployment",
+			username:  "bob",
+			object: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]interface{}{AnnotationCreatedBy: "bob"},
+					"labels":      map[string]interface{}{LabelUser: UserLabelValue("alice")},
+				},
+			},
+			old: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]interface{}{AnnotationCreatedBy: "alice"},
+				},
+			},
+			want: []patchOperation{
+				{Op: "replace", Path: "/metadata" + createdByPath, Value: "alice"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, _ := json.Marshal(tt.object)
+			req := &admissionv1.AdmissionRequest{
+				UID:       "1",
+				Kind:      metav1.GroupVersionKind{Kind: tt.kind},
+				Namespace: "ns-a",
+				Operation: tt.operation,
+				UserInfo:  authenticationv1.UserInfo{Username: tt.username},
+				Object:    runtime.RawExtension{Raw: raw},
+			}
+			if tt.old != nil {
+				oldRaw, _ := json.Marshal(tt.old)
+				req.OldObject = runtime.RawExtension{Raw: oldRaw}
+			}
+
+			response := svc.Review(context.Background(), req)
+			if !response.Allowed {
+				t.Fatalf("request was rejected: %v", response.Result)
+			}
+			want, _ := json.Marshal(tt.want)
+			if string(response.Patch) != string(want) {
+				t.Fatalf("got patch %s, want %s", response.Patch, want)
+			}
+		})
+	}
+}
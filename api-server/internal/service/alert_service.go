@@ -8,25 +8,33 @@ import (
 	"io"
 	"net/http"
 	"sort"
+	"strings"
 	"time"
 
-	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-
-	"github.com/bison/api-server/internal/k8s"
+	"github.com/bison/api-server/internal/store"
 	"github.com/bison/api-server/pkg/logger"
 )
 
 const (
 	AlertConfigConfigMap  = "bison-alert-config"
 	AlertHistoryConfigMap = "bison-alert-history"
-	MaxAlertHistory       = 1000
+	MaxAlertHistory       = 1000 // Kept when the store is bounded
+
+	// SuspensionNoticesConfigMap tracks the countdown notices sent to each overdue team
+	SuspensionNoticesConfigMap = "bison-suspension-notices"
 )
 
+// DefaultSuspensionCountdown is when owners are warned before suspension, in hours
+var DefaultSuspensionCountdown = []int{72, 24, 1}
+
 // AlertConfig represents alert configuration
 type AlertConfig struct {
 	BalanceThreshold float64         `json:"balanceThreshold"` // Alert when balance below this
 	Channels         []NotifyChannel `json:"channels"`
+
+	// Hours before suspension at which team owners are warned; null uses
+	// DefaultSuspensionCountdown, an empty list disables countdown notices
+	SuspensionCountdown []int `json:"suspensionCountdown"`
 }
 
 // NotifyChannel represents a notification channel
@@ -44,24 +52,32 @@ type Alert struct {
 	Timestamp time.Time `json:"timestamp"`
 	Type      string    `json:"type"`     // low_balance, suspended, etc.
 	Severity  string    `json:"severity"` // warning, critical
-	Target    string    `json:"target"`   // Team name
+	Target    string    `json:"target"`   // Team or project name
 	Message   string    `json:"message"`
 	Sent      bool      `json:"sent"`
 	SentAt    time.Time `json:"sentAt,omitempty"`
 	Channels  []string  `json:"channels,omitempty"` // Channels alert was sent to
+
+	Recipients []OwnerRef `json:"recipients,omitempty"` // Team owners the alert is addressed to
+}
+
+// suspensionNoticeState records the countdown notices sent since a team went overdue
+type suspensionNoticeState struct {
+	OverdueAt time.Time `json:"overdueAt"`
+	Notified  int       `json:"notified"` // Smallest countdown (hours) already notified
 }
 
 // AlertService handles alert operations
 type AlertService struct {
-	k8sClient  *k8s.Client
+	store      store.Store
 	balanceSvc *BalanceService
 	httpClient *http.Client
 }
 
 // NewAlertService creates a new AlertService
-func NewAlertService(k8sClient *k8s.Client, balanceSvc *BalanceService) *AlertService {
+func NewAlertService(st store.Store, balanceSvc *BalanceService) *AlertService {
 	return &AlertService{
-		k8sClient:  k8sClient,
+		store:      st,
 		balanceSvc: balanceSvc,
 		httpClient: &http.Client{Timeout: 10 * time.Second},
 	}
@@ -71,12 +87,12 @@ func NewAlertService(k8sClient *k8s.Client, balanceSvc *BalanceService) *AlertSe
 func (s *AlertService) GetConfig(ctx context.Context) (*AlertConfig, error) {
 	logger.Debug("Getting alert config")
 
-	cm, err := s.k8sClient.GetConfigMap(ctx, BisonNamespace, AlertConfigConfigMap)
+	entries, err := s.store.Load(ctx, AlertConfigConfigMap)
 	if err != nil {
 		return s.getDefaultConfig(), nil
 	}
 
-	data, ok := cm.Data["config"]
+	data, ok := entries["config"]
 	if !ok {
 		return s.getDefaultConfig(), nil
 	}
@@ -99,31 +115,10 @@ func (s *AlertService) SetConfig(ctx context.Context, config *AlertConfig) error
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
-	cm, err := s.k8sClient.GetConfigMap(ctx, BisonNamespace, AlertConfigConfigMap)
-	if err != nil {
-		// Create if not exists
-		cm = &corev1.ConfigMap{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      AlertConfigConfigMap,
-				Namespace: BisonNamespace,
-				Labels: map[string]string{
-					"app.kubernetes.io/name":      "bison",
-					"app.kubernetes.io/component": "alert",
-				},
-			},
-			Data: map[string]string{
-				"config": string(data),
-			},
-		}
-		return s.k8sClient.CreateConfigMap(ctx, BisonNamespace, cm)
-	}
-
-	if cm.Data == nil {
-		cm.Data = make(map[string]string)
-	}
-	cm.Data["config"] = string(data)
-
-	return s.k8sClient.UpdateConfigMap(ctx, BisonNamespace, cm)
+	return s.store.Modify(ctx, AlertConfigConfigMap, func(entries map[string]string) error {
+		entries["config"] = string(data)
+		return nil
+	})
 }
 
 // CheckAndNotify checks for alert conditions and sends notifications
@@ -170,6 +165,232 @@ func (s *AlertService) CheckAndNotify(ctx context.Context) error {
 	return nil
 }
 
+// NotifySuspensionCountdown warns a team's owners that it will be suspended at deadline.
+// Each countdown step is notified once per overdue period; when several steps have passed
+// since the last check only the closest one is sent.
+func (s *AlertService) NotifySuspensionCountdown(ctx context.Context, teamName string, owners []OwnerRef, overdueAt, deadline time.Time) error {
+	config, err := s.GetConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	countdown := config.SuspensionCountdown
+	if countdown == nil {
+		countdown = DefaultSuspensionCountdown
+	}
+
+	// Find the smallest countdown step already reached
+	remaining := time.Until(deadline)
+	step := 0
+	for _, hours := range countdown {
+		if hours > 0 && remaining <= time.Duration(hours)*time.Hour && (step == 0 || hours < step) {
+			step = hours
+		}
+	}
+	if step == 0 {
+		return nil
+	}
+
+	entries, err := s.store.Load(ctx, SuspensionNoticesConfigMap)
+	if err != nil {
+		return err
+	}
+	var state suspensionNoticeState
+	if data, ok := entries[teamName]; ok {
+		if err := json.Unmarshal([]byte(data), &state); err != nil {
+			logger.Warn("Ignoring invalid suspension notice state", "team", teamName, "error", err)
+		}
+	}
+	if !state.OverdueAt.Equal(overdueAt) {
+		state = suspensionNoticeState{OverdueAt: overdueAt}
+	}
+	if state.Notified != 0 && state.Notified <= step {
+		return nil
+	}
+
+	alert := &Alert{
+		ID:         fmt.Sprintf("%d", time.Now().UnixNano()),
+		Timestamp:  time.Now(),
+		Type:       "suspension_countdown",
+		Severity:   "warning",
+		Target:     teamName,
+		Message:    fmt.Sprintf("Team %s will be suspended in %s (at %s) unless its balance is topped up", teamName, formatRemaining(remaining), deadline.Local().Format("2006-01-02 15:04")),
+		Recipients: owners,
+	}
+	if err := s.SendAlert(ctx, config, alert); err != nil {
+		logger.Error("Failed to send suspension countdown", "team", teamName, "error", err)
+	}
+
+	state.Notified = step
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal suspension notice state: %w", err)
+	}
+	return s.store.Modify(ctx, SuspensionNoticesConfigMap, func(entries map[string]string) error {
+		entries[teamName] = string(data)
+		return nil
+	})
+}
+
+// NotifySuspended tells a team's owners that the team has been suspended
+func (s *AlertService) NotifySuspended(ctx context.Context, teamName string, owners []OwnerRef, stopped int) error {
+	config, err := s.GetConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	alert := &Alert{
+		ID:         fmt.Sprintf("%d", time.Now().UnixNano()),
+		Timestamp:  time.Now(),
+		Type:       "suspended",
+		Severity:   "critical",
+		Target:     teamName,
+		Message:    fmt.Sprintf("Team %s has been suspended and %d workloads were stopped. Top up its balance to resume.", teamName, stopped),
+		Recipients: owners,
+	}
+	return s.SendAlert(ctx, config, alert)
+}
+
+// NotifyResumed tells a team's owners that the team has been resumed
+func (s *AlertService) NotifyResumed(ctx context.Context, teamName string, owners []OwnerRef) error {
+	config, err := s.GetConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Start a fresh countdown the next time the team goes overdue
+	if err := s.store.Modify(ctx, SuspensionNoticesConfigMap, func(entries map[string]string) error {
+		delete(entries, teamName)
+		return nil
+	}); err != nil {
+		logger.Warn("Failed to clear suspension notice state", "team", teamName, "error", err)
+	}
+
+	alert := &Alert{
+		ID:         fmt.Sprintf("%d", time.Now().UnixNano()),
+		Timestamp:  time.Now(),
+		Type:       "resumed",
+		Severity:   "info",
+		Target:     teamName,
+		Message:    fmt.Sprintf("Team %s has been resumed and its workloads restored", teamName),
+		Recipients: owners,
+	}
+	return s.SendAlert(ctx, config, alert)
+}
+
+// NotifyProjectBudget tells a team's owners that a project is approaching or has reached its budget
+func (s *AlertService) NotifyProjectBudget(ctx context.Context, budget *ProjectBudget, owners []OwnerRef) error {
+	config, err := s.GetConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	alert := &Alert{
+		ID:         fmt.Sprintf("%d", time.Now().UnixNano()),
+		Timestamp:  time.Now(),
+		Type:       "project_budget_warning",
+		Severity:   "warning",
+		Target:     budget.Project,
+		Message:    fmt.Sprintf("Project %s of team %s has spent %.2f of its %.2f monthly budget", budget.Project, budget.Team, budget.Spent, budget.MonthlyAmount),
+		Recipients: owners,
+	}
+	if budget.Exceeded {
+		alert.Type = "project_budget_exceeded"
+		alert.Severity = "critical"
+		if budget.HardStop {
+			alert.Message += " and will be suspended until next month or until its budget is raised"
+		} else {
+			alert.Message += " and has exceeded it"
+		}
+	}
+	return s.SendAlert(ctx, config, alert)
+}
+
+// NotifyProjectSuspended tells a team's owners that a project was suspended by its budget
+func (s *AlertService) NotifyProjectSuspended(ctx context.Context, budget *ProjectBudget, owners []OwnerRef, stopped int) error {
+	config, err := s.GetConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	alert := &Alert{
+		ID:         fmt.Sprintf("%d", time.Now().UnixNano()),
+		Timestamp:  time.Now(),
+		Type:       "project_suspended",
+		Severity:   "critical",
+		Target:     budget.Project,
+		Message:    fmt.Sprintf("Project %s of team %s reached its %.2f monthly budget and %d workloads were stopped. Other projects of the team keep running.", budget.Project, budget.Team, budget.MonthlyAmount, stopped),
+		Recipients: owners,
+	}
+	return s.SendAlert(ctx, config, alert)
+}
+
+// NotifyProjectResumed tells a team's owners that a project suspended by its budget has been resumed
+func (s *AlertService) NotifyProjectResumed(ctx context.Context, budget *ProjectBudget, owners []OwnerRef) error {
+	config, err := s.GetConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	alert := &Alert{
+		ID:         fmt.Sprintf("%d", time.Now().UnixNano()),
+		Timestamp:  time.Now(),
+		Type:       "project_resumed",
+		Severity:   "info",
+		Target:     budget.Project,
+		Message:    fmt.Sprintf("Project %s of team %s has been resumed and its workloads restored", budget.Project, budget.Team),
+		Recipients: owners,
+	}
+	return s.SendAlert(ctx, config, alert)
+}
+
+// NotifyUserLimit tells a team's owners that one of its users reached a limit. reason
+// completes the sentence starting with the user's name.
+func (s *AlertService) NotifyUserLimit(ctx context.Context, teamName string, owners []OwnerRef, limit *UserLimit, reason string) error {
+	config, err := s.GetConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	alert := &Alert{
+		ID:         fmt.Sprintf("%d", time.Now().UnixNano()),
+		Timestamp:  time.Now(),
+		Type:       "user_limit_reached",
+		Severity:   "warning",
+		Target:     teamName,
+		Message:    fmt.Sprintf("User %s in team %s %s", limit.User, teamName, reason),
+		Recipients: owners,
+	}
+	if limit.Enforce {
+		alert.Severity = "critical"
+		alert.Message += ". New pods from the user are rejected while the limit is reached."
+	}
+	return s.SendAlert(ctx, config, alert)
+}
+
+// formatRemaining formats a countdown as days, hours or minutes
+func formatRemaining(d time.Duration) string {
+	switch {
+	case d <= 0:
+		return "less than a minute"
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd %dh", int(d.Hours())/24, int(d.Hours())%24)
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+}
+
+// recipientNames returns the names of an alert's recipients
+func recipientNames(alert *Alert) string {
+	names := make([]string, 0, len(alert.Recipients))
+	for _, owner := range alert.Recipients {
+		names = append(names, owner.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
 // SendAlert sends an alert through configured channels
 func (s *AlertService) SendAlert(ctx context.Context, config *AlertConfig, alert *Alert) error {
 	logger.Info("Sending alert", "type", alert.Type, "target", alert.Target)
@@ -215,12 +436,12 @@ func (s *AlertService) TestChannel(ctx context.Context, channel *NotifyChannel)
 func (s *AlertService) GetHistory(ctx context.Context, limit int) ([]*Alert, error) {
 	logger.Debug("Getting alert history", "limit", limit)
 
-	cm, err := s.k8sClient.GetConfigMap(ctx, BisonNamespace, AlertHistoryConfigMap)
+	entries, err := s.store.Load(ctx, AlertHistoryConfigMap)
 	if err != nil {
 		return []*Alert{}, nil
 	}
 
-	data, ok := cm.Data["history"]
+	data, ok := entries["history"]
 	if !ok {
 		return []*Alert{}, nil
 	}
@@ -280,6 +501,9 @@ func (s *AlertService) sendWebhook(ctx context.Context, channel *NotifyChannel,
 		"message":   alert.Message,
 		"timestamp": alert.Timestamp,
 	}
+	if len(alert.Recipients) > 0 {
+		payload["recipients"] = alert.Recipients
+	}
 
 	data, _ := json.Marshal(payload)
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(data))
@@ -308,10 +532,14 @@ func (s *AlertService) sendDingtalk(ctx context.Context, channel *NotifyChannel,
 		return fmt.Errorf("dingtalk webhook not configured")
 	}
 
+	content := fmt.Sprintf("[%s] %s\n%s", alert.Severity, alert.Type, alert.Message)
+	if len(alert.Recipients) > 0 {
+		content += "\nTo: " + recipientNames(alert)
+	}
 	payload := map[string]interface{}{
 		"msgtype": "text",
 		"text": map[string]string{
-			"content": fmt.Sprintf("[%s] %s\n%s", alert.Severity, alert.Type, alert.Message),
+			"content": content,
 		},
 	}
 
@@ -342,10 +570,14 @@ func (s *AlertService) sendWechat(ctx context.Context, channel *NotifyChannel, a
 		return fmt.Errorf("wechat webhook not configured")
 	}
 
+	content := fmt.Sprintf("[%s] %s\n%s", alert.Severity, alert.Type, alert.Message)
+	if len(alert.Recipients) > 0 {
+		content += "\nTo: " + recipientNames(alert)
+	}
 	payload := map[string]interface{}{
 		"msgtype": "text",
 		"text": map[string]string{
-			"content": fmt.Sprintf("[%s] %s\n%s", alert.Severity, alert.Type, alert.Message),
+			"content": content,
 		},
 	}
 
@@ -373,47 +605,24 @@ func (s *AlertService) sendWechat(ctx context.Context, channel *NotifyChannel, a
 func (s *AlertService) sendEmail(ctx context.Context, channel *NotifyChannel, alert *Alert) error {
 	// Email sending requires SMTP configuration
 	// For now, just log
-	logger.Info("Email alert would be sent", "to", channel.Config["to"], "message", alert.Message)
+	logger.Info("Email alert would be sent", "to", channel.Config["to"], "recipients", recipientNames(alert), "message", alert.Message)
 	return nil
 }
 
 func (s *AlertService) recordAlert(ctx context.Context, alert *Alert) error {
-	cm, err := s.k8sClient.GetConfigMap(ctx, BisonNamespace, AlertHistoryConfigMap)
-	if err != nil {
-		// Create if not exists
-		cm = &corev1.ConfigMap{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      AlertHistoryConfigMap,
-				Namespace: BisonNamespace,
-				Labels: map[string]string{
-					"app.kubernetes.io/name":      "bison",
-					"app.kubernetes.io/component": "alert",
-				},
-			},
-			Data: map[string]string{
-				"history": "[]",
-			},
+	return s.store.Modify(ctx, AlertHistoryConfigMap, func(entries map[string]string) error {
+		var alerts []*Alert
+		if data, ok := entries["history"]; ok {
+			json.Unmarshal([]byte(data), &alerts)
 		}
-		if err := s.k8sClient.CreateConfigMap(ctx, BisonNamespace, cm); err != nil {
-			return err
-		}
-	}
 
-	var alerts []*Alert
-	if data, ok := cm.Data["history"]; ok {
-		json.Unmarshal([]byte(data), &alerts)
-	}
-
-	alerts = append(alerts, alert)
-	if len(alerts) > MaxAlertHistory {
-		alerts = alerts[len(alerts)-MaxAlertHistory:]
-	}
-
-	data, _ := json.Marshal(alerts)
-	if cm.Data == nil {
-		cm.Data = make(map[string]string)
-	}
-	cm.Data["history"] = string(data)
+		alerts = append(alerts, alert)
+		if s.store.Bounded() && len(alerts) > MaxAlertHistory {
+			alerts = alerts[len(alerts)-MaxAlertHistory:]
+		}
 
-	return s.k8sClient.UpdateConfigMap(ctx, BisonNamespace, cm)
+		data, _ := json.Marshal(alerts)
+		entries["history"] = string(data)
+		return nil
+	})
 }
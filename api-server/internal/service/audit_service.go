@@ -7,16 +7,13 @@ import (
 	"sort"
 	"time"
 
-	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-
-	"github.com/bison/api-server/internal/k8s"
+	"github.com/bison/api-server/internal/store"
 	"github.com/bison/api-server/pkg/logger"
 )
 
 const (
 	AuditLogsConfigMap = "bison-audit-logs"
-	MaxAuditLogs       = 10000
+	MaxAuditLogs       = 10000 // Kept when the store is bounded
 )
 
 // AuditLog represents an audit log entry
@@ -53,13 +50,13 @@ type AuditPage struct {
 
 // AuditService handles audit logging
 type AuditService struct {
-	k8sClient *k8s.Client
+	store store.Store
 }
 
 // NewAuditService creates a new AuditService
-func NewAuditService(k8sClient *k8s.Client) *AuditService {
+func NewAuditService(st store.Store) *AuditService {
 	return &AuditService{
-		k8sClient: k8sClient,
+		store: st,
 	}
 }
 
@@ -74,53 +71,45 @@ func (s *AuditService) Log(ctx context.Context, log *AuditLog) error {
 		log.Timestamp = time.Now()
 	}
 
-	cm, err := s.getOrCreateConfigMap(ctx)
-	if err != nil {
-		return err
-	}
-
-	// Get existing logs
-	var logs []*AuditLog
-	if data, ok := cm.Data["logs"]; ok {
-		if err := json.Unmarshal([]byte(data), &logs); err != nil {
-			logger.Warn("Failed to unmarshal existing audit logs, starting fresh")
-			logs = []*AuditLog{}
+	return s.store.Modify(ctx, AuditLogsConfigMap, func(entries map[string]string) error {
+		// Get existing logs
+		var logs []*AuditLog
+		if data, ok := entries["logs"]; ok {
+			if err := json.Unmarshal([]byte(data), &logs); err != nil {
+				logger.Warn("Failed to unmarshal existing audit logs, starting fresh")
+				logs = []*AuditLog{}
+			}
 		}
-	}
-
-	// Add new log
-	logs = append(logs, log)
-
-	// Keep only last MaxAuditLogs
-	if len(logs) > MaxAuditLogs {
-		logs = logs[len(logs)-MaxAuditLogs:]
-	}
 
-	// Save back
-	data, err := json.Marshal(logs)
-	if err != nil {
-		return fmt.Errorf("failed to marshal logs: %w", err)
-	}
+		// Add new log
+		logs = append(logs, log)
 
-	if cm.Data == nil {
-		cm.Data = make(map[string]string)
-	}
-	cm.Data["logs"] = string(data)
+		// Keep only last MaxAuditLogs
+		if s.store.Bounded() && len(logs) > MaxAuditLogs {
+			logs = logs[len(logs)-MaxAuditLogs:]
+		}
 
-	return s.k8sClient.UpdateConfigMap(ctx, BisonNamespace, cm)
+		// Save back
+		data, err := json.Marshal(logs)
+		if err != nil {
+			return fmt.Errorf("failed to marshal logs: %w", err)
+		}
+		entries["logs"] = string(data)
+		return nil
+	})
 }
 
 // Query queries audit logs with filters and pagination
 func (s *AuditService) Query(ctx context.Context, filter *AuditFilter, page, pageSize int) (*AuditPage, error) {
 	logger.Debug("Querying audit logs", "filter", filter, "page", page, "pageSize", pageSize)
 
-	cm, err := s.getOrCreateConfigMap(ctx)
+	entries, err := s.store.Load(ctx, AuditLogsConfigMap)
 	if err != nil {
 		return nil, err
 	}
 
 	var logs []*AuditLog
-	if data, ok := cm.Data["logs"]; ok {
+	if data, ok := entries["logs"]; ok {
 		if err := json.Unmarshal([]byte(data), &logs); err != nil {
 			logger.Error("Failed to unmarshal audit logs", "error", err)
 			return &AuditPage{Items: []*AuditLog{}, Total: 0}, nil
@@ -226,28 +215,3 @@ func (s *AuditService) matchesFilter(log *AuditLog, filter *AuditFilter) bool {
 
 	return true
 }
-
-func (s *AuditService) getOrCreateConfigMap(ctx context.Context) (*corev1.ConfigMap, error) {
-	cm, err := s.k8sClient.GetConfigMap(ctx, BisonNamespace, AuditLogsConfigMap)
-	if err != nil {
-		// Create if not exists
-		cm = &corev1.ConfigMap{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      AuditLogsConfigMap,
-				Namespace: BisonNamespace,
-				Labels: map[string]string{
-					"app.kubernetes.io/name":      "bison",
-					"app.kubernetes.io/component": "audit",
-				},
-			},
-			Data: map[string]string{
-				"logs": "[]",
-			},
-		}
-		if err := s.k8sClient.CreateConfigMap(ctx, BisonNamespace, cm); err != nil {
-			return nil, fmt.Errorf("failed to create configmap: %w", err)
-		}
-	}
-
-	return cm, nil
-}
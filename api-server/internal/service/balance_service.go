@@ -3,15 +3,13 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"sort"
 	"time"
 
-	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-
-	"github.com/bison/api-server/internal/k8s"
+	"github.com/bison/api-server/internal/store"
 	"github.com/bison/api-server/pkg/logger"
 )
 
@@ -20,9 +18,52 @@ const (
 	BalancesConfigMap        = "bison-team-balances"
 	RechargeHistoryConfigMap = "bison-recharge-history"
 	AutoRechargeConfigMap    = "bison-auto-recharge"
+	CreditPoliciesConfigMap  = "bison-credit-policies"
 	BisonNamespace           = "bison-system"
+
+	// MaxLedgerEntries is the number of ledger entries kept per team before
+	// older entries are folded into a checkpoint, when the store is bounded
+	MaxLedgerEntries = 1000
 )
 
+// Ledger entry types
+const (
+	RecordTypeRecharge     = "recharge"
+	RecordTypeDeduction    = "deduction"
+	RecordTypeAutoRecharge = "auto_recharge"
+	RecordTypeCheckpoint   = "checkpoint" // Folded sum of older entries
+
+	// Adjustments made by an operator
+	RecordTypeRefund       = "refund"        // Returns part or all of a charge
+	RecordTypeCredit       = "credit"        // Goodwill or compensation credit
+	RecordTypeManualCharge = "manual_charge" // Charge outside usage billing
+	RecordTypeCorrection   = "correction"    // Signed fix for a wrong entry
+
+	// Transfers between teams; each side references the other side's entry
+	RecordTypeTransferOut = "transfer_out"
+	RecordTypeTransferIn  = "transfer_in"
+
+	// Expiring credit grants
+	RecordTypeGrant       = "grant"
+	RecordTypeGrantExpiry = "grant_expiry"
+
+	// RecordTypeBillingReplay charges usage for a past window that regular billing missed
+	RecordTypeBillingReplay = "billing_replay"
+)
+
+// IsAdjustment reports whether a ledger entry type is an operator adjustment
+func IsAdjustment(recordType string) bool {
+	switch recordType {
+	case RecordTypeRefund, RecordTypeCredit, RecordTypeManualCharge, RecordTypeCorrection:
+		return true
+	}
+	return false
+}
+
+// ledgerTolerance is the largest difference between stored balance and
+// ledger sum that is treated as floating point noise
+const ledgerTolerance = 0.005
+
 // Balance represents a team's balance
 type Balance struct {
 	TeamName           string     `json:"teamName"`
@@ -32,39 +73,159 @@ type Balance struct {
 	EstimatedOverdueAt *time.Time `json:"estimatedOverdueAt,omitempty"` // Predicted time when balance will go negative
 	DailyConsumption   float64    `json:"dailyConsumption,omitempty"`   // Average daily consumption
 	GraceRemaining     string     `json:"graceRemaining,omitempty"`     // Remaining grace period (e.g., "2天 3小时")
+
+	// Effective credit policy, the team's override or the global defaults
+	CreditLimit      float64 `json:"creditLimit,omitempty"`      // Overdraft allowed below zero
+	GracePeriodValue int     `json:"gracePeriodValue,omitempty"` // Grace period once over the credit limit
+	GracePeriodUnit  string  `json:"gracePeriodUnit,omitempty"`  // "hours" or "days"
+	NeverSuspend     bool    `json:"neverSuspend,omitempty"`     // Never suspended automatically
+
+	// Split of Amount into paid funds and unexpired credit grants
+	PaidAmount    float64 `json:"paidAmount"`
+	GrantedAmount float64 `json:"grantedAmount"`
 }
 
-// RechargeRecord represents a recharge or deduction record
+// RechargeRecord represents an immutable ledger entry. The sum of all entry
+// amounts of a team (including checkpoints) is the team's balance.
 type RechargeRecord struct {
 	ID        string    `json:"id"`
 	Timestamp time.Time `json:"timestamp"`
-	Type      string    `json:"type"`   // "recharge", "deduction", "auto_recharge"
+	Type      string    `json:"type"`   // "recharge", "deduction", "auto_recharge", "checkpoint" or an adjustment type
 	Amount    float64   `json:"amount"` // Positive for recharge, negative for deduction
 	Operator  string    `json:"operator"`
 	Reason    string    `json:"reason,omitempty"`
-	Balance   float64   `json:"balance"` // Balance after this operation
+	Balance   float64   `json:"balance"`          // Balance after this operation
+	Period    string    `json:"period,omitempty"` // Billing period key for usage deductions
+	RefID     string    `json:"refId,omitempty"`  // Ledger entry an adjustment applies to, or the other side of a transfer
+	Peer      string    `json:"peer,omitempty"`   // Other team of a transfer
+}
+
+// Adjustment is an operator correction to a team's balance
+type Adjustment struct {
+	Type   string  `json:"type"`   // refund, credit, manual_charge or correction
+	Amount float64 `json:"amount"` // Positive, except corrections which are signed
+	RefID  string  `json:"refId"`  // Original ledger entry; required for refunds and corrections
+	Reason string  `json:"reason"`
 }
 
+// Validate checks an adjustment request
+func (a *Adjustment) Validate() error {
+	if !IsAdjustment(a.Type) {
+		return fmt.Errorf("unknown adjustment type %q", a.Type)
+	}
+	if a.Reason == "" {
+		return fmt.Errorf("adjustment reason is required")
+	}
+	if a.Type == RecordTypeCorrection {
+		if a.Amount == 0 {
+			return fmt.Errorf("correction amount must not be zero")
+		}
+	} else if a.Amount <= 0 {
+		return fmt.Errorf("%s amount must be positive", a.Type)
+	}
+	if a.RefID == "" && (a.Type == RecordTypeRefund || a.Type == RecordTypeCorrection) {
+		return fmt.Errorf("%s must reference the original ledger entry", a.Type)
+	}
+	return nil
+}
+
+// Auto-recharge triggers
+const (
+	AutoRechargeTriggerSchedule  = "schedule"  // Top up on a weekly or monthly schedule
+	AutoRechargeTriggerThreshold = "threshold" // Top up when the balance falls below a threshold
+)
+
 // AutoRechargeConfig represents auto-recharge configuration for a team
 type AutoRechargeConfig struct {
 	Enabled       bool      `json:"enabled"`
 	Amount        float64   `json:"amount"`
-	Schedule      string    `json:"schedule"`   // "weekly" or "monthly"
-	DayOfWeek     int       `json:"dayOfWeek"`  // 0-6 for weekly (0=Sunday)
-	DayOfMonth    int       `json:"dayOfMonth"` // 1-31 for monthly
+	Trigger       string    `json:"trigger,omitempty"` // "schedule" (default) or "threshold"
+	Schedule      string    `json:"schedule"`          // "weekly" or "monthly"
+	DayOfWeek     int       `json:"dayOfWeek"`         // 0-6 for weekly (0=Sunday)
+	DayOfMonth    int       `json:"dayOfMonth"`        // 1-31 for monthly
 	NextExecution time.Time `json:"nextExecution"`
 	LastExecuted  time.Time `json:"lastExecuted,omitempty"`
+
+	// Threshold trigger
+	Threshold          float64 `json:"threshold,omitempty"`          // Top up when the balance falls below this
+	MonthlyCap         float64 `json:"monthlyCap,omitempty"`         // Most topped up per calendar month, 0 = unlimited
+	MinIntervalMinutes int     `json:"minIntervalMinutes,omitempty"` // Least time between top-ups
+	Month              string  `json:"month,omitempty"`              // Calendar month of MonthTotal, YYYY-MM
+	MonthTotal         float64 `json:"monthTotal,omitempty"`         // Topped up by the threshold trigger this month
+}
+
+// Validate checks an auto-recharge configuration
+func (c *AutoRechargeConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Amount <= 0 {
+		return fmt.Errorf("auto-recharge amount must be positive")
+	}
+	switch c.Trigger {
+	case "", AutoRechargeTriggerSchedule:
+	case AutoRechargeTriggerThreshold:
+		if c.MonthlyCap < 0 || c.MinIntervalMinutes < 0 {
+			return fmt.Errorf("monthly cap and minimum interval must not be negative")
+		}
+	default:
+		return fmt.Errorf("unknown auto-recharge trigger %q", c.Trigger)
+	}
+	return nil
+}
+
+// CreditPolicy overrides how a team is treated once its balance runs out
+type CreditPolicy struct {
+	CreditLimit      float64   `json:"creditLimit"`                // Overdraft allowed below zero before the grace period starts
+	GracePeriodValue int       `json:"gracePeriodValue,omitempty"` // Overrides the global grace period when GracePeriodUnit is set
+	GracePeriodUnit  string    `json:"gracePeriodUnit,omitempty"`  // "hours" or "days"
+	NeverSuspend     bool      `json:"neverSuspend"`               // Never suspend automatically, e.g. for VIP teams
+	UpdatedAt        time.Time `json:"updatedAt,omitempty"`
+	UpdatedBy        string    `json:"updatedBy,omitempty"`
+}
+
+// Validate checks a credit policy
+func (p *CreditPolicy) Validate() error {
+	if p.CreditLimit < 0 {
+		return fmt.Errorf("credit limit must not be negative")
+	}
+	switch p.GracePeriodUnit {
+	case "", "hours", "days":
+	default:
+		return fmt.Errorf("grace period unit must be hours or days")
+	}
+	if p.GracePeriodValue < 0 {
+		return fmt.Errorf("grace period must not be negative")
+	}
+	return nil
+}
+
+// ReconcileResult describes a team whose stored balance disagrees with its ledger
+type ReconcileResult struct {
+	TeamName      string  `json:"teamName"`
+	StoredBalance float64 `json:"storedBalance"`
+	LedgerBalance float64 `json:"ledgerBalance"`
+	Difference    float64 `json:"difference"` // Stored minus ledger
+	Repaired      bool    `json:"repaired"`
+}
+
+// ReconcileReport is the outcome of a reconciliation run
+type ReconcileReport struct {
+	CheckedAt    time.Time          `json:"checkedAt"`
+	TeamsChecked int                `json:"teamsChecked"`
+	Mismatches   []*ReconcileResult `json:"mismatches"`
+	Repair       bool               `json:"repair"`
 }
 
 // BalanceService handles team balance operations
 type BalanceService struct {
-	k8sClient *k8s.Client
+	store store.Store
 }
 
 // NewBalanceService creates a new BalanceService
-func NewBalanceService(k8sClient *k8s.Client) *BalanceService {
+func NewBalanceService(st store.Store) *BalanceService {
 	return &BalanceService{
-		k8sClient: k8sClient,
+		store: st,
 	}
 }
 
@@ -72,12 +233,12 @@ func NewBalanceService(k8sClient *k8s.Client) *BalanceService {
 func (s *BalanceService) GetBalance(ctx context.Context, teamName string) (*Balance, error) {
 	logger.Debug("Getting balance", "team", teamName)
 
-	cm, err := s.getOrCreateConfigMap(ctx, BalancesConfigMap)
+	entries, err := s.store.Load(ctx, BalancesConfigMap)
 	if err != nil {
 		return nil, err
 	}
 
-	data, ok := cm.Data[teamName]
+	data, ok := entries[teamName]
 	if !ok {
 		// Return zero balance if not found
 		return &Balance{
@@ -101,13 +262,13 @@ func (s *BalanceService) GetBalance(ctx context.Context, teamName string) (*Bala
 func (s *BalanceService) GetAllBalances(ctx context.Context) ([]*Balance, error) {
 	logger.Debug("Getting all balances")
 
-	cm, err := s.getOrCreateConfigMap(ctx, BalancesConfigMap)
+	entries, err := s.store.Load(ctx, BalancesConfigMap)
 	if err != nil {
 		return nil, err
 	}
 
 	var balances []*Balance
-	for teamName, data := range cm.Data {
+	for teamName, data := range entries {
 		var balance Balance
 		if err := json.Unmarshal([]byte(data), &balance); err != nil {
 			logger.Warn("Failed to unmarshal balance", "team", teamName, "error", err)
@@ -128,84 +289,291 @@ func (s *BalanceService) Recharge(ctx context.Context, teamName string, amount f
 		return fmt.Errorf("recharge amount must be positive")
 	}
 
-	// Get current balance
-	balance, err := s.GetBalance(ctx, teamName)
-	if err != nil {
-		return err
-	}
+	return s.applyEntry(ctx, teamName, &RechargeRecord{
+		Type:     RecordTypeRecharge,
+		Amount:   amount,
+		Operator: operator,
+		Reason:   remark,
+	})
+}
 
-	// Update balance
-	newAmount := balance.Amount + amount
-	if err := s.updateBalance(ctx, teamName, newAmount); err != nil {
-		return err
+// Adjust records an adjustment against a team's ledger on behalf of an operator. The
+// referenced entry must still be in the ledger, and refunds of a charge cannot add up to
+// more than the charge.
+func (s *BalanceService) Adjust(ctx context.Context, teamName string, adj *Adjustment, operator string) (*RechargeRecord, error) {
+	logger.Info("Adjusting team balance", "team", teamName, "type", adj.Type, "amount", adj.Amount, "refId", adj.RefID, "operator", operator)
+
+	if err := adj.Validate(); err != nil {
+		return nil, err
 	}
 
-	// Record history
+	amount := adj.Amount
+	if adj.Type == RecordTypeManualCharge {
+		amount = -amount
+	}
 	record := &RechargeRecord{
-		ID:        fmt.Sprintf("%d", time.Now().UnixNano()),
-		Timestamp: time.Now(),
-		Type:      "recharge",
+		Type:     adj.Type,
+		Amount:   amount,
+		Operator: operator,
+		Reason:   adj.Reason,
+		RefID:    adj.RefID,
+	}
+
+	check := func(records []*RechargeRecord) error {
+		if adj.RefID == "" {
+			return nil
+		}
+		var original *RechargeRecord
+		refunded := 0.0
+		for _, r := range records {
+			if r.ID == adj.RefID && r.Type != RecordTypeCheckpoint {
+				original = r
+			}
+			if r.RefID == adj.RefID && r.Type == RecordTypeRefund {
+				refunded += r.Amount
+			}
+		}
+		if original == nil {
+			return fmt.Errorf("ledger entry %s not found for team %s", adj.RefID, teamName)
+		}
+		if adj.Type == RecordTypeRefund {
+			if !isUsageCharge(original.Type) && original.Type != RecordTypeManualCharge {
+				return fmt.Errorf("ledger entry %s is not a charge and cannot be refunded", adj.RefID)
+			}
+			if refunded+adj.Amount > -original.Amount+ledgerTolerance {
+				return fmt.Errorf("refund of %.2f exceeds the %.2f left to refund on entry %s", adj.Amount, -original.Amount-refunded, adj.RefID)
+			}
+		}
+		return nil
+	}
+
+	if err := s.applyCheckedEntry(ctx, teamName, record, check); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// Transfer moves balance from one team to another. Both ledger entries are written in one
+// update, and the transfer is rejected if it would take the source team below its floor,
+// the negative of its credit limit.
+func (s *BalanceService) Transfer(ctx context.Context, fromTeam, toTeam string, amount float64, operator, reason string) (*RechargeRecord, error) {
+	logger.Info("Transferring balance", "from", fromTeam, "to", toTeam, "amount", amount, "operator", operator)
+
+	if amount <= 0 {
+		return nil, fmt.Errorf("transfer amount must be positive")
+	}
+	if fromTeam == toTeam {
+		return nil, fmt.Errorf("cannot transfer to the same team")
+	}
+
+	floor := 0.0
+	policy, err := s.GetCreditPolicy(ctx, fromTeam)
+	if err != nil {
+		return nil, err
+	}
+	if policy != nil {
+		floor = -policy.CreditLimit
+	}
+
+	now := time.Now()
+	id := fmt.Sprintf("%d", now.UnixNano())
+	out := &RechargeRecord{
+		ID:        id + "-out",
+		Timestamp: now,
+		Type:      RecordTypeTransferOut,
+		Amount:    -amount,
+		Operator:  operator,
+		Reason:    reason,
+		RefID:     id + "-in",
+		Peer:      toTeam,
+	}
+	in := &RechargeRecord{
+		ID:        id + "-in",
+		Timestamp: now,
+		Type:      RecordTypeTransferIn,
 		Amount:    amount,
 		Operator:  operator,
-		Reason:    remark,
-		Balance:   newAmount,
+		Reason:    reason,
+		RefID:     id + "-out",
+		Peer:      fromTeam,
+	}
+
+	err = s.store.Modify(ctx, RechargeHistoryConfigMap, func(entries map[string]string) error {
+		source, err := s.parseLedger(entries, fromTeam)
+		if err != nil {
+			return err
+		}
+		if source, err = s.openLedger(ctx, fromTeam, source, out); err != nil {
+			return err
+		}
+		// Granted credit belongs to the team it was granted to. It is read on every
+		// attempt, so a charge drawing on the grants meanwhile is taken into account.
+		granted, err := s.GrantedAmount(ctx, fromTeam)
+		if err != nil {
+			return err
+		}
+		available := sumLedger(source)
+		if available-granted-amount < floor-ledgerTolerance {
+			return fmt.Errorf("transfer of %.2f would take team %s below its floor of %.2f (paid balance %.2f)", amount, fromTeam, floor, available-granted)
+		}
+
+		target, err := s.parseLedger(entries, toTeam)
+		if err != nil {
+			return err
+		}
+		if target, err = s.openLedger(ctx, toTeam, target, in); err != nil {
+			return err
+		}
+
+		out.Balance = available - amount
+		in.Balance = sumLedger(target) + amount
+		if err := s.writeLedger(entries, fromTeam, append(source, out)); err != nil {
+			return err
+		}
+		return s.writeLedger(entries, toTeam, append(target, in))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// A failed balance write is detected and repaired by Reconcile, as in applyEntry
+	for _, team := range []string{fromTeam, toTeam} {
+		if _, err := s.syncBalance(ctx, team); err != nil {
+			return nil, fmt.Errorf("transfer %s recorded but balance update failed: %w", id, err)
+		}
 	}
 
-	return s.addRechargeRecord(ctx, teamName, record)
+	return out, nil
 }
 
 // Deduct deducts balance from a team
 func (s *BalanceService) Deduct(ctx context.Context, teamName string, amount float64, reason string) error {
-	logger.Info("Deducting from team", "team", teamName, "amount", amount, "reason", reason)
+	return s.deduct(ctx, teamName, amount, reason, "", nil)
+}
 
-	if amount <= 0 {
-		return fmt.Errorf("deduction amount must be positive")
+// errPeriodBilled rejects a period deduction when the period already has one
+var errPeriodBilled = errors.New("period already billed")
+
+// DeductForPeriod deducts usage charges for a billing period. A period that
+// already has a deduction recorded for the team is skipped, so re-running
+// billing for the same period never charges twice. The check is made in the
+// same ledger update as the deduction, so concurrent runs cannot both charge.
+func (s *BalanceService) DeductForPeriod(ctx context.Context, teamName string, amount float64, reason, period string) error {
+	err := s.deduct(ctx, teamName, amount, reason, period, func(records []*RechargeRecord) error {
+		if hasPeriodCharge(records, period) {
+			return errPeriodBilled
+		}
+		return nil
+	})
+	if errors.Is(err, errPeriodBilled) {
+		logger.Info("Period already billed, skipping deduction", "team", teamName, "period", period)
+		return nil
+	}
+	return err
+}
+
+// hasPeriodCharge reports whether records include a usage charge for the period
+func hasPeriodCharge(records []*RechargeRecord, period string) bool {
+	for _, record := range records {
+		if record.Period == period && isUsageCharge(record.Type) {
+			return true
+		}
 	}
+	return false
+}
 
-	// Get current balance
-	balance, err := s.GetBalance(ctx, teamName)
+// ReplayConflict returns why usage of the team in [start, end) can't be replayed, or "" if
+// it can
+func (s *BalanceService) ReplayConflict(ctx context.Context, teamName string, start, end time.Time) (string, error) {
+	ledger, err := s.GetLedger(ctx, teamName)
 	if err != nil {
-		return err
+		return "", err
 	}
+	return replayConflict(ledger, start, end), nil
+}
 
-	// Update balance (allow negative balance)
-	newAmount := balance.Amount - amount
-	if err := s.updateBalance(ctx, teamName, newAmount); err != nil {
-		return err
+// DeductReplay charges usage for a past window with a "billing_replay" entry. The charge
+// is refused if any usage charge already recorded for the team overlaps the window, or
+// the window reaches back into ledger history that was compacted away.
+func (s *BalanceService) DeductReplay(ctx context.Context, teamName string, amount float64, reason, period, operator string) (*RechargeRecord, error) {
+	logger.Info("Replaying billing", "team", teamName, "amount", amount, "period", period, "operator", operator)
+
+	if amount <= 0 {
+		return nil, fmt.Errorf("deduction amount must be positive")
+	}
+	start, end, err := parseWindow(period, time.Now())
+	if err != nil {
+		return nil, err
 	}
 
-	// Record history
 	record := &RechargeRecord{
-		ID:        fmt.Sprintf("%d", time.Now().UnixNano()),
-		Timestamp: time.Now(),
-		Type:      "deduction",
-		Amount:    -amount,
-		Operator:  "system",
-		Reason:    reason,
-		Balance:   newAmount,
+		Type:     RecordTypeBillingReplay,
+		Amount:   -amount,
+		Operator: operator,
+		Reason:   reason,
+		Period:   period,
 	}
+	err = s.charge(ctx, teamName, record, func(records []*RechargeRecord) error {
+		if conflict := replayConflict(records, start, end); conflict != "" {
+			return fmt.Errorf("cannot replay %s for team %s: %s", period, teamName, conflict)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
 
-	return s.addRechargeRecord(ctx, teamName, record)
+func (s *BalanceService) deduct(ctx context.Context, teamName string, amount float64, reason, period string, check func(records []*RechargeRecord) error) error {
+	logger.Info("Deducting from team", "team", teamName, "amount", amount, "reason", reason, "period", period)
+
+	if amount <= 0 {
+		return fmt.Errorf("deduction amount must be positive")
+	}
+
+	// Negative balance is allowed
+	return s.charge(ctx, teamName, &RechargeRecord{
+		Type:     RecordTypeDeduction,
+		Amount:   -amount,
+		Operator: "system",
+		Reason:   reason,
+		Period:   period,
+	}, check)
+}
+
+// charge records a usage charge, drawing it from credit grants before paid funds
+func (s *BalanceService) charge(ctx context.Context, teamName string, record *RechargeRecord, check func(records []*RechargeRecord) error) error {
+	if err := s.applyCheckedEntry(ctx, teamName, record, check); err != nil {
+		return err
+	}
+
+	// A failed draw-down is retried by ExpireGrants, keyed by the ledger entry
+	granted, err := s.consumeGrants(ctx, teamName, record)
+	if err != nil {
+		return fmt.Errorf("ledger entry %s recorded but credit grants were not drawn down: %w", record.ID, err)
+	}
+	if granted > 0 {
+		logger.Info("Deduction drawn from credit grants", "team", teamName, "granted", granted, "paid", -record.Amount-granted)
+	}
+	return nil
 }
 
 // GetRechargeHistory returns recharge/deduction history for a team
 func (s *BalanceService) GetRechargeHistory(ctx context.Context, teamName string, limit int) ([]*RechargeRecord, error) {
 	logger.Debug("Getting recharge history", "team", teamName, "limit", limit)
 
-	cm, err := s.getOrCreateConfigMap(ctx, RechargeHistoryConfigMap)
+	ledger, err := s.GetLedger(ctx, teamName)
 	if err != nil {
 		return nil, err
 	}
 
-	data, ok := cm.Data[teamName]
-	if !ok {
-		return []*RechargeRecord{}, nil
-	}
-
-	var records []*RechargeRecord
-	if err := json.Unmarshal([]byte(data), &records); err != nil {
-		logger.Error("Failed to unmarshal history", "team", teamName, "error", err)
-		return nil, fmt.Errorf("failed to parse history: %w", err)
+	// Checkpoints are bookkeeping entries, not operations
+	records := make([]*RechargeRecord, 0, len(ledger))
+	for _, record := range ledger {
+		if record.Type != RecordTypeCheckpoint {
+			records = append(records, record)
+		}
 	}
 
 	// Sort by timestamp descending
@@ -221,16 +589,121 @@ func (s *BalanceService) GetRechargeHistory(ctx context.Context, teamName string
 	return records, nil
 }
 
+// GetAdjustments returns a team's adjustments recorded in [start, end), oldest first
+func (s *BalanceService) GetAdjustments(ctx context.Context, teamName string, start, end time.Time) ([]*RechargeRecord, error) {
+	ledger, err := s.GetLedger(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	adjustments := make([]*RechargeRecord, 0)
+	for _, record := range ledger {
+		if IsAdjustment(record.Type) && !record.Timestamp.Before(start) && record.Timestamp.Before(end) {
+			adjustments = append(adjustments, record)
+		}
+	}
+	return adjustments, nil
+}
+
+// GetLedger returns all ledger entries for a team in the order they were recorded
+func (s *BalanceService) GetLedger(ctx context.Context, teamName string) ([]*RechargeRecord, error) {
+	entries, err := s.store.Load(ctx, RechargeHistoryConfigMap)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.parseLedger(entries, teamName)
+}
+
+// Reconcile compares each team's stored balance with the sum of its ledger.
+// When repair is true, mismatched balances are overwritten with the ledger sum.
+func (s *BalanceService) Reconcile(ctx context.Context, repair bool) (*ReconcileReport, error) {
+	logger.Info("Reconciling balances against ledger", "repair", repair)
+
+	balanceEntries, err := s.store.Load(ctx, BalancesConfigMap)
+	if err != nil {
+		return nil, err
+	}
+	ledgerEntries, err := s.store.Load(ctx, RechargeHistoryConfigMap)
+	if err != nil {
+		return nil, err
+	}
+
+	teams := make(map[string]bool)
+	for teamName := range balanceEntries {
+		teams[teamName] = true
+	}
+	for teamName := range ledgerEntries {
+		teams[teamName] = true
+	}
+
+	report := &ReconcileReport{
+		CheckedAt:  time.Now(),
+		Mismatches: []*ReconcileResult{},
+		Repair:     repair,
+	}
+
+	for teamName := range teams {
+		ledger, err := s.parseLedger(ledgerEntries, teamName)
+		if err != nil {
+			logger.Warn("Skipping team with unreadable ledger", "team", teamName, "error", err)
+			continue
+		}
+		// Teams that predate the ledger have no opening checkpoint yet;
+		// their stored balance becomes the opening balance on first write
+		if !hasOpeningCheckpoint(ledger) {
+			continue
+		}
+		report.TeamsChecked++
+
+		balance, err := s.GetBalance(ctx, teamName)
+		if err != nil {
+			logger.Warn("Skipping team with unreadable balance", "team", teamName, "error", err)
+			continue
+		}
+
+		ledgerBalance := sumLedger(ledger)
+		diff := balance.Amount - ledgerBalance
+		if math.Abs(diff) <= ledgerTolerance {
+			continue
+		}
+
+		result := &ReconcileResult{
+			TeamName:      teamName,
+			StoredBalance: balance.Amount,
+			LedgerBalance: ledgerBalance,
+			Difference:    diff,
+		}
+		logger.Warn("Balance does not match ledger", "team", teamName, "stored", balance.Amount, "ledger", ledgerBalance)
+
+		if repair {
+			if _, err := s.syncBalance(ctx, teamName); err != nil {
+				logger.Error("Failed to repair balance", "team", teamName, "error", err)
+			} else {
+				result.Repaired = true
+			}
+		}
+
+		report.Mismatches = append(report.Mismatches, result)
+	}
+
+	sort.Slice(report.Mismatches, func(i, j int) bool {
+		return report.Mismatches[i].TeamName < report.Mismatches[j].TeamName
+	})
+
+	return report, nil
+}
+
 // GetAutoRechargeConfig returns auto-recharge configuration for a team
 func (s *BalanceService) GetAutoRechargeConfig(ctx context.Context, teamName string) (*AutoRechargeConfig, error) {
 	logger.Debug("Getting auto-recharge config", "team", teamName)
 
-	cm, err := s.getOrCreateConfigMap(ctx, AutoRechargeConfigMap)
+	entries, err := s.store.Load(ctx, AutoRechargeConfigMap)
 	if err != nil {
 		return nil, err
 	}
 
-	data, ok := cm.Data[teamName]
+	data, ok := entries[teamName]
 	if !ok {
 		return &AutoRechargeConfig{Enabled: false}, nil
 	}
@@ -244,12 +717,25 @@ func (s *BalanceService) GetAutoRechargeConfig(ctx context.Context, teamName str
 	return &config, nil
 }
 
+// UpdateAutoRechargeConfig replaces a team's auto-recharge settings, keeping the record of
+// past top-ups so changing the settings does not reset the monthly cap or interval
+func (s *BalanceService) UpdateAutoRechargeConfig(ctx context.Context, teamName string, config *AutoRechargeConfig) error {
+	current, err := s.GetAutoRechargeConfig(ctx, teamName)
+	if err != nil {
+		return err
+	}
+	config.LastExecuted = current.LastExecuted
+	config.Month = current.Month
+	config.MonthTotal = current.MonthTotal
+	return s.SetAutoRechargeConfig(ctx, teamName, config)
+}
+
 // SetAutoRechargeConfig sets auto-recharge configuration for a team
 func (s *BalanceService) SetAutoRechargeConfig(ctx context.Context, teamName string, config *AutoRechargeConfig) error {
-	logger.Info("Setting auto-recharge config", "team", teamName, "enabled", config.Enabled)
+	logger.Info("Setting auto-recharge config", "team", teamName, "enabled", config.Enabled, "trigger", config.Trigger)
 
 	// Calculate next execution time
-	if config.Enabled {
+	if config.Enabled && config.Trigger != AutoRechargeTriggerThreshold {
 		config.NextExecution = s.calculateNextExecution(config)
 	}
 
@@ -258,37 +744,78 @@ func (s *BalanceService) SetAutoRechargeConfig(ctx context.Context, teamName str
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
-	cm, err := s.getOrCreateConfigMap(ctx, AutoRechargeConfigMap)
+	return s.store.Modify(ctx, AutoRechargeConfigMap, func(entries map[string]string) error {
+		entries[teamName] = string(data)
+		return nil
+	})
+}
+
+// GetCreditPolicy returns the credit policy of a team, or nil if it uses the defaults
+func (s *BalanceService) GetCreditPolicy(ctx context.Context, teamName string) (*CreditPolicy, error) {
+	entries, err := s.store.Load(ctx, CreditPoliciesConfigMap)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if cm.Data == nil {
-		cm.Data = make(map[string]string)
+	data, ok := entries[teamName]
+	if !ok {
+		return nil, nil
 	}
-	cm.Data[teamName] = string(data)
 
-	return s.updateConfigMap(ctx, cm)
+	var policy CreditPolicy
+	if err := json.Unmarshal([]byte(data), &policy); err != nil {
+		logger.Error("Failed to unmarshal credit policy", "team", teamName, "error", err)
+		return nil, fmt.Errorf("failed to parse credit policy: %w", err)
+	}
+
+	return &policy, nil
+}
+
+// SetCreditPolicy creates or replaces the credit policy of a team
+func (s *BalanceService) SetCreditPolicy(ctx context.Context, teamName string, policy *CreditPolicy, operator string) error {
+	logger.Info("Setting credit policy", "team", teamName, "creditLimit", policy.CreditLimit, "neverSuspend", policy.NeverSuspend)
+
+	policy.UpdatedAt = time.Now()
+	policy.UpdatedBy = operator
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return fmt.Errorf("failed to marshal credit policy: %w", err)
+	}
+
+	return s.store.Modify(ctx, CreditPoliciesConfigMap, func(entries map[string]string) error {
+		entries[teamName] = string(data)
+		return nil
+	})
+}
+
+// DeleteCreditPolicy reverts a team to the default credit policy
+func (s *BalanceService) DeleteCreditPolicy(ctx context.Context, teamName string) error {
+	logger.Info("Deleting credit policy", "team", teamName)
+
+	return s.store.Modify(ctx, CreditPoliciesConfigMap, func(entries map[string]string) error {
+		delete(entries, teamName)
+		return nil
+	})
 }
 
 // ProcessAutoRecharge processes auto-recharge for all teams
 func (s *BalanceService) ProcessAutoRecharge(ctx context.Context) error {
 	logger.Debug("Processing auto-recharge")
 
-	cm, err := s.getOrCreateConfigMap(ctx, AutoRechargeConfigMap)
+	entries, err := s.store.Load(ctx, AutoRechargeConfigMap)
 	if err != nil {
 		return err
 	}
 
 	now := time.Now()
-	for teamName, data := range cm.Data {
+	for teamName, data := range entries {
 		var config AutoRechargeConfig
 		if err := json.Unmarshal([]byte(data), &config); err != nil {
 			logger.Warn("Failed to unmarshal auto-recharge config", "team", teamName, "error", err)
 			continue
 		}
 
-		if !config.Enabled {
+		if !config.Enabled || config.Trigger == AutoRechargeTriggerThreshold {
 			continue
 		}
 
@@ -299,32 +826,16 @@ func (s *BalanceService) ProcessAutoRecharge(ctx context.Context) error {
 
 		logger.Info("Executing auto-recharge", "team", teamName, "amount", config.Amount)
 
-		// Get current balance
-		balance, err := s.GetBalance(ctx, teamName)
-		if err != nil {
-			logger.Error("Failed to get balance for auto-recharge", "team", teamName, "error", err)
-			continue
-		}
-
-		// Update balance
-		newAmount := balance.Amount + config.Amount
-		if err := s.updateBalance(ctx, teamName, newAmount); err != nil {
-			logger.Error("Failed to update balance for auto-recharge", "team", teamName, "error", err)
-			continue
-		}
-
-		// Record history
 		record := &RechargeRecord{
-			ID:        fmt.Sprintf("%d", time.Now().UnixNano()),
 			Timestamp: now,
-			Type:      "auto_recharge",
+			Type:      RecordTypeAutoRecharge,
 			Amount:    config.Amount,
 			Operator:  "system",
 			Reason:    fmt.Sprintf("Auto recharge (%s)", config.Schedule),
-			Balance:   newAmount,
 		}
-		if err := s.addRechargeRecord(ctx, teamName, record); err != nil {
-			logger.Error("Failed to record auto-recharge", "team", teamName, "error", err)
+		if err := s.applyEntry(ctx, teamName, record); err != nil {
+			logger.Error("Failed to apply auto-recharge", "team", teamName, "error", err)
+			continue
 		}
 
 		// Update config with next execution time
@@ -338,6 +849,163 @@ func (s *BalanceService) ProcessAutoRecharge(ctx context.Context) error {
 	return nil
 }
 
+// CheckThresholdRecharge tops up a team whose balance has fallen below its auto-recharge
+// threshold, within the monthly cap and minimum interval. It is run after each billing
+// deduction.
+func (s *BalanceService) CheckThresholdRecharge(ctx context.Context, teamName string) error {
+	config, err := s.GetAutoRechargeConfig(ctx, teamName)
+	if err != nil {
+		return err
+	}
+	if !config.Enabled || config.Trigger != AutoRechargeTriggerThreshold {
+		return nil
+	}
+
+	balance, err := s.GetBalance(ctx, teamName)
+	if err != nil {
+		return err
+	}
+	if balance.Amount >= config.Threshold {
+		return nil
+	}
+
+	// Claim the top-up against the interval and monthly cap before recording it, so
+	// concurrent checks cannot both pass them
+	now := time.Now()
+	previous, amount, err := s.claimThresholdRecharge(ctx, teamName, now)
+	if err != nil {
+		return err
+	}
+	if amount <= 0 {
+		logger.Info("Threshold auto-recharge not due", "team", teamName, "lastExecuted", previous.LastExecuted, "monthTotal", previous.MonthTotal)
+		return nil
+	}
+
+	logger.Info("Executing threshold auto-recharge", "team", teamName, "amount", amount, "balance", balance.Amount, "threshold", config.Threshold)
+
+	// The entry is named after its claim, so it is recorded at most once
+	record := &RechargeRecord{
+		ID:        fmt.Sprintf("auto-recharge-%d", now.UnixNano()),
+		Timestamp: now,
+		Type:      RecordTypeAutoRecharge,
+		Amount:    amount,
+		Operator:  "system",
+		Reason:    fmt.Sprintf("Auto recharge (balance %.2f below threshold %.2f)", balance.Amount, config.Threshold),
+	}
+	err = s.applyCheckedEntry(ctx, teamName, record, func(records []*RechargeRecord) error {
+		if findRecord(records, record.ID) != nil {
+			return fmt.Errorf("auto-recharge %s is already recorded", record.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		// Give the claim back unless the entry made it into the ledger
+		if ledger, lerr := s.GetLedger(ctx, teamName); lerr == nil && findRecord(ledger, record.ID) == nil {
+			if rerr := s.releaseThresholdRecharge(ctx, teamName, previous, now, amount); rerr != nil {
+				logger.Error("Failed to release auto-recharge claim", "team", teamName, "error", rerr)
+			}
+		}
+		return fmt.Errorf("failed to apply auto-recharge: %w", err)
+	}
+	return nil
+}
+
+// claimThresholdRecharge records a threshold top-up at now in the team's auto-recharge
+// config, if the minimum interval and monthly cap allow one. It returns the config as it
+// was before and the amount claimed, which is 0 when no top-up is due.
+func (s *BalanceService) claimThresholdRecharge(ctx context.Context, teamName string, now time.Time) (*AutoRechargeConfig, float64, error) {
+	var previous *AutoRechargeConfig
+	var amount float64
+	err := s.store.Modify(ctx, AutoRechargeConfigMap, func(entries map[string]string) error {
+		previous, amount = nil, 0
+		data, ok := entries[teamName]
+		if !ok {
+			previous = &AutoRechargeConfig{}
+			return nil
+		}
+
+		var config AutoRechargeConfig
+		if err := json.Unmarshal([]byte(data), &config); err != nil {
+			return fmt.Errorf("failed to parse config: %w", err)
+		}
+		before := config
+		previous = &before
+		if !config.Enabled || config.Trigger != AutoRechargeTriggerThreshold {
+			return nil
+		}
+
+		minInterval := time.Duration(config.MinIntervalMinutes) * time.Minute
+		if !config.LastExecuted.IsZero() && now.Sub(config.LastExecuted) < minInterval {
+			return nil
+		}
+
+		month := now.Format("2006-01")
+		if config.Month != month {
+			config.Month = month
+			config.MonthTotal = 0
+		}
+		due := config.Amount
+		if config.MonthlyCap > 0 {
+			due = math.Min(due, config.MonthlyCap-config.MonthTotal)
+			if due <= 0 {
+				return nil
+			}
+		}
+
+		config.LastExecuted = now
+		config.MonthTotal += due
+		updated, err := json.Marshal(config)
+		if err != nil {
+			return fmt.Errorf("failed to marshal config: %w", err)
+		}
+		entries[teamName] = string(updated)
+		amount = due
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return previous, amount, nil
+}
+
+// releaseThresholdRecharge undoes a claim whose top-up was not recorded, unless a later
+// top-up has been claimed since
+func (s *BalanceService) releaseThresholdRecharge(ctx context.Context, teamName string, previous *AutoRechargeConfig, claimed time.Time, amount float64) error {
+	return s.store.Modify(ctx, AutoRechargeConfigMap, func(entries map[string]string) error {
+		data, ok := entries[teamName]
+		if !ok {
+			return nil
+		}
+
+		var config AutoRechargeConfig
+		if err := json.Unmarshal([]byte(data), &config); err != nil {
+			return fmt.Errorf("failed to parse config: %w", err)
+		}
+		if !config.LastExecuted.Equal(claimed) {
+			return nil
+		}
+
+		config.LastExecuted = previous.LastExecuted
+		config.MonthTotal -= amount
+		updated, err := json.Marshal(config)
+		if err != nil {
+			return fmt.Errorf("failed to marshal config: %w", err)
+		}
+		entries[teamName] = string(updated)
+		return nil
+	})
+}
+
+// findRecord returns the ledger entry with the given ID, or nil
+func findRecord(records []*RechargeRecord, id string) *RechargeRecord {
+	for _, record := range records {
+		if record.ID == id {
+			return record
+		}
+	}
+	return nil
+}
+
 // GetLowBalanceTeams returns teams with balance below threshold
 func (s *BalanceService) GetLowBalanceTeams(ctx context.Context, threshold float64) ([]*Balance, error) {
 	balances, err := s.GetAllBalances(ctx)
@@ -372,98 +1040,223 @@ func (s *BalanceService) GetTotalBalance(ctx context.Context) (float64, error) {
 
 // Helper methods
 
-func (s *BalanceService) updateBalance(ctx context.Context, teamName string, amount float64) error {
-	balance := &Balance{
-		TeamName:    teamName,
-		Amount:      amount,
-		LastUpdated: time.Now(),
-	}
-
-	data, err := json.Marshal(balance)
-	if err != nil {
-		return fmt.Errorf("failed to marshal balance: %w", err)
-	}
+// applyEntry appends an entry to the team ledger and then updates the stored
+// balance to the new ledger sum. The ledger is written first as the source of
+// truth; a failed balance write is detected and repaired by Reconcile.
+func (s *BalanceService) applyEntry(ctx context.Context, teamName string, record *RechargeRecord) error {
+	return s.applyCheckedEntry(ctx, teamName, record, nil)
+}
 
-	cm, err := s.getOrCreateConfigMap(ctx, BalancesConfigMap)
-	if err != nil {
+// applyCheckedEntry is applyEntry with a check run against the current ledger in the same
+// update, so the entry is only recorded if the check passes. check may be called more than
+// once and must not have side effects.
+func (s *BalanceService) applyCheckedEntry(ctx context.Context, teamName string, record *RechargeRecord, check func(records []*RechargeRecord) error) error {
+	if err := s.appendLedgerEntry(ctx, teamName, record, check); err != nil {
 		return err
 	}
 
-	if cm.Data == nil {
-		cm.Data = make(map[string]string)
+	if _, err := s.syncBalance(ctx, teamName); err != nil {
+		return fmt.Errorf("ledger entry %s recorded but balance update failed: %w", record.ID, err)
 	}
-	cm.Data[teamName] = string(data)
 
-	return s.updateConfigMap(ctx, cm)
+	return nil
 }
 
-func (s *BalanceService) addRechargeRecord(ctx context.Context, teamName string, record *RechargeRecord) error {
-	cm, err := s.getOrCreateConfigMap(ctx, RechargeHistoryConfigMap)
-	if err != nil {
-		return err
+// appendLedgerEntry records an entry if check, when set, accepts the current ledger;
+// record.Balance is set to the team balance after it
+func (s *BalanceService) appendLedgerEntry(ctx context.Context, teamName string, record *RechargeRecord, check func(records []*RechargeRecord) error) error {
+	if record.ID == "" {
+		record.ID = fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	if record.Timestamp.IsZero() {
+		record.Timestamp = time.Now()
 	}
 
-	var records []*RechargeRecord
-	if data, ok := cm.Data[teamName]; ok {
-		if err := json.Unmarshal([]byte(data), &records); err != nil {
-			logger.Warn("Failed to unmarshal existing history, starting fresh", "team", teamName)
-			records = []*RechargeRecord{}
+	return s.store.Modify(ctx, RechargeHistoryConfigMap, func(entries map[string]string) error {
+		records, err := s.parseLedger(entries, teamName)
+		if err != nil {
+			return err
+		}
+		if check != nil {
+			if err := check(records); err != nil {
+				return err
+			}
 		}
+
+		records, err = s.openLedger(ctx, teamName, records, record)
+		if err != nil {
+			return err
+		}
+
+		record.Balance = sumLedger(records) + record.Amount
+		return s.writeLedger(entries, teamName, append(records, record))
+	})
+}
+
+// openLedger opens a ledger with the stored balance so history recorded before the
+// ledger existed (and possibly truncated) still sums to the right amount. The opening
+// checkpoint is dated and named after the first new record.
+func (s *BalanceService) openLedger(ctx context.Context, teamName string, records []*RechargeRecord, first *RechargeRecord) ([]*RechargeRecord, error) {
+	if hasOpeningCheckpoint(records) {
+		return records, nil
 	}
 
-	// Add new record
-	records = append(records, record)
+	balance, err := s.GetBalance(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	opening := balance.Amount - sumLedger(records)
+	return append([]*RechargeRecord{{
+		ID:        first.ID + "-opening",
+		Timestamp: first.Timestamp,
+		Type:      RecordTypeCheckpoint,
+		Amount:    opening,
+		Operator:  "system",
+		Reason:    "Opening balance",
+		Balance:   opening,
+	}}, records...), nil
+}
 
-	// Keep only last 1000 records
-	if len(records) > 1000 {
-		records = records[len(records)-1000:]
+// writeLedger stores a team's ledger, compacting it when it grows too long for a bounded store
+func (s *BalanceService) writeLedger(entries map[string]string, teamName string, records []*RechargeRecord) error {
+	if s.store.Bounded() && len(records) > MaxLedgerEntries {
+		records = compactLedger(records, MaxLedgerEntries)
 	}
 
 	data, err := json.Marshal(records)
 	if err != nil {
-		return fmt.Errorf("failed to marshal history: %w", err)
+		return fmt.Errorf("failed to marshal ledger: %w", err)
+	}
+	entries[teamName] = string(data)
+	return nil
+}
+
+func (s *BalanceService) parseLedger(entries map[string]string, teamName string) ([]*RechargeRecord, error) {
+	data, ok := entries[teamName]
+	if !ok {
+		return []*RechargeRecord{}, nil
 	}
 
-	if cm.Data == nil {
-		cm.Data = make(map[string]string)
+	var records []*RechargeRecord
+	if err := json.Unmarshal([]byte(data), &records); err != nil {
+		logger.Error("Failed to unmarshal ledger", "team", teamName, "error", err)
+		return nil, fmt.Errorf("failed to parse ledger: %w", err)
 	}
-	cm.Data[teamName] = string(data)
 
-	return s.updateConfigMap(ctx, cm)
+	return records, nil
 }
 
-func (s *BalanceService) getOrCreateConfigMap(ctx context.Context, name string) (*corev1.ConfigMap, error) {
-	cm, err := s.k8sClient.GetConfigMap(ctx, BisonNamespace, name)
-	if err != nil {
-		if errors.IsNotFound(err) {
-			// Create the ConfigMap
-			cm = &corev1.ConfigMap{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      name,
-					Namespace: BisonNamespace,
-					Labels: map[string]string{
-						"app.kubernetes.io/name":      "bison",
-						"app.kubernetes.io/component": "billing",
-					},
-				},
-				Data: make(map[string]string),
-			}
-			if err := s.k8sClient.CreateConfigMap(ctx, BisonNamespace, cm); err != nil {
-				return nil, fmt.Errorf("failed to create configmap: %w", err)
+// syncBalance sets the stored balance to the current ledger sum and returns it.
+// The ledger is read after the balances ConfigMap on every attempt, so when
+// writers race the last successful update always reflects the latest ledger.
+func (s *BalanceService) syncBalance(ctx context.Context, teamName string) (float64, error) {
+	var amount float64
+	err := s.modifyBalance(ctx, teamName, func(balance *Balance) error {
+		ledger, err := s.GetLedger(ctx, teamName)
+		if err != nil {
+			return err
+		}
+		amount = sumLedger(ledger)
+		balance.Amount = amount
+		balance.LastUpdated = time.Now()
+		return nil
+	})
+	return amount, err
+}
+
+// modifyBalance applies mutate to a team's stored balance with conflict retry
+func (s *BalanceService) modifyBalance(ctx context.Context, teamName string, mutate func(balance *Balance) error) error {
+	return s.store.Modify(ctx, BalancesConfigMap, func(entries map[string]string) error {
+		balance := &Balance{TeamName: teamName}
+		if data, ok := entries[teamName]; ok {
+			if err := json.Unmarshal([]byte(data), balance); err != nil {
+				return fmt.Errorf("failed to parse balance: %w", err)
 			}
-			return cm, nil
 		}
-		return nil, fmt.Errorf("failed to get configmap: %w", err)
+
+		if err := mutate(balance); err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(balance)
+		if err != nil {
+			return fmt.Errorf("failed to marshal balance: %w", err)
+		}
+		entries[teamName] = string(data)
+		return nil
+	})
+}
+
+// sumLedger returns the balance implied by a ledger
+func sumLedger(records []*RechargeRecord) float64 {
+	var sum float64
+	for _, record := range records {
+		sum += record.Amount
 	}
+	return sum
+}
+
+// isUsageCharge reports whether an entry type charges usage for a billing period
+func isUsageCharge(recordType string) bool {
+	return recordType == RecordTypeDeduction || recordType == RecordTypeBillingReplay
+}
 
-	return cm, nil
+// billedOverlap returns the period key of the first usage charge overlapping [start, end)
+func billedOverlap(records []*RechargeRecord, start, end time.Time) string {
+	for _, record := range records {
+		if !isUsageCharge(record.Type) || record.Period == "" {
+			continue
+		}
+		from, to, err := parseWindow(record.Period, record.Timestamp)
+		if err != nil {
+			continue
+		}
+		if from.Before(end) && start.Before(to) {
+			return record.Period
+		}
+	}
+	return ""
 }
 
-func (s *BalanceService) updateConfigMap(ctx context.Context, cm *corev1.ConfigMap) error {
-	if err := s.k8sClient.UpdateConfigMap(ctx, BisonNamespace, cm); err != nil {
-		return fmt.Errorf("failed to update configmap: %w", err)
+// replayConflict returns why a usage charge for [start, end) can't be added to a ledger, or
+// "" if it can. Usage charges are recorded after their period has ended, so those folded
+// into a checkpoint all cover time before the checkpoint; a window starting earlier could
+// overlap one of them and is refused.
+func replayConflict(records []*RechargeRecord, start, end time.Time) string {
+	if billed := billedOverlap(records, start, end); billed != "" {
+		return "already billed for " + billed
 	}
-	return nil
+	if hasOpeningCheckpoint(records) && start.Before(records[0].Timestamp) {
+		return "ledger history before " + records[0].Timestamp.UTC().Format(time.RFC3339) + " was compacted"
+	}
+	return ""
+}
+
+// hasOpeningCheckpoint reports whether the ledger starts with a checkpoint
+func hasOpeningCheckpoint(records []*RechargeRecord) bool {
+	return len(records) > 0 && records[0].Type == RecordTypeCheckpoint
+}
+
+// compactLedger folds the oldest entries into a single checkpoint so that at
+// most max entries remain while the ledger sum stays unchanged
+func compactLedger(records []*RechargeRecord, max int) []*RechargeRecord {
+	if len(records) <= max {
+		return records
+	}
+
+	folded := records[:len(records)-max+1]
+	last := folded[len(folded)-1]
+	checkpoint := &RechargeRecord{
+		ID:        last.ID + "-checkpoint",
+		Timestamp: last.Timestamp,
+		Type:      RecordTypeCheckpoint,
+		Amount:    sumLedger(folded),
+		Operator:  "system",
+		Reason:    fmt.Sprintf("Checkpoint of %d entries", len(folded)),
+		Balance:   last.Balance,
+	}
+
+	return append([]*RechargeRecord{checkpoint}, records[len(records)-max+1:]...)
 }
 
 func (s *BalanceService) calculateNextExecution(config *AutoRechargeConfig) time.Time {
@@ -511,7 +1304,7 @@ func (s *BalanceService) CalculateDailyConsumption(ctx context.Context, teamName
 	var daysWithData float64 = 7 // Default to 7 days
 
 	for _, record := range records {
-		if record.Type == "deduction" && record.Timestamp.After(sevenDaysAgo) {
+		if record.Type == RecordTypeDeduction && record.Timestamp.After(sevenDaysAgo) {
 			totalDeductions += -record.Amount // Amount is negative for deductions
 		}
 	}
@@ -536,28 +1329,10 @@ func (s *BalanceService) CalculateDailyConsumption(ctx context.Context, teamName
 
 // SetOverdueAt records when a team first went into negative balance
 func (s *BalanceService) SetOverdueAt(ctx context.Context, teamName string, overdueAt *time.Time) error {
-	balance, err := s.GetBalance(ctx, teamName)
-	if err != nil {
-		return err
-	}
-
-	balance.OverdueAt = overdueAt
-	data, err := json.Marshal(balance)
-	if err != nil {
-		return fmt.Errorf("failed to marshal balance: %w", err)
-	}
-
-	cm, err := s.getOrCreateConfigMap(ctx, BalancesConfigMap)
-	if err != nil {
-		return err
-	}
-
-	if cm.Data == nil {
-		cm.Data = make(map[string]string)
-	}
-	cm.Data[teamName] = string(data)
-
-	return s.updateConfigMap(ctx, cm)
+	return s.modifyBalance(ctx, teamName, func(balance *Balance) error {
+		balance.OverdueAt = overdueAt
+		return nil
+	})
 }
 
 // GetBalanceWithEstimate returns the balance with consumption and estimated overdue time calculated
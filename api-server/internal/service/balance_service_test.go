@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"math"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/bison/api-server/internal/k8s"
+	"github.com/bison/api-server/internal/store"
+	"github.com/bison/api-server/pkg/logger"
+)
+
+func TestMain(m *testing.M) {
+	logger.Init(false)
+	os.Exit(m.Run())
+}
+
+// newConflictingClientset returns a fake clientset whose ConfigMap writes behave like the
+// API server's: creates set a resourceVersion, and an update carrying a stale one fails
+// with a Conflict. The first injected updates also fail with a Conflict.
+func newConflictingClientset(injected int) *fake.Clientset {
+	clientset := fake.NewSimpleClientset()
+	gvr := corev1.SchemeGroupVersion.WithResource("configmaps")
+	version := 0
+
+	clientset.PrependReactor("create", "configmaps", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		cm := action.(clienttesting.CreateAction).GetObject().(*corev1.ConfigMap)
+		version++
+		cm.ResourceVersion = strconv.Itoa(version)
+		return false, nil, nil
+	})
+	clientset.PrependReactor("update", "configmaps", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		cm := action.(clienttesting.UpdateAction).GetObject().(*corev1.ConfigMap)
+		conflict := apierrors.NewConflict(gvr.GroupResource(), cm.Name, nil)
+		if injected != 0 {
+			injected--
+			return true, nil, conflict
+		}
+
+		stored, err := clientset.Tracker().Get(gvr, cm.Namespace, cm.Name)
+		if err != nil {
+			return true, nil, err
+		}
+		if stored.(*corev1.ConfigMap).ResourceVersion != cm.ResourceVersion {
+			return true, nil, conflict
+		}
+		version++
+		cm.ResourceVersion = strconv.Itoa(version)
+		return false, nil, nil
+	})
+	return clientset
+}
+
+func newTestBalanceService(clientset *fake.Clientset) *BalanceService {
+	return NewBalanceService(store.NewConfigMapStore(k8s.NewClientFromInterfaces(clientset, nil), store.Namespace))
+}
+
+func TestRechargeConcurrentWritesBothLand(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestBalanceService(newConflictingClientset(1))
+
+	amounts := []float64{100, 50}
+	var wg sync.WaitGroup
+	errs := make([]error, len(amounts))
+	for i, amount := range amounts {
+		wg.Add(1)
+		go func(i int, amount float64) {
+			defer wg.Done()
+			errs[i] = svc.Recharge(ctx, "team-a", amount, "admin", "top-up")
+		}(i, amount)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("recharge %d failed: %v", i, err)
+		}
+	}
+
+	ledger, err := svc.GetLedger(ctx, "team-a")
+	if err != nil {
+		t.Fatalf("failed to get ledger: %v", err)
+	}
+	recharged := 0.0
+	recharges := 0
+	for _, record := range ledger {
+		if record.Type == RecordTypeRecharge {
+			recharges++
+			recharged += record.Amount
+		}
+	}
+	if recharges != len(amounts) || recharged != 150 {
+		t.Fatalf("ledger has %d recharges totalling %.2f, want 2 totalling 150", recharges, recharged)
+	}
+
+	balance, err := svc.GetBalance(ctx, "team-a")
+	if err != nil {
+		t.Fatalf("failed to get balance: %v", err)
+	}
+	if math.Abs(balance.Amount-150) > ledgerTolerance {
+		t.Fatalf("balance is %.2f, want 150", balance.Amount)
+	}
+}
+
+func TestRechargeGivesUpWhenConflictsPersist(t *testing.T) {
+	ctx := context.Background()
+	clientset := newConflictingClientset(0)
+	svc := newTestBalanceService(clientset)
+
+	if err := svc.Recharge(ctx, "team-a", 10, "admin", "opening"); err != nil {
+		t.Fatalf("first recharge failed: %v", err)
+	}
+
+	// Every later update loses the race
+	clientset.PrependReactor("update", "configmaps", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		cm := action.(clienttesting.UpdateAction).GetObject().(*corev1.ConfigMap)
+		return true, nil, apierrors.NewConflict(corev1.Resource("configmaps"), cm.Name, nil)
+	})
+
+	err := svc.Recharge(ctx, "team-a", 100, "admin", "top-up")
+	if err == nil {
+		t.Fatal("recharge succeeded, want an error once conflict retries are exhausted")
+	}
+	if !apierrors.IsConflict(err) {
+		t.Fatalf("got %v, want a wrapped Conflict", err)
+	}
+
+	ledger, err := svc.GetLedger(ctx, "team-a")
+	if err != nil {
+		t.Fatalf("failed to get ledger: %v", err)
+	}
+	if sum := sumLedger(ledger); math.Abs(sum-10) > ledgerTolerance {
+		t.Fatalf("ledger sums to %.2f after the failed recharge, want 10", sum)
+	}
+}
+
+func TestThresholdRechargeClaimedOnce(t *testing.T) {
+	ctx := context.Background()
+	clientset := newConflictingClientset(0)
+	svc := newTestBalanceService(clientset)
+
+	// Slow reads let the checks overlap
+	clientset.PrependReactor("get", "configmaps", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		time.Sleep(10 * time.Millisecond)
+		return false, nil, nil
+	})
+
+	config := &AutoRechargeConfig{
+		Enabled:            true,
+		Trigger:            AutoRechargeTriggerThreshold,
+		Amount:             50,
+		Threshold:          100,
+		MonthlyCap:         500,
+		MinIntervalMinutes: 60,
+	}
+	if err := svc.SetAutoRechargeConfig(ctx, "team-a", config); err != nil {
+		t.Fatalf("failed to set auto-recharge config: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 3)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = svc.CheckThresholdRecharge(ctx, "team-a")
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("check %d failed: %v", i, err)
+		}
+	}
+
+	ledger, err := svc.GetLedger(ctx, "team-a")
+	if err != nil {
+		t.Fatalf("failed to get ledger: %v", err)
+	}
+	topUps := 0
+	for _, record := range ledger {
+		if record.Type == RecordTypeAutoRecharge {
+			topUps++
+		}
+	}
+	if topUps != 1 {
+		t.Fatalf("ledger has %d auto-recharges, want 1 within the minimum interval", topUps)
+	}
+
+	stored, err := svc.GetAutoRechargeConfig(ctx, "team-a")
+	if err != nil {
+		t.Fatalf("failed to get auto-recharge config: %v", err)
+	}
+	if stored.MonthTotal != 50 {
+		t.Fatalf("month total is %.2f, want 50", stored.MonthTotal)
+	}
+}
+
+func TestReplayConflictAfterCompaction(t *testing.T) {
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	var records []*RechargeRecord
+	for i := 0; i < 10; i++ {
+		start := day.Add(time.Duration(i) * time.Hour)
+		records = append(records, &RechargeRecord{
+			ID:        strconv.Itoa(i),
+			Timestamp: start.Add(time.Hour),
+			Type:      RecordTypeDeduction,
+			Amount:    -1,
+			Period:    billingPeriodKey(start, start.Add(time.Hour)),
+		})
+	}
+	compacted := compactLedger(records, 4)
+
+	tests := []struct {
+		name     string
+		start    time.Time
+		conflict bool
+	}{
+		{"compacted period", day.Add(2 * time.Hour), true},
+		{"retained period", day.Add(8 * time.Hour), true},
+		{"before compacted history", day.Add(-2 * time.Hour), true},
+		{"after all charges", day.Add(12 * time.Hour), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conflict := replayConflict(compacted, tt.start, tt.start.Add(time.Hour))
+			if (conflict != "") != tt.conflict {
+				t.Fatalf("got conflict %q, want conflict %v", conflict, tt.conflict)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/bison/api-server/pkg/logger"
+)
+
+// MaxBillingRunWindow is the longest window a simulation or replay may cover
+const MaxBillingRunWindow = 31 * 24 * time.Hour
+
+// BillingRun is the outcome of simulating or replaying billing for a window
+type BillingRun struct {
+	Start   time.Time       `json:"start"`
+	End     time.Time       `json:"end"`
+	DryRun  bool            `json:"dryRun"`
+	Periods []*BilledPeriod `json:"periods"`
+	Teams   []*TeamCharge   `json:"teams"` // Charges per team over the whole window
+	Total   float64         `json:"total"`
+}
+
+// BilledPeriod is one billing period of a simulated or replayed window
+type BilledPeriod struct {
+	Period  string          `json:"period"`
+	Start   time.Time       `json:"start"`
+	End     time.Time       `json:"end"`
+	Charges []*TeamCharge   `json:"charges"`           // Charged, or would be charged by a replay
+	Skipped []SkippedCharge `json:"skipped,omitempty"` // Not charged
+}
+
+// SkippedCharge is a charge a replay leaves out
+type SkippedCharge struct {
+	Team   string  `json:"team"`
+	Cost   float64 `json:"cost"`
+	Reason string  `json:"reason"`
+}
+
+// SimulateBilling runs the billing pipeline for [start, end) without writing anything and
+// returns what each team would be charged. Charges that overlap periods a team was
+// already billed for are listed as skipped, as a replay would skip them.
+func (s *BillingService) SimulateBilling(ctx context.Context, start, end time.Time) (*BillingRun, error) {
+	logger.Info("Simulating billing", "start", start, "end", end)
+	return s.runBilling(ctx, start, end, "", "", true)
+}
+
+// ReplayBilling charges usage in [start, end) that regular billing missed, for example while
+// the usage source was down. Charges are recorded as "billing_replay" entries, and a team is
+// skipped for any period that overlaps a period it was already billed for, so a replay can
+// safely be run again after a failure. A team is also skipped for periods before its
+// compacted ledger history, where earlier charges can no longer be checked. The window must lie before the billing watermark so
+// regular billing never charges it afterwards.
+func (s *BillingService) ReplayBilling(ctx context.Context, start, end time.Time, operator, reason string) (*BillingRun, error) {
+	logger.Info("Replaying billing", "start", start, "end", end, "operator", operator)
+
+	state, err := s.GetBillingState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if end.After(state.LastBilledEnd) {
+		return nil, fmt.Errorf("replay window must end by the billing watermark %s", state.LastBilledEnd.Format(time.RFC3339))
+	}
+
+	return s.runBilling(ctx, start, end, operator, reason, false)
+}
+
+func (s *BillingService) runBilling(ctx context.Context, start, end time.Time, operator, reason string, dryRun bool) (*BillingRun, error) {
+	if !start.Before(end) {
+		return nil, fmt.Errorf("start must be before end")
+	}
+	if end.After(time.Now()) {
+		return nil, fmt.Errorf("window must have ended")
+	}
+	if end.Sub(start) > MaxBillingRunWindow {
+		return nil, fmt.Errorf("window must not be longer than %d days", int(MaxBillingRunWindow.Hours()/24))
+	}
+	if s.usageSource == nil || !s.usageSource.IsEnabled() {
+		return nil, fmt.Errorf("usage source is not available")
+	}
+
+	// Replays write to the same ledgers as regular billing
+	s.billingMu.Lock()
+	defer s.billingMu.Unlock()
+
+	config, err := s.GetConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	interval := time.Duration(config.Interval) * time.Hour
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	run := &BillingRun{Start: start, End: end, DryRun: dryRun, Periods: make([]*BilledPeriod, 0)}
+	totals := make(map[string]*TeamCharge)
+	charged := make(map[string]bool)
+
+	for from := start; from.Before(end); {
+		// Periods follow the regular billing boundaries; the first and last may be partial
+		to := from.UTC().Truncate(interval).Add(interval)
+		if to.After(end) {
+			to = end
+		}
+
+		period, err := s.runBillingPeriod(ctx, config, from, to, operator, reason, dryRun)
+		if err != nil {
+			return nil, fmt.Errorf("failed to bill period %s: %w", billingPeriodKey(from, to), err)
+		}
+		run.Periods = append(run.Periods, period)
+
+		for _, charge := range period.Charges {
+			if totals[charge.Team] == nil {
+				totals[charge.Team] = &TeamCharge{Team: charge.Team}
+			}
+			totals[charge.Team].Cost += charge.Cost
+			totals[charge.Team].OverheadCost += charge.OverheadCost
+			totals[charge.Team].NodeHours += charge.NodeHours
+			totals[charge.Team].CommittedCost += charge.CommittedCost
+			totals[charge.Team].BurstCost += charge.BurstCost
+			run.Total += charge.Cost
+			charged[charge.Team] = true
+		}
+		from = to
+	}
+
+	run.Teams = make([]*TeamCharge, 0, len(totals))
+	for _, total := range totals {
+		run.Teams = append(run.Teams, total)
+	}
+	sort.Slice(run.Teams, func(i, j int) bool {
+		return run.Teams[i].Team < run.Teams[j].Team
+	})
+
+	if !dryRun {
+		for teamName := range charged {
+			s.afterCharge(ctx, config, teamName)
+		}
+	}
+
+	return run, nil
+}
+
+// runBillingPeriod prices one period and, unless dryRun, charges it
+func (s *BillingService) runBillingPeriod(ctx context.Context, config *BillingConfig, start, end time.Time, operator, reason string, dryRun bool) (*BilledPeriod, error) {
+	key := billingPeriodKey(start, end)
+	period := &BilledPeriod{Period: key, Start: start, End: end, Charges: make([]*TeamCharge, 0)}
+
+	charges, err := s.periodCharges(ctx, config, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	teams := make([]string, 0, len(charges))
+	for teamName := range charges {
+		teams = append(teams, teamName)
+	}
+	sort.Strings(teams)
+
+	for _, teamName := range teams {
+		charge := charges[teamName]
+
+		conflict, err := s.balanceSvc.ReplayConflict(ctx, teamName, start, end)
+		if err != nil {
+			return nil, err
+		}
+		if conflict != "" {
+			period.Skipped = append(period.Skipped, SkippedCharge{Team: teamName, Cost: charge.Cost, Reason: conflict})
+			continue
+		}
+
+		if !dryRun {
+			entryReason := "Replayed " + charge.reason(start, end)
+			if reason != "" {
+				entryReason += ": " + reason
+			}
+			if _, err := s.balanceSvc.DeductReplay(ctx, teamName, charge.Cost, entryReason, key, operator); err != nil {
+				logger.Error("Failed to replay billing", "team", teamName, "period", key, "error", err)
+				period.Skipped = append(period.Skipped, SkippedCharge{Team: teamName, Cost: charge.Cost, Reason: err.Error()})
+				continue
+			}
+		}
+		period.Charges = append(period.Charges, charge)
+	}
+
+	return period, nil
+}
@@ -4,31 +4,36 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"strconv"
+	"sync"
 	"time"
 
-	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/bison/api-server/internal/k8s"
 	"github.com/bison/api-server/internal/opencost"
+	"github.com/bison/api-server/internal/store"
 	"github.com/bison/api-server/pkg/logger"
 )
 
 const (
-	BillingConfigMap = "bison-billing-config"
+	BillingConfigMap      = "bison-billing-config"
+	BillingStateConfigMap = "bison-billing-state"
 )
 
 // BillingConfig represents the billing configuration
 type BillingConfig struct {
-	Enabled          bool                     `json:"enabled"`
-	Interval         int                      `json:"interval"`         // Billing interval in hours
-	Currency         string                   `json:"currency"`         // e.g., "CNY", "USD"
-	CurrencySymbol   string                   `json:"currencySymbol"`   // e.g., "¥", "$"
-	Pricing          map[string]ResourcePrice `json:"pricing"`          // Resource pricing
-	GracePeriodValue int                      `json:"gracePeriodValue"` // Grace period value (e.g., 7)
-	GracePeriodUnit  string                   `json:"gracePeriodUnit"`  // Grace period unit: "hours" or "days"
+	Enabled            bool                     `json:"enabled"`
+	Interval           int                      `json:"interval"`         // Billing interval in hours
+	Currency           string                   `json:"currency"`         // e.g., "CNY", "USD"
+	CurrencySymbol     string                   `json:"currencySymbol"`   // e.g., "¥", "$"
+	Pricing            map[string]ResourcePrice `json:"pricing"`          // Resource pricing
+	GracePeriodValue   int                      `json:"gracePeriodValue"` // Grace period value (e.g., 7)
+	GracePeriodUnit    string                   `json:"gracePeriodUnit"`  // Grace period unit: "hours" or "days"
+	TimeOfUse          *TimeOfUsePricing        `json:"timeOfUse,omitempty"`
+	Overhead           *OverheadDistribution    `json:"overhead,omitempty"`
+	Suspension         *SuspensionConfig        `json:"suspension,omitempty"`
+	Invoicing          *InvoiceConfig           `json:"invoicing,omitempty"`
+	ReservationPricing map[string]ResourcePrice `json:"reservationPricing,omitempty"` // Quota pricing for committed capacity billing, memory per GB
 }
 
 // ResourcePrice represents the price for a resource
@@ -37,42 +42,238 @@ type ResourcePrice struct {
 	Unit  string  `json:"unit"`  // e.g., "核·时", "GB·时", "卡·时"
 }
 
+// Time-of-use day selectors
+const (
+	BandDaysAll     = "all"
+	BandDaysWeekday = "weekday"
+	BandDaysWeekend = "weekend" // Saturdays, Sundays and holidays
+)
+
+// StandardBand is the band reported for hours no configured band covers
+const StandardBand = "standard"
+
+// TimeOfUsePricing scales resource prices by time band (peak / off-peak / weekend)
+type TimeOfUsePricing struct {
+	Enabled  bool        `json:"enabled"`
+	Timezone string      `json:"timezone"` // IANA zone used to evaluate bands, e.g. "Asia/Shanghai"; empty = UTC
+	Holidays []string    `json:"holidays"` // Dates (2006-01-02) billed as weekend days
+	Bands    []PriceBand `json:"bands"`    // First matching band wins; other hours use base prices
+}
+
+// PriceBand is a named range of hours with a price multiplier
+type PriceBand struct {
+	Name       string  `json:"name"`       // e.g. "peak", "off-peak", "weekend"
+	Days       string  `json:"days"`       // "all", "weekday" or "weekend"
+	StartHour  int     `json:"startHour"`  // First hour of the band, 0-23
+	EndHour    int     `json:"endHour"`    // Hour the band ends (exclusive), 0-24; wraps past midnight if <= StartHour
+	Multiplier float64 `json:"multiplier"` // Applied to base prices, e.g. 0.5 = half price
+}
+
+// Validate checks the time-of-use settings
+func (t *TimeOfUsePricing) Validate() error {
+	if _, err := time.LoadLocation(t.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone %q: %w", t.Timezone, err)
+	}
+	for _, day := range t.Holidays {
+		if _, err := time.Parse("2006-01-02", day); err != nil {
+			return fmt.Errorf("invalid holiday %q, expected YYYY-MM-DD", day)
+		}
+	}
+	for _, band := range t.Bands {
+		if band.Name == "" {
+			return fmt.Errorf("price band name is required")
+		}
+		switch band.Days {
+		case "", BandDaysAll, BandDaysWeekday, BandDaysWeekend:
+		default:
+			return fmt.Errorf("price band %s: invalid days %q", band.Name, band.Days)
+		}
+		if band.StartHour < 0 || band.StartHour > 23 || band.EndHour < 0 || band.EndHour > 24 {
+			return fmt.Errorf("price band %s: hours must be within 0-24", band.Name)
+		}
+		if band.Multiplier < 0 {
+			return fmt.Errorf("price band %s: multiplier must not be negative", band.Name)
+		}
+	}
+	return nil
+}
+
+// BandAt returns the band name and price multiplier in effect at the given time
+func (t *TimeOfUsePricing) BandAt(at time.Time) (string, float64) {
+	if loc, err := time.LoadLocation(t.Timezone); err == nil {
+		at = at.In(loc)
+	}
+
+	weekend := at.Weekday() == time.Saturday || at.Weekday() == time.Sunday
+	for _, day := range t.Holidays {
+		if day == at.Format("2006-01-02") {
+			weekend = true
+			break
+		}
+	}
+
+	hour := at.Hour()
+	for _, band := range t.Bands {
+		if band.Days == BandDaysWeekday && weekend || band.Days == BandDaysWeekend && !weekend {
+			continue
+		}
+		inRange := hour >= band.StartHour && hour < band.EndHour
+		if band.EndHour <= band.StartHour {
+			inRange = hour >= band.StartHour || hour < band.EndHour
+		}
+		if inRange {
+			return band.Name, band.Multiplier
+		}
+	}
+
+	return StandardBand, 1
+}
+
+// Overhead distribution methods
+const (
+	OverheadProportional = "proportional" // By each team's share of total team cost
+	OverheadWeighted     = "weighted"     // By fixed team weights
+)
+
+// OverheadDistribution redistributes idle cluster cost and shared system-namespace
+// cost to teams for chargeback
+type OverheadDistribution struct {
+	Enabled          bool               `json:"enabled"`
+	DistributeIdle   bool               `json:"distributeIdle"`    // Include unallocated cluster capacity
+	SharedNamespaces []string           `json:"sharedNamespaces"`  // e.g. kube-system, monitoring, bison-system
+	Method           string             `json:"method"`            // "proportional" or "weighted"
+	Weights          map[string]float64 `json:"weights,omitempty"` // Team weights for the weighted method
+	ApplyToBilling   bool               `json:"applyToBilling"`    // Also deduct the shares from team balances
+}
+
+// Validate checks the overhead distribution settings
+func (d *OverheadDistribution) Validate() error {
+	switch d.Method {
+	case "", OverheadProportional:
+	case OverheadWeighted:
+		var total float64
+		for team, weight := range d.Weights {
+			if weight < 0 {
+				return fmt.Errorf("weight for team %s must not be negative", team)
+			}
+			total += weight
+		}
+		if d.Enabled && total <= 0 {
+			return fmt.Errorf("weighted overhead distribution needs at least one positive team weight")
+		}
+	default:
+		return fmt.Errorf("invalid overhead distribution method %q", d.Method)
+	}
+	return nil
+}
+
+// IsShared reports whether a namespace's cost is shared overhead
+func (d *OverheadDistribution) IsShared(namespace string) bool {
+	for _, ns := range d.SharedNamespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// Split divides an overhead amount among teams, proportionally to teamCosts or by weight
+func (d *OverheadDistribution) Split(amount float64, teamCosts map[string]float64) map[string]float64 {
+	shares := make(map[string]float64)
+	if amount <= 0 {
+		return shares
+	}
+
+	basis := teamCosts
+	if d.Method == OverheadWeighted {
+		basis = d.Weights
+	}
+
+	var total float64
+	for _, value := range basis {
+		if value > 0 {
+			total += value
+		}
+	}
+	if total <= 0 {
+		return shares
+	}
+
+	for team, value := range basis {
+		if value > 0 {
+			shares[team] = amount * value / total
+		}
+	}
+	return shares
+}
+
+// BillingState tracks billing progress across restarts
+type BillingState struct {
+	LastBilledEnd time.Time `json:"lastBilledEnd"`       // End of the last fully billed period
+	LastRunAt     time.Time `json:"lastRunAt,omitempty"` // When a period was last billed
+}
+
 // Bill represents a team/project/user bill
 type Bill struct {
-	Name          string             `json:"name"`
-	Window        string             `json:"window"`
-	TotalCost     float64            `json:"totalCost"`
-	ResourceCosts map[string]float64 `json:"resourceCosts"` // Cost breakdown by resource
-	UsageDetails  *UsageData         `json:"usageDetails"`
-	GeneratedAt   time.Time          `json:"generatedAt"`
+	Name          string                      `json:"name"`
+	Window        string                      `json:"window"`
+	TotalCost     float64                     `json:"totalCost"`
+	ResourceCosts map[string]float64          `json:"resourceCosts"`           // Cost breakdown by resource
+	BandCosts     map[string]float64          `json:"bandCosts,omitempty"`     // Cost breakdown by time-of-use band
+	Discount      float64                     `json:"discount,omitempty"`      // Savings from the team pricing policy
+	OverheadCost  float64                     `json:"overheadCost,omitempty"`  // Share of idle and shared-namespace cost
+	NodeHours     float64                     `json:"nodeHours,omitempty"`     // Reserved node-hours, for reservation billing
+	Nodes         map[string]*NodeReservation `json:"nodes,omitempty"`         // Reserved node-hours and cost by node
+	CommittedCost float64                     `json:"committedCost,omitempty"` // Quota at reservation prices, for committed capacity billing
+	BurstCost     float64                     `json:"burstCost,omitempty"`     // Usage above the quota, for committed capacity billing
+	Commitments   []*CommittedResource        `json:"commitments,omitempty"`
+	UsageDetails  *UsageData                  `json:"usageDetails"`
+	GeneratedAt   time.Time                   `json:"generatedAt"`
 }
 
 // BillingService handles billing operations
 type BillingService struct {
 	k8sClient         *k8s.Client
-	opencostClient    *opencost.Client
+	store             store.Store
+	usageSource       UsageSource
+	reservationSvc    *ReservationService
 	balanceSvc        *BalanceService
 	tenantSvc         *TenantService
 	projectSvc        *ProjectService
 	resourceConfigSvc *ResourceConfigService
+	teamPricingSvc    *TeamPricingService
+	auditSvc          *AuditService
+	alertSvc          *AlertService
+
+	billingMu sync.Mutex
 }
 
 // NewBillingService creates a new BillingService
 func NewBillingService(
 	k8sClient *k8s.Client,
-	opencostClient *opencost.Client,
+	st store.Store,
+	usageSource UsageSource,
+	reservationSvc *ReservationService,
 	balanceSvc *BalanceService,
 	tenantSvc *TenantService,
 	projectSvc *ProjectService,
 	resourceConfigSvc *ResourceConfigService,
+	teamPricingSvc *TeamPricingService,
+	auditSvc *AuditService,
+	alertSvc *AlertService,
 ) *BillingService {
 	return &BillingService{
 		k8sClient:         k8sClient,
-		opencostClient:    opencostClient,
+		store:             st,
+		usageSource:       usageSource,
+		reservationSvc:    reservationSvc,
 		balanceSvc:        balanceSvc,
 		tenantSvc:         tenantSvc,
 		projectSvc:        projectSvc,
 		resourceConfigSvc: resourceConfigSvc,
+		teamPricingSvc:    teamPricingSvc,
+		auditSvc:          auditSvc,
+		alertSvc:          alertSvc,
 	}
 }
 
@@ -80,13 +281,13 @@ func NewBillingService(
 func (s *BillingService) GetConfig(ctx context.Context) (*BillingConfig, error) {
 	logger.Debug("Getting billing config")
 
-	cm, err := s.k8sClient.GetConfigMap(ctx, BisonNamespace, BillingConfigMap)
+	entries, err := s.store.Load(ctx, BillingConfigMap)
 	if err != nil {
-		// Return default config if not found
+		logger.Warn("Failed to load billing config, using defaults", "error", err)
 		return s.getDefaultConfig(), nil
 	}
 
-	data, ok := cm.Data["config"]
+	data, ok := entries["config"]
 	if !ok {
 		return s.getDefaultConfig(), nil
 	}
@@ -104,40 +305,50 @@ func (s *BillingService) GetConfig(ctx context.Context) (*BillingConfig, error)
 func (s *BillingService) SetConfig(ctx context.Context, config *BillingConfig) error {
 	logger.Info("Setting billing config")
 
-	data, err := json.Marshal(config)
-	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
+	if config.TimeOfUse != nil {
+		if err := config.TimeOfUse.Validate(); err != nil {
+			return err
+		}
 	}
-
-	cm, err := s.k8sClient.GetConfigMap(ctx, BisonNamespace, BillingConfigMap)
-	if err != nil {
-		// Create if not exists
-		cm = &corev1.ConfigMap{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      BillingConfigMap,
-				Namespace: BisonNamespace,
-				Labels: map[string]string{
-					"app.kubernetes.io/name":      "bison",
-					"app.kubernetes.io/component": "billing",
-				},
-			},
-			Data: map[string]string{
-				"config": string(data),
-			},
+	if config.Overhead != nil {
+		if err := config.Overhead.Validate(); err != nil {
+			return err
+		}
+	}
+	if config.Suspension != nil {
+		if err := config.Suspension.Validate(); err != nil {
+			return err
+		}
+	}
+	if config.Invoicing != nil {
+		if err := config.Invoicing.Validate(); err != nil {
+			return err
+		}
+	}
+	for name, price := range config.ReservationPricing {
+		if price.Price < 0 {
+			return fmt.Errorf("reservation price for %s must not be negative", name)
 		}
-		return s.k8sClient.CreateConfigMap(ctx, BisonNamespace, cm)
 	}
 
-	if cm.Data == nil {
-		cm.Data = make(map[string]string)
+	data, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
 	}
-	cm.Data["config"] = string(data)
 
-	return s.k8sClient.UpdateConfigMap(ctx, BisonNamespace, cm)
+	return s.store.Modify(ctx, BillingConfigMap, func(entries map[string]string) error {
+		entries["config"] = string(data)
+		return nil
+	})
 }
 
-// ProcessBilling processes billing for all teams
+// ProcessBilling bills every closed period since the last billed period end.
+// Periods are exact [start, end) windows, so missed ticks and restarts are
+// caught up on the next run without charging any period twice.
 func (s *BillingService) ProcessBilling(ctx context.Context) error {
+	s.billingMu.Lock()
+	defer s.billingMu.Unlock()
+
 	logger.Info("Processing billing")
 
 	config, err := s.GetConfig(ctx)
@@ -145,115 +356,409 @@ func (s *BillingService) ProcessBilling(ctx context.Context) error {
 		return err
 	}
 
+	// Expire credit grants first so expired credit is not drawn on
+	if err := s.balanceSvc.ExpireGrants(ctx); err != nil {
+		logger.Error("Failed to expire credit grants", "error", err)
+	}
+
 	if !config.Enabled {
 		logger.Debug("Billing is disabled")
 		return nil
 	}
 
-	// Get usage from OpenCost
-	if s.opencostClient == nil || !s.opencostClient.IsEnabled() {
-		logger.Warn("OpenCost not available, skipping billing")
+	if s.usageSource == nil || !s.usageSource.IsEnabled() {
+		logger.Warn("Usage source not available, skipping billing")
 		return nil
 	}
 
-	// Get usage for the billing interval
-	window := fmt.Sprintf("%dh", config.Interval)
-	allocations, err := s.opencostClient.GetAllocationByNamespace(ctx, window)
+	interval := time.Duration(config.Interval) * time.Hour
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	state, err := s.GetBillingState(ctx)
 	if err != nil {
-		logger.Error("Failed to get allocations", "error", err)
 		return err
 	}
 
+	latestEnd := time.Now().UTC().Truncate(interval)
+
+	// First run: start tracking from the current period boundary so hours
+	// already charged by a rolling window are not billed again
+	if state.LastBilledEnd.IsZero() {
+		logger.Info("Initializing billing watermark", "lastBilledEnd", latestEnd)
+		state.LastBilledEnd = latestEnd
+		return s.saveBillingState(ctx, state)
+	}
+
+	for !state.LastBilledEnd.Add(interval).After(latestEnd) {
+		start := state.LastBilledEnd
+		end := start.Add(interval)
+
+		if err := s.billPeriod(ctx, config, start, end); err != nil {
+			return fmt.Errorf("failed to bill period %s: %w", billingPeriodKey(start, end), err)
+		}
+
+		// Advance the watermark only after the whole period is billed
+		state.LastBilledEnd = end
+		state.LastRunAt = time.Now()
+		if err := s.saveBillingState(ctx, state); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// billPeriod charges all teams for usage in the closed period [start, end)
+func (s *BillingService) billPeriod(ctx context.Context, config *BillingConfig, start, end time.Time) error {
+	period := billingPeriodKey(start, end)
+	logger.Info("Billing period", "period", period)
+
+	charges, err := s.periodCharges(ctx, config, start, end)
+	if err != nil {
+		return err
+	}
+
+	// Deduct costs from team balances
+	var failed []string
+	for teamName, charge := range charges {
+		if err := s.balanceSvc.DeductForPeriod(ctx, teamName, charge.Cost, charge.reason(start, end), period); err != nil {
+			logger.Error("Failed to deduct balance", "team", teamName, "cost", charge.Cost, "period", period, "error", err)
+			failed = append(failed, teamName)
+			continue
+		}
+
+		s.afterCharge(ctx, config, teamName)
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to deduct balance for teams: %v", failed)
+	}
+
+	return nil
+}
+
+// TeamCharge is what a team is charged for a billing period
+type TeamCharge struct {
+	Team          string  `json:"team"`
+	Cost          float64 `json:"cost"`                    // Total charge, including overhead
+	OverheadCost  float64 `json:"overheadCost,omitempty"`  // Share of idle and shared-namespace cost
+	NodeHours     float64 `json:"nodeHours,omitempty"`     // Reserved node-hours charged, for reservation billing
+	CommittedCost float64 `json:"committedCost,omitempty"` // Quota at reservation prices, for committed capacity billing
+	BurstCost     float64 `json:"burstCost,omitempty"`     // Usage above the quota, for committed capacity billing
+}
+
+// reason describes the charge in the ledger
+func (c *TeamCharge) reason(start, end time.Time) string {
+	window := fmt.Sprintf("%s ~ %s", start.Local().Format("2006-01-02 15:04"), end.Local().Format("2006-01-02 15:04"))
+	if c.NodeHours > 0 {
+		return fmt.Sprintf("Reservation billing for %s (%.0f node-hours)", window, c.NodeHours)
+	}
+	reason := "Usage billing for " + window
+	if c.CommittedCost > 0 {
+		reason = fmt.Sprintf("Committed capacity billing for %s (committed %.2f, burst %.2f)", window, c.CommittedCost, c.BurstCost)
+	}
+	if c.OverheadCost > 0 {
+		reason += fmt.Sprintf(" (incl. %.2f shared overhead)", c.OverheadCost)
+	}
+	return reason
+}
+
+// periodCharges prices every team's usage in [start, end) without charging anything.
+// Reservation-billed teams are priced by the node-hours of their exclusive pool instead, and
+// committed-billed teams by their quota plus usage above it.
+// Teams with nothing to pay are left out.
+func (s *BillingService) periodCharges(ctx context.Context, config *BillingConfig, start, end time.Time) (map[string]*TeamCharge, error) {
+	period := billingPeriodKey(start, end)
+
+	allocations, err := s.getPodAllocations(ctx, config, opencost.FormatWindow(start, end), "")
+	if err != nil {
+		logger.Error("Failed to get allocations", "period", period, "error", err)
+		return nil, err
+	}
+
 	// Get all teams
 	teams, err := s.tenantSvc.List(ctx)
 	if err != nil {
 		logger.Error("Failed to list teams", "error", err)
-		return err
+		return nil, err
 	}
 
-	// Map namespace to team
-	nsToTeam := make(map[string]string)
+	nsToTeam := s.namespaceTeams(ctx, teams)
+
+	// Aggregate costs by team, applying each team's pricing policy in effect at the period start
+	pricing := s.loadPricing(ctx)
+	policies := make(map[string]*TeamPricingPolicy)
+	reserved := make(map[string]bool)
+	committed := make(map[string]*Team)
 	for _, team := range teams {
-		projects, _ := s.projectSvc.ListByTeam(ctx, team.Name)
-		for _, project := range projects {
-			nsToTeam[project.Name] = team.Name
+		policies[team.Name] = s.activePricingPolicy(ctx, team.Name, start)
+		switch team.BillingMode {
+		case BillingModeReservation:
+			reserved[team.Name] = true
+		case BillingModeCommitted:
+			committed[team.Name] = team
 		}
 	}
 
-	// Aggregate costs by team
-	teamCosts := make(map[string]float64)
+	charges := make(map[string]*TeamCharge)
+	charge := func(teamName string) *TeamCharge {
+		if charges[teamName] == nil {
+			charges[teamName] = &TeamCharge{Team: teamName}
+		}
+		return charges[teamName]
+	}
+	committedAllocations := make(map[string][]bandedAllocation)
 	for _, alloc := range allocations {
-		teamName, ok := nsToTeam[alloc.Name]
-		if !ok {
+		teamName, ok := nsToTeam[alloc.Properties.Namespace]
+		if !ok || reserved[teamName] {
+			continue
+		}
+		if committed[teamName] != nil {
+			committedAllocations[teamName] = append(committedAllocations[teamName], alloc)
 			continue
 		}
 
 		// Calculate cost based on pricing config
-		cost := s.calculateCost(ctx, config, &alloc)
-		teamCosts[teamName] += cost
+		charge(teamName).Cost += sumCosts(s.calculateCost(config, pricing, policies[teamName], &alloc.Allocation)) * alloc.Multiplier
 	}
 
-	// Deduct costs from team balances
-	for teamName, cost := range teamCosts {
-		if cost <= 0 {
-			continue
+	// Charge each team its share of idle and shared-namespace cost
+	if overheadEnabled(config) && config.Overhead.ApplyToBilling {
+		shares, err := s.overheadShares(ctx, config, pricing, opencost.FormatWindow(start, end), nsToTeam, reserved)
+		if err != nil {
+			logger.Error("Failed to compute overhead shares", "period", period, "error", err)
+			return nil, err
+		}
+		for teamName, share := range shares {
+			if _, ok := policies[teamName]; ok && !reserved[teamName] {
+				charge(teamName).OverheadCost = share
+				charge(teamName).Cost += share
+			}
 		}
+	}
 
-		reason := fmt.Sprintf("Usage billing for %s", window)
-		if err := s.balanceSvc.Deduct(ctx, teamName, cost, reason); err != nil {
-			logger.Error("Failed to deduct balance", "team", teamName, "cost", cost, "error", err)
-			continue
+	if len(reserved) > 0 {
+		hours, err := s.reservationSvc.ReservedHours(ctx, start, end)
+		if err != nil {
+			logger.Error("Failed to load reserved nodes", "period", period, "error", err)
+			return nil, err
+		}
+		for teamName := range reserved {
+			reservation := s.priceReservation(config, pricing, policies[teamName], hours, teamName)
+			if reservation.NodeHours > 0 {
+				charge(teamName).NodeHours = reservation.NodeHours
+				charge(teamName).Cost += reservation.Cost
+			}
 		}
+	}
 
-		// Check if team is now in debt
-		balance, _ := s.balanceSvc.GetBalance(ctx, teamName)
-		if balance != nil && balance.Amount < 0 {
-			logger.Warn("Team is in debt", "team", teamName, "balance", balance.Amount)
+	for teamName, team := range committed {
+		commitment := s.priceCommitment(config, pricing, policies[teamName], team, end.Sub(start).Hours(), committedAllocations[teamName])
+		charge(teamName).CommittedCost = commitment.CommittedCost
+		charge(teamName).BurstCost = commitment.BurstCost
+		charge(teamName).Cost += commitment.CommittedCost + commitment.BurstCost
+	}
 
-			// Record when balance first went negative
-			if balance.OverdueAt == nil {
-				now := time.Now()
-				if err := s.balanceSvc.SetOverdueAt(ctx, teamName, &now); err != nil {
-					logger.Error("Failed to set overdue time", "team", teamName, "error", err)
-				}
-				balance.OverdueAt = &now
-			}
+	for teamName, c := range charges {
+		if c.Cost <= 0 {
+			delete(charges, teamName)
+		}
+	}
+	return charges, nil
+}
+
+// afterCharge runs the balance checks that follow a charge: threshold auto-recharge, then
+// the credit limit and grace period
+func (s *BillingService) afterCharge(ctx context.Context, config *BillingConfig, teamName string) {
+	if err := s.balanceSvc.CheckThresholdRecharge(ctx, teamName); err != nil {
+		logger.Error("Failed to check threshold auto-recharge", "team", teamName, "error", err)
+	}
+	s.checkOverdue(ctx, config, teamName)
+}
+
+// checkOverdue tracks when a team went beyond its credit limit and suspends it once its
+// grace period expires
+func (s *BillingService) checkOverdue(ctx context.Context, config *BillingConfig, teamName string) {
+	balance, _ := s.balanceSvc.GetBalance(ctx, teamName)
+	if balance == nil {
+		return
+	}
+	s.applyCreditPolicy(ctx, config, balance)
 
-			// Check if grace period has passed
-			if s.isGracePeriodExpired(config, balance.OverdueAt) {
-				logger.Warn("Grace period expired, suspending team", "team", teamName, "overdueAt", balance.OverdueAt)
-				if err := s.SuspendTeam(ctx, teamName); err != nil {
-					logger.Error("Failed to suspend team", "team", teamName, "error", err)
-				}
-			} else {
-				remaining := s.balanceSvc.CalculateGraceRemaining(balance.OverdueAt, config.GracePeriodValue, config.GracePeriodUnit)
-				logger.Info("Team in grace period", "team", teamName, "remaining", remaining)
+	if balance.Amount < -balance.CreditLimit {
+		logger.Warn("Team is over its credit limit", "team", teamName, "balance", balance.Amount, "creditLimit", balance.CreditLimit)
+
+		// Record when balance first went over the credit limit
+		if balance.OverdueAt == nil {
+			now := time.Now()
+			if err := s.balanceSvc.SetOverdueAt(ctx, teamName, &now); err != nil {
+				logger.Error("Failed to set overdue time", "team", teamName, "error", err)
 			}
-		} else if balance != nil && balance.Amount >= 0 && balance.OverdueAt != nil {
-			// Balance is positive again, clear overdue time
-			if err := s.balanceSvc.SetOverdueAt(ctx, teamName, nil); err != nil {
-				logger.Error("Failed to clear overdue time", "team", teamName, "error", err)
+			balance.OverdueAt = &now
+		}
+
+		if balance.NeverSuspend {
+			logger.Info("Team is exempt from automatic suspension", "team", teamName)
+			return
+		}
+
+		// Check if grace period has passed
+		if isGracePeriodExpired(balance.GracePeriodValue, balance.GracePeriodUnit, balance.OverdueAt) {
+			logger.Warn("Grace period expired, suspending team", "team", teamName, "overdueAt", balance.OverdueAt)
+			if _, err := s.SuspendTeam(ctx, teamName, "system"); err != nil {
+				logger.Error("Failed to suspend team", "team", teamName, "error", err)
 			}
+		} else {
+			remaining := s.balanceSvc.CalculateGraceRemaining(balance.OverdueAt, balance.GracePeriodValue, balance.GracePeriodUnit)
+			logger.Info("Team in grace period", "team", teamName, "remaining", remaining)
+		}
+	} else if balance.OverdueAt != nil {
+		// Balance is back within the credit limit, clear overdue time
+		if err := s.balanceSvc.SetOverdueAt(ctx, teamName, nil); err != nil {
+			logger.Error("Failed to clear overdue time", "team", teamName, "error", err)
 		}
 	}
+}
 
-	return nil
+// GetBillingState returns the persisted billing progress
+func (s *BillingService) GetBillingState(ctx context.Context) (*BillingState, error) {
+	entries, err := s.store.Load(ctx, BillingStateConfigMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get billing state: %w", err)
+	}
+
+	data, ok := entries["state"]
+	if !ok {
+		return &BillingState{}, nil
+	}
+
+	var state BillingState
+	if err := json.Unmarshal([]byte(data), &state); err != nil {
+		return nil, fmt.Errorf("failed to parse billing state: %w", err)
+	}
+
+	return &state, nil
+}
+
+func (s *BillingService) saveBillingState(ctx context.Context, state *BillingState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal billing state: %w", err)
+	}
+
+	return s.store.Modify(ctx, BillingStateConfigMap, func(entries map[string]string) error {
+		entries["state"] = string(data)
+		return nil
+	})
+}
+
+// billingPeriodKey returns the canonical key identifying a billing period
+func billingPeriodKey(start, end time.Time) string {
+	return opencost.FormatWindow(start, end)
 }
 
-// isGracePeriodExpired checks if the grace period has expired for a team
-func (s *BillingService) isGracePeriodExpired(config *BillingConfig, overdueAt *time.Time) bool {
+// isGracePeriodExpired checks if a grace period starting at overdueAt has expired
+func isGracePeriodExpired(gracePeriodValue int, gracePeriodUnit string, overdueAt *time.Time) bool {
 	if overdueAt == nil {
 		return false
 	}
+	return time.Now().After(gracePeriodEnd(gracePeriodValue, gracePeriodUnit, *overdueAt))
+}
+
+// gracePeriodEnd returns when a grace period starting at overdueAt ends
+func gracePeriodEnd(gracePeriodValue int, gracePeriodUnit string, overdueAt time.Time) time.Time {
+	if gracePeriodUnit == "hours" {
+		return overdueAt.Add(time.Duration(gracePeriodValue) * time.Hour)
+	}
+	return overdueAt.AddDate(0, 0, gracePeriodValue) // days
+}
 
-	var gracePeriodEnd time.Time
-	if config.GracePeriodUnit == "hours" {
-		gracePeriodEnd = overdueAt.Add(time.Duration(config.GracePeriodValue) * time.Hour)
-	} else { // days
-		gracePeriodEnd = overdueAt.AddDate(0, 0, config.GracePeriodValue)
+// NotifySuspensionCountdowns warns the owners of every team in its grace period as
+// suspension approaches
+func (s *BillingService) NotifySuspensionCountdowns(ctx context.Context) error {
+	config, err := s.GetConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if !config.Enabled {
+		return nil
 	}
 
-	return time.Now().After(gracePeriodEnd)
+	balances, err := s.balanceSvc.GetAllBalances(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, balance := range balances {
+		if balance.OverdueAt == nil {
+			continue
+		}
+		s.applyCreditPolicy(ctx, config, balance)
+		if balance.NeverSuspend {
+			continue
+		}
+
+		team, err := s.tenantSvc.Get(ctx, balance.TeamName)
+		if err != nil {
+			logger.Warn("Failed to get team for suspension countdown", "team", balance.TeamName, "error", err)
+			continue
+		}
+		if team.Suspended {
+			continue
+		}
+
+		deadline := gracePeriodEnd(balance.GracePeriodValue, balance.GracePeriodUnit, *balance.OverdueAt)
+		if err := s.alertSvc.NotifySuspensionCountdown(ctx, team.Name, team.Owners, *balance.OverdueAt, deadline); err != nil {
+			logger.Error("Failed to notify suspension countdown", "team", team.Name, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// applyCreditPolicy fills in the team's effective credit limit, grace period and
+// suspension flag, and the grace period left when it is over the limit
+func (s *BillingService) applyCreditPolicy(ctx context.Context, config *BillingConfig, balance *Balance) {
+	balance.GracePeriodValue = config.GracePeriodValue
+	balance.GracePeriodUnit = config.GracePeriodUnit
+
+	policy, err := s.balanceSvc.GetCreditPolicy(ctx, balance.TeamName)
+	if err != nil {
+		logger.Warn("Failed to get credit policy, using defaults", "team", balance.TeamName, "error", err)
+	}
+	if policy != nil {
+		balance.CreditLimit = policy.CreditLimit
+		balance.NeverSuspend = policy.NeverSuspend
+		if policy.GracePeriodUnit != "" {
+			balance.GracePeriodValue = policy.GracePeriodValue
+			balance.GracePeriodUnit = policy.GracePeriodUnit
+		}
+	}
+
+	if balance.OverdueAt != nil && !balance.NeverSuspend {
+		balance.GraceRemaining = s.balanceSvc.CalculateGraceRemaining(balance.OverdueAt, balance.GracePeriodValue, balance.GracePeriodUnit)
+	}
+}
+
+// GetTeamBalance returns a team's balance with its effective credit policy
+func (s *BillingService) GetTeamBalance(ctx context.Context, teamName string) (*Balance, error) {
+	balance, err := s.balanceSvc.GetBalance(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := s.GetConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.applyCreditPolicy(ctx, config, balance)
+
+	if err := s.balanceSvc.SplitBalance(ctx, balance); err != nil {
+		return nil, err
+	}
+	return balance, nil
 }
 
 // GetTeamBill returns a bill for a specific team
@@ -268,48 +773,104 @@ func (s *BillingService) GetTeamBill(ctx context.Context, teamName, window strin
 		return nil, err
 	}
 
-	// Get allocations for each project
-	var totalCost float64
-	var totalUsage UsageData
-	resourceCosts := make(map[string]float64)
-
+	bill := newBill(teamName, window)
 	config, _ := s.GetConfig(ctx)
 
-	if s.opencostClient != nil && s.opencostClient.IsEnabled() {
+	// Reservation-billed teams pay for the node-hours of their exclusive pool
+	team, err := s.tenantSvc.Get(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	if team.BillingMode == BillingModeReservation {
+		return s.reservationBill(ctx, bill, config, teamName, window)
+	}
+
+	// Get allocations for each project
+	if s.usageSource != nil && s.usageSource.IsEnabled() {
+		pricing := s.loadPricing(ctx)
+		policy := s.activePricingPolicy(ctx, teamName, time.Now())
+		var teamAllocations []bandedAllocation
 		for _, project := range projects {
-			allocations, err := s.opencostClient.GetAllocationForNamespace(ctx, window, project.Name)
+			allocations, err := s.getPodAllocations(ctx, config, window, project.Name)
 			if err != nil {
 				logger.Warn("Failed to get allocations for project", "project", project.Name, "error", err)
 				continue
 			}
+			s.addToBill(bill, config, pricing, policy, allocations)
+			teamAllocations = append(teamAllocations, allocations...)
+		}
 
-			for _, alloc := range allocations {
-				totalUsage.CPUCoreHours += alloc.CPUCoreHours
-				totalUsage.RAMGBHours += alloc.RAMGBHours
-				totalUsage.GPUHours += alloc.GPUHours
-				totalUsage.Minutes += alloc.Minutes
-
-				cost := s.calculateCost(ctx, config, &alloc)
-				totalCost += cost
+		// Committed-billed teams pay for their quota, plus usage above it
+		if team.BillingMode == BillingModeCommitted {
+			start, end, err := parseWindow(window, time.Now())
+			if err != nil {
+				return nil, err
+			}
+			applyCommitment(bill, s.priceCommitment(config, pricing, policy, team, end.Sub(start).Hours(), teamAllocations))
+		}
 
-				resourceCosts["cpu"] += alloc.CPUCost
-				resourceCosts["memory"] += alloc.RAMCost
-				resourceCosts["gpu"] += alloc.GPUCost
+		if overheadEnabled(config) {
+			share, err := s.teamOverheadShare(ctx, config, pricing, teamName, window)
+			if err != nil {
+				return nil, err
 			}
+			bill.OverheadCost = share
+			bill.TotalCost += bill.OverheadCost
+			bill.UsageDetails.OverheadCost = bill.OverheadCost
+			bill.UsageDetails.TotalCost = bill.TotalCost
 		}
 	}
 
-	totalUsage.Name = teamName
-	totalUsage.TotalCost = totalCost
+	return bill, nil
+}
 
-	return &Bill{
-		Name:          teamName,
-		Window:        window,
-		TotalCost:     totalCost,
-		ResourceCosts: resourceCosts,
-		UsageDetails:  &totalUsage,
-		GeneratedAt:   time.Now(),
-	}, nil
+// reservationBill fills a bill with the node-hours a reservation-billed team held in the window
+func (s *BillingService) reservationBill(ctx context.Context, bill *Bill, config *BillingConfig, teamName, window string) (*Bill, error) {
+	start, end, err := parseWindow(window, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	reservation, err := s.teamReservationCost(ctx, config, s.loadPricing(ctx), s.activePricingPolicy(ctx, teamName, time.Now()), teamName, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	bill.ResourceCosts = reservation.ResourceCosts
+	bill.BandCosts = reservation.BandCosts
+	bill.Discount = reservation.Discount
+	bill.TotalCost = reservation.Cost
+	bill.NodeHours = reservation.NodeHours
+	bill.Nodes = reservation.Nodes
+	for resource, cost := range reservation.ResourceCosts {
+		bill.UsageDetails.addResourceCost(resource, cost)
+	}
+	bill.UsageDetails.TotalCost = bill.TotalCost
+	return bill, nil
+}
+
+// teamReservationCost prices the node-hours a team held in [start, end)
+func (s *BillingService) teamReservationCost(ctx context.Context, config *BillingConfig, pricing *resourcePricing, policy *TeamPricingPolicy, teamName string, start, end time.Time) (*ReservationCost, error) {
+	hours, err := s.reservationSvc.ReservedHours(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return s.priceReservation(config, pricing, policy, hours, teamName), nil
+}
+
+// teamOverheadShare returns a team's share of idle and shared-namespace cost for a window.
+// A failure to price the overhead is logged and counts as no share.
+func (s *BillingService) teamOverheadShare(ctx context.Context, config *BillingConfig, pricing *resourcePricing, teamName, window string) (float64, error) {
+	teams, err := s.tenantSvc.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	shares, err := s.overheadShares(ctx, config, pricing, window, s.namespaceTeams(ctx, teams), reservedTeams(teams))
+	if err != nil {
+		logger.Warn("Failed to compute overhead share", "team", teamName, "error", err)
+		return 0, nil
+	}
+	return shares[teamName], nil
 }
 
 // GetProjectBill returns a bill for a specific project
@@ -317,75 +878,224 @@ func (s *BillingService) GetProjectBill(ctx context.Context, projectName, window
 	if window == "" {
 		window = "7d"
 	}
+	return s.projectBill(ctx, projectName, window, time.Now())
+}
 
-	var totalCost float64
-	var usage UsageData
-	resourceCosts := make(map[string]float64)
-
+// projectBill prices a project's usage in a window under the team pricing policy in
+// effect at pricedAt
+func (s *BillingService) projectBill(ctx context.Context, projectName, window string, pricedAt time.Time) (*Bill, error) {
+	bill := newBill(projectName, window)
 	config, _ := s.GetConfig(ctx)
 
-	if s.opencostClient != nil && s.opencostClient.IsEnabled() {
-		allocations, err := s.opencostClient.GetAllocationForNamespace(ctx, window, projectName)
+	if s.usageSource != nil && s.usageSource.IsEnabled() {
+		allocations, err := s.getPodAllocations(ctx, config, window, projectName)
 		if err != nil {
 			return nil, err
 		}
 
-		for _, alloc := range allocations {
-			usage.CPUCoreHours += alloc.CPUCoreHours
-			usage.RAMGBHours += alloc.RAMGBHours
-			usage.GPUHours += alloc.GPUHours
-			usage.Minutes += alloc.Minutes
-
-			cost := s.calculateCost(ctx, config, &alloc)
-			totalCost += cost
-
-			resourceCosts["cpu"] += alloc.CPUCost
-			resourceCosts["memory"] += alloc.RAMCost
-			resourceCosts["gpu"] += alloc.GPUCost
+		// Projects are billed under their team's pricing policy
+		var policy *TeamPricingPolicy
+		if project, err := s.projectSvc.Get(ctx, projectName); err == nil && project.Team != "" {
+			policy = s.activePricingPolicy(ctx, project.Team, pricedAt)
 		}
+
+		s.addToBill(bill, config, s.loadPricing(ctx), policy, allocations)
 	}
 
-	usage.Name = projectName
-	usage.TotalCost = totalCost
+	return bill, nil
+}
 
+// newBill returns an empty bill
+func newBill(name, window string) *Bill {
 	return &Bill{
-		Name:          projectName,
+		Name:          name,
 		Window:        window,
-		TotalCost:     totalCost,
-		ResourceCosts: resourceCosts,
-		UsageDetails:  &usage,
+		ResourceCosts: make(map[string]float64),
+		BandCosts:     make(map[string]float64),
+		UsageDetails:  &UsageData{Name: name},
 		GeneratedAt:   time.Now(),
-	}, nil
+	}
+}
+
+// addToBill prices allocations into a bill's totals and breakdowns
+func (s *BillingService) addToBill(bill *Bill, config *BillingConfig, pricing *resourcePricing, policy *TeamPricingPolicy, allocations []bandedAllocation) {
+	usage := bill.UsageDetails
+	for _, alloc := range allocations {
+		usage.CPUCoreHours += alloc.CPUCoreHours
+		usage.RAMGBHours += alloc.RAMGBHours
+		usage.GPUHours += alloc.GPUHours
+		usage.PVGBHours += alloc.PVByteHours / bytesPerGB
+		usage.NetworkGB += alloc.NetworkTransferBytes / bytesPerGB
+		usage.Minutes += alloc.Minutes
+
+		var charged float64
+		for resource, cost := range s.calculateCost(config, pricing, policy, &alloc.Allocation) {
+			cost *= alloc.Multiplier
+			bill.ResourceCosts[resource] += cost
+			usage.addResourceCost(resource, cost)
+			charged += cost
+			if alloc.Band != "" {
+				bill.BandCosts[alloc.Band] += cost
+			}
+		}
+		bill.TotalCost += charged
+
+		if policy != nil {
+			listCost := sumCosts(s.calculateCost(config, pricing, nil, &alloc.Allocation)) * alloc.Multiplier
+			bill.Discount += listCost - charged
+		}
+	}
+	usage.TotalCost = bill.TotalCost
+}
+
+// namespaceTeams maps each project namespace to its team
+func (s *BillingService) namespaceTeams(ctx context.Context, teams []*Team) map[string]string {
+	nsToTeam := make(map[string]string)
+	for _, team := range teams {
+		projects, _ := s.projectSvc.ListByTeam(ctx, team.Name)
+		for _, project := range projects {
+			nsToTeam[project.Name] = team.Name
+		}
+	}
+	return nsToTeam
+}
+
+// reservedTeams returns the names of the reservation-billed teams
+func reservedTeams(teams []*Team) map[string]bool {
+	reserved := make(map[string]bool)
+	for _, team := range teams {
+		if team.BillingMode == BillingModeReservation {
+			reserved[team.Name] = true
+		}
+	}
+	return reserved
+}
+
+// overheadEnabled reports whether idle and shared cost is redistributed to teams
+func overheadEnabled(config *BillingConfig) bool {
+	return config != nil && config.Enabled && config.Overhead != nil && config.Overhead.Enabled
+}
+
+// overheadShares prices idle and shared-namespace cost for a window and splits it among teams.
+// Overhead and the team costs used for proportional splits are priced at list prices.
+// Reserved teams already pay for their nodes' idle capacity, so they are left out of both the
+// proportional and the weighted basis and the other teams absorb all of the overhead.
+func (s *BillingService) overheadShares(ctx context.Context, config *BillingConfig, pricing *resourcePricing, window string, nsToTeam map[string]string, reserved map[string]bool) (map[string]float64, error) {
+	dist := *config.Overhead
+	if dist.Method == OverheadWeighted && len(reserved) > 0 {
+		dist.Weights = make(map[string]float64, len(config.Overhead.Weights))
+		for teamName, weight := range config.Overhead.Weights {
+			if !reserved[teamName] {
+				dist.Weights[teamName] = weight
+			}
+		}
+	}
+
+	allocations, err := s.usageSource.GetNamespaceAllocations(ctx, window, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var pool float64
+	teamCosts := make(map[string]float64)
+	for _, alloc := range allocations {
+		cost := sumCosts(s.calculateCost(config, pricing, nil, &alloc))
+		switch {
+		case opencost.IsIdle(alloc.Name):
+			if dist.DistributeIdle {
+				pool += cost
+			}
+		case dist.IsShared(alloc.Name):
+			pool += cost
+		default:
+			if teamName, ok := nsToTeam[alloc.Name]; ok && !reserved[teamName] {
+				teamCosts[teamName] += cost
+			}
+		}
+	}
+
+	return dist.Split(pool, teamCosts), nil
+}
+
+// activePricingPolicy returns the team's pricing policy if it is in effect at the given time
+func (s *BillingService) activePricingPolicy(ctx context.Context, teamName string, at time.Time) *TeamPricingPolicy {
+	policy, err := s.teamPricingSvc.Get(ctx, teamName)
+	if err != nil {
+		logger.Warn("Failed to get team pricing policy, using list prices", "team", teamName, "error", err)
+		return nil
+	}
+	if !policy.ActiveAt(at) {
+		return nil
+	}
+	return policy
 }
 
-// SuspendTeam suspends a team due to insufficient balance
-func (s *BillingService) SuspendTeam(ctx context.Context, teamName string) error {
-	logger.Info("Suspending team", "team", teamName)
+// SuspendTeam suspends a team due to insufficient balance and returns what was stopped and
+// what exemptions kept running
+func (s *BillingService) SuspendTeam(ctx context.Context, teamName, operator string) (*SuspensionResult, error) {
+	logger.Info("Suspending team", "team", teamName, "operator", operator)
+
+	team, err := s.tenantSvc.Get(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
 
 	// Mark team as suspended
 	if err := s.tenantSvc.SetSuspended(ctx, teamName, true); err != nil {
-		return err
+		return nil, err
 	}
 
 	// Get all projects for this team
 	projects, err := s.projectSvc.ListByTeam(ctx, teamName)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Scale down all deployments and statefulsets in each project
+	config, _ := s.GetConfig(ctx)
+	result := &SuspensionResult{Team: teamName, Namespaces: []*SuspensionRecord{}}
+
+	// Stop all workloads in each project
+	var exempted []string
 	for _, project := range projects {
-		if err := s.scaleDownNamespace(ctx, project.Name); err != nil {
-			logger.Error("Failed to scale down namespace", "namespace", project.Name, "error", err)
+		record, stopped, err := s.suspendNamespace(ctx, config, teamName, project.Name)
+		if err != nil {
+			logger.Error("Failed to suspend namespace", "namespace", project.Name, "error", err)
+		}
+		result.Stopped += stopped
+		if record != nil {
+			result.Namespaces = append(result.Namespaces, record)
+			for _, obj := range record.Exempted {
+				exempted = append(exempted, fmt.Sprintf("%s/%s/%s (%s)", project.Name, obj.Kind, obj.Name, obj.Reason))
+			}
 		}
 	}
 
-	return nil
+	// Overdue teams are suspended again on every billing run; only audit runs that changed something
+	if operator != "system" || result.Stopped > 0 {
+		s.auditSvc.LogAction(ctx, operator, "suspend", "team", teamName, map[string]interface{}{
+			"strategy": suspensionStrategy(config),
+			"stopped":  result.Stopped,
+			"exempted": exempted,
+		})
+	}
+
+	if !team.Suspended {
+		if err := s.alertSvc.NotifySuspended(ctx, teamName, team.Owners, result.Stopped); err != nil {
+			logger.Error("Failed to send suspension notice", "team", teamName, "error", err)
+		}
+	}
+
+	return result, nil
 }
 
 // ResumeTeam resumes a suspended team
-func (s *BillingService) ResumeTeam(ctx context.Context, teamName string) error {
-	logger.Info("Resuming team", "team", teamName)
+func (s *BillingService) ResumeTeam(ctx context.Context, teamName, operator string) error {
+	logger.Info("Resuming team", "team", teamName, "operator", operator)
+
+	team, err := s.tenantSvc.Get(ctx, teamName)
+	if err != nil {
+		return err
+	}
 
 	// Check balance
 	balance, err := s.balanceSvc.GetBalance(ctx, teamName)
@@ -393,8 +1103,10 @@ func (s *BillingService) ResumeTeam(ctx context.Context, teamName string) error
 		return err
 	}
 
-	if balance.Amount < 0 {
-		return fmt.Errorf("cannot resume team with negative balance: %.2f", balance.Amount)
+	config, _ := s.GetConfig(ctx)
+	s.applyCreditPolicy(ctx, config, balance)
+	if balance.Amount < -balance.CreditLimit {
+		return fmt.Errorf("cannot resume team with balance %.2f beyond its credit limit of %.2f", balance.Amount, balance.CreditLimit)
 	}
 
 	// Mark team as not suspended
@@ -408,13 +1120,25 @@ func (s *BillingService) ResumeTeam(ctx context.Context, teamName string) error
 		return err
 	}
 
-	// Scale up all deployments and statefulsets in each project
+	// Restore the workloads stopped in each project, except projects their budgets keep suspended
+	budgetSuspended := s.budgetSuspendedProjects(ctx, teamName)
 	for _, project := range projects {
-		if err := s.scaleUpNamespace(ctx, project.Name); err != nil {
-			logger.Error("Failed to scale up namespace", "namespace", project.Name, "error", err)
+		if budgetSuspended[project.Name] {
+			logger.Info("Keeping project suspended by its budget", "project", project.Name)
+			continue
+		}
+		if err := s.resumeNamespace(ctx, project.Name); err != nil {
+			logger.Error("Failed to resume namespace", "namespace", project.Name, "error", err)
 		}
 	}
 
+	s.auditSvc.LogAction(ctx, operator, "resume", "team", teamName, nil)
+
+	if team.Suspended {
+		if err := s.alertSvc.NotifyResumed(ctx, teamName, team.Owners); err != nil {
+			logger.Error("Failed to send resume notice", "team", teamName, "error", err)
+		}
+	}
 	return nil
 }
 
@@ -452,193 +1176,260 @@ func (s *BillingService) getDefaultConfig() *BillingConfig {
 	}
 }
 
-func (s *BillingService) calculateCost(ctx context.Context, config *BillingConfig, alloc *opencost.Allocation) float64 {
-	if config == nil || !config.Enabled {
-		return alloc.TotalCost
-	}
-
-	var cost float64
-
-	// Get resource configs for pricing
-	resourceConfigs, _ := s.resourceConfigSvc.GetEnabledResourceConfigs(ctx)
+// bandedAllocation is a pod allocation tagged with the time-of-use band it was billed in
+type bandedAllocation struct {
+	opencost.Allocation
+	Band       string  // Empty when time-of-use pricing is off
+	Multiplier float64 // Price multiplier of the band
+}
 
-	// Build price map and find accelerator price
-	cpuPrice := float64(0)
-	memoryPrice := float64(0)
-	acceleratorPrice := float64(0)
+// getPodAllocations returns pod-level allocations for a window, optionally limited to one namespace.
+// Pod allocations carry the node each pod ran on, so accelerators are priced per model. With
+// time-of-use pricing the window is queried in hourly steps and each step is tagged with its band.
+func (s *BillingService) getPodAllocations(ctx context.Context, config *BillingConfig, window, namespace string) ([]bandedAllocation, error) {
+	var result []bandedAllocation
 
-	for _, rc := range resourceConfigs {
-		if rc.Price <= 0 {
-			continue
+	if config != nil && config.Enabled && config.TimeOfUse != nil && config.TimeOfUse.Enabled {
+		steps, err := s.usageSource.GetPodAllocationSteps(ctx, window, namespace, "1h")
+		if err != nil {
+			return nil, err
 		}
-		switch rc.Name {
-		case "cpu":
-			cpuPrice = rc.Price
-		case "memory":
-			memoryPrice = rc.Price
-		default:
-			// For accelerators (any non-cpu/memory resource), use the first one with price
-			if rc.Category == CategoryAccelerator && acceleratorPrice == 0 {
-				acceleratorPrice = rc.Price
+		for _, step := range steps {
+			band, multiplier := config.TimeOfUse.BandAt(step.Start)
+			for _, alloc := range step.Allocations {
+				result = append(result, bandedAllocation{Allocation: alloc, Band: band, Multiplier: multiplier})
 			}
 		}
+		return result, nil
 	}
 
-	// CPU cost
-	if cpuPrice > 0 {
-		cost += alloc.CPUCoreHours * cpuPrice
-	} else {
-		cost += alloc.CPUCost
-	}
-
-	// Memory cost
-	if memoryPrice > 0 {
-		cost += alloc.RAMGBHours * memoryPrice
-	} else {
-		cost += alloc.RAMCost
+	allocations, err := s.usageSource.GetPodAllocations(ctx, window, namespace)
+	if err != nil {
+		return nil, err
 	}
-
-	// GPU/Accelerator cost (OpenCost reports all accelerators as GPUHours)
-	if acceleratorPrice > 0 {
-		cost += alloc.GPUHours * acceleratorPrice
-	} else {
-		cost += alloc.GPUCost
+	for _, alloc := range allocations {
+		result = append(result, bandedAllocation{Allocation: alloc, Multiplier: 1})
 	}
+	return result, nil
+}
 
-	return cost
+// resourcePricing holds the unit prices used to cost allocations in one billing pass
+type resourcePricing struct {
+	cpuPrice     float64
+	memoryPrice  float64
+	accelerators []ResourceDefinition    // Priced accelerator resources
+	extended     []ResourceDefinition    // Priced accelerators and other extended resources, for metered usage
+	nodes        map[string]*corev1.Node // Nodes by name, used to resolve the hardware a pod ran on
+
+	// Billing-only resources, nil when not enabled (and then not charged)
+	storage      *ResourceDefinition
+	network      *ResourceDefinition
+	loadBalancer *ResourceDefinition
+	pvClasses    map[string]string // PersistentVolume name to StorageClass
 }
 
-func (s *BillingService) scaleDownNamespace(ctx context.Context, namespace string) error {
-	// Scale down deployments
-	deployments, err := s.k8sClient.ListDeployments(ctx, namespace)
-	if err != nil {
-		return err
+// loadPricing reads resource prices and, when extended resources are priced, the current nodes
+func (s *BillingService) loadPricing(ctx context.Context) *resourcePricing {
+	pricing := &resourcePricing{
+		nodes:     make(map[string]*corev1.Node),
+		pvClasses: make(map[string]string),
 	}
 
-	for _, deploy := range deployments.Items {
-		if *deploy.Spec.Replicas == 0 {
-			continue
+	resourceConfigs, _ := s.resourceConfigSvc.GetEnabledResourceConfigs(ctx)
+	for _, rc := range resourceConfigs {
+		switch {
+		case rc.Name == "cpu":
+			pricing.cpuPrice = rc.Price
+		case rc.Name == "memory":
+			pricing.memoryPrice = rc.Price
+		case rc.Name == ResourcePersistentStorage:
+			pricing.storage = &rc
+		case rc.Name == ResourceNetworkEgress:
+			pricing.network = &rc
+		case rc.Name == ResourceLoadBalancer:
+			pricing.loadBalancer = &rc
+		case rc.Category == CategoryAccelerator && (rc.Price > 0 || len(rc.ProductPrices) > 0):
+			pricing.accelerators = append(pricing.accelerators, rc)
+			pricing.extended = append(pricing.extended, rc)
+		case rc.Price > 0 || len(rc.ProductPrices) > 0:
+			pricing.extended = append(pricing.extended, rc)
 		}
+	}
 
-		// Save original replicas
-		if deploy.Annotations == nil {
-			deploy.Annotations = make(map[string]string)
+	if pricing.storage != nil && len(pricing.storage.ProductPrices) > 0 {
+		pvs, err := s.k8sClient.ListPersistentVolumes(ctx)
+		if err != nil {
+			logger.Warn("Failed to list persistent volumes for storage pricing", "error", err)
+		} else {
+			for _, pv := range pvs.Items {
+				pricing.pvClasses[pv.Name] = pv.Spec.StorageClassName
+			}
 		}
-		deploy.Annotations["bison.io/original-replicas"] = fmt.Sprintf("%d", *deploy.Spec.Replicas)
-
-		// Scale to 0
-		zero := int32(0)
-		deploy.Spec.Replicas = &zero
+	}
 
-		if err := s.k8sClient.UpdateDeployment(ctx, namespace, &deploy); err != nil {
-			logger.Error("Failed to scale down deployment", "namespace", namespace, "name", deploy.Name, "error", err)
+	if len(pricing.extended) > 0 {
+		nodes, err := s.k8sClient.ListNodes(ctx)
+		if err != nil {
+			logger.Warn("Failed to list nodes for accelerator pricing", "error", err)
+		} else {
+			for i := range nodes.Items {
+				pricing.nodes[nodes.Items[i].Name] = &nodes.Items[i]
+			}
 		}
 	}
 
-	// Scale down statefulsets
-	statefulsets, err := s.k8sClient.ListStatefulSets(ctx, namespace)
-	if err != nil {
-		return err
-	}
+	return pricing
+}
 
-	for _, sts := range statefulsets.Items {
-		if *sts.Spec.Replicas == 0 {
-			continue
+// acceleratorPrice resolves the accelerator resource, hardware model and hourly price for usage on a node
+func (p *resourcePricing) acceleratorPrice(nodeName string) (resource, product string, price float64) {
+	if node, ok := p.nodes[nodeName]; ok {
+		for i := range p.accelerators {
+			rc := &p.accelerators[i]
+			capacity, has := node.Status.Capacity[corev1.ResourceName(rc.Name)]
+			if !has || capacity.IsZero() {
+				continue
+			}
+			product, price = p.productPrice(rc, nodeName)
+			return rc.Name, product, price
 		}
+	}
 
-		// Save original replicas
-		if sts.Annotations == nil {
-			sts.Annotations = make(map[string]string)
+	// Node unknown (e.g. removed since the usage): fall back to the first accelerator with a base price
+	for _, rc := range p.accelerators {
+		if rc.Price > 0 {
+			return rc.Name, "", rc.Price
 		}
-		sts.Annotations["bison.io/original-replicas"] = fmt.Sprintf("%d", *sts.Spec.Replicas)
-
-		// Scale to 0
-		zero := int32(0)
-		sts.Spec.Replicas = &zero
+	}
+	return "", "", 0
+}
 
-		if err := s.k8sClient.UpdateStatefulSet(ctx, namespace, &sts); err != nil {
-			logger.Error("Failed to scale down statefulset", "namespace", namespace, "name", sts.Name, "error", err)
+// productPrice resolves the model of a resource on a node and its hourly price
+func (p *resourcePricing) productPrice(rc *ResourceDefinition, nodeName string) (product string, price float64) {
+	price = rc.Price
+	if node, ok := p.nodes[nodeName]; ok {
+		product = node.Labels[rc.GetProductLabel()]
+		if productPrice, ok := rc.ProductPrices[product]; ok && product != "" {
+			price = productPrice
 		}
 	}
+	return product, price
+}
 
-	// Delete orphan pods (pods not managed by a controller)
-	pods, err := s.k8sClient.ListPods(ctx, namespace, "")
-	if err != nil {
-		logger.Error("Failed to list pods", "namespace", namespace, "error", err)
-		return nil // Don't fail the whole operation
-	}
+// calculateCost returns the cost of an allocation broken down by resource, with the team's
+// pricing policy (if any) applied. Accelerator costs are keyed by resource name, suffixed
+// with ":<model>" when the model is known.
+func (s *BillingService) calculateCost(config *BillingConfig, pricing *resourcePricing, policy *TeamPricingPolicy, alloc *opencost.Allocation) map[string]float64 {
+	costs := make(map[string]float64)
 
-	for _, pod := range pods.Items {
-		// Check if pod is managed by a controller
-		if len(pod.OwnerReferences) == 0 {
-			// Orphan pod - delete it
-			logger.Info("Deleting orphan pod", "namespace", namespace, "name", pod.Name)
-			if err := s.k8sClient.DeletePod(ctx, namespace, pod.Name); err != nil {
-				logger.Error("Failed to delete orphan pod", "namespace", namespace, "name", pod.Name, "error", err)
-			}
+	if config == nil || !config.Enabled {
+		costs["cpu"] = alloc.CPUCost
+		costs["memory"] = alloc.RAMCost
+		costs["gpu"] = alloc.GPUCost
+		costs[ResourcePersistentStorage] = alloc.PVCost
+		costs[ResourceNetworkEgress] = alloc.NetworkCost
+		costs[ResourceLoadBalancer] = alloc.LoadBalancerCost
+		known := alloc.CPUCost + alloc.RAMCost + alloc.GPUCost + alloc.PVCost + alloc.NetworkCost + alloc.LoadBalancerCost
+		if other := alloc.TotalCost - known; other > 0.000001 {
+			costs["other"] = other
 		}
+		return costs
 	}
 
-	return nil
-}
+	costs["cpu"] = componentCost(policy, "cpu", "", alloc.CPUCoreHours, pricing.cpuPrice, alloc.CPUCost)
+	costs["memory"] = componentCost(policy, "memory", "", alloc.RAMGBHours, pricing.memoryPrice, alloc.RAMCost)
 
-func (s *BillingService) scaleUpNamespace(ctx context.Context, namespace string) error {
-	// Scale up deployments
-	deployments, err := s.k8sClient.ListDeployments(ctx, namespace)
-	if err != nil {
-		return err
-	}
-
-	for _, deploy := range deployments.Items {
-		originalStr, ok := deploy.Annotations["bison.io/original-replicas"]
-		if !ok {
-			continue
+	// Accelerators and other extended resources. Metered usage carries the hours of each
+	// resource; OpenCost reports all accelerators as GPUHours.
+	if len(alloc.ResourceHours) > 0 {
+		for i := range pricing.extended {
+			rc := &pricing.extended[i]
+			hours := alloc.ResourceHours[rc.Name]
+			if hours == 0 {
+				continue
+			}
+			product, price := pricing.productPrice(rc, alloc.Properties.Node)
+			key := rc.Name
+			if product != "" {
+				key = rc.Name + ":" + product
+			}
+			costs[key] += componentCost(policy, rc.Name, product, hours, price, 0)
 		}
-
-		original, err := strconv.ParseInt(originalStr, 10, 32)
-		if err != nil {
-			continue
+	} else if alloc.GPUHours > 0 || alloc.GPUCost > 0 {
+		resource, product, price := pricing.acceleratorPrice(alloc.Properties.Node)
+		if price > 0 {
+			key := resource
+			if product != "" {
+				key = resource + ":" + product
+			}
+			costs[key] += componentCost(policy, resource, product, alloc.GPUHours, price, 0)
+		} else {
+			costs["gpu"] += componentCost(policy, "gpu", "", alloc.GPUHours, 0, alloc.GPUCost)
 		}
+	}
 
-		// Restore original replicas
-		replicas := int32(original)
-		deploy.Spec.Replicas = &replicas
-		delete(deploy.Annotations, "bison.io/original-replicas")
-
-		if err := s.k8sClient.UpdateDeployment(ctx, namespace, &deploy); err != nil {
-			logger.Error("Failed to scale up deployment", "namespace", namespace, "name", deploy.Name, "error", err)
+	// Persistent volumes, per StorageClass when class prices are set
+	if rc := pricing.storage; rc != nil {
+		if len(alloc.PVs) == 0 && alloc.PVByteHours > 0 {
+			costs[rc.Name] += componentCost(policy, rc.Name, "", alloc.PVByteHours/bytesPerGB, rc.Price, alloc.PVCost)
+		}
+		for key, pv := range alloc.PVs {
+			class := pricing.pvClasses[opencost.PVName(key)]
+			price := rc.Price
+			resourceKey := rc.Name
+			if classPrice, ok := rc.ProductPrices[class]; ok && class != "" {
+				price = classPrice
+				resourceKey = rc.Name + ":" + class
+			}
+			costs[resourceKey] += componentCost(policy, rc.Name, class, pv.ByteHours/bytesPerGB, price, pv.Cost)
 		}
 	}
 
-	// Scale up statefulsets
-	statefulsets, err := s.k8sClient.ListStatefulSets(ctx, namespace)
-	if err != nil {
-		return err
+	// Network egress
+	if rc := pricing.network; rc != nil && (alloc.NetworkTransferBytes > 0 || alloc.NetworkCost > 0) {
+		costs[rc.Name] += componentCost(policy, rc.Name, "", alloc.NetworkTransferBytes/bytesPerGB, rc.Price, alloc.NetworkCost)
 	}
 
-	for _, sts := range statefulsets.Items {
-		originalStr, ok := sts.Annotations["bison.io/original-replicas"]
-		if !ok {
-			continue
+	// Load balancers; without per-service hours only OpenCost's cost is available
+	if rc := pricing.loadBalancer; rc != nil && (alloc.LoadBalancerCost > 0 || len(alloc.LoadBalancers) > 0) {
+		var hours float64
+		for _, lb := range alloc.LoadBalancers {
+			hours += lb.Hours
 		}
-
-		original, err := strconv.ParseInt(originalStr, 10, 32)
-		if err != nil {
-			continue
+		price := rc.Price
+		if hours == 0 {
+			price = 0
 		}
+		costs[rc.Name] += componentCost(policy, rc.Name, "", hours, price, alloc.LoadBalancerCost)
+	}
 
-		// Restore original replicas
-		replicas := int32(original)
-		sts.Spec.Replicas = &replicas
-		delete(sts.Annotations, "bison.io/original-replicas")
+	return costs
+}
 
-		if err := s.k8sClient.UpdateStatefulSet(ctx, namespace, &sts); err != nil {
-			logger.Error("Failed to scale up statefulset", "namespace", namespace, "name", sts.Name, "error", err)
-		}
-	}
+// bytesPerGB converts byte quantities to the GB used by storage and network prices
+const bytesPerGB = 1024 * 1024 * 1024
 
-	return nil
+// componentCost prices one resource. A team price override replaces the list price;
+// otherwise the list price (or OpenCost's cost when unpriced) is charged less the team discount.
+func componentCost(policy *TeamPricingPolicy, resource, product string, quantity, price, fallback float64) float64 {
+	cost := fallback
+	if price > 0 {
+		cost = quantity * price
+	}
+	if policy == nil {
+		return cost
+	}
+	if override, ok := policy.PriceOverride(resource, product); ok {
+		return quantity * override
+	}
+	return cost * (1 - policy.Discount(resource, product))
 }
 
-// Unused import fix
-var _ = appsv1.Deployment{}
+// sumCosts returns the total of a cost breakdown
+func sumCosts(costs map[string]float64) float64 {
+	var total float64
+	for _, cost := range costs {
+		total += cost
+	}
+	return total
+}
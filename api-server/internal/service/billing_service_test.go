@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/bison/api-server/internal/opencost"
+)
+
+// stubUsageSource serves fixed namespace allocations
+type stubUsageSource struct {
+	namespaces []opencost.Allocation
+}
+
+func (s *stubUsageSource) Name() string    { return "stub" }
+func (s *stubUsageSource) IsEnabled() bool { return true }
+
+func (s *stubUsageSource) GetPodAllocations(ctx context.Context, window, namespace string) ([]opencost.Allocation, error) {
+	return nil, nil
+}
+
+func (s *stubUsageSource) GetPodAllocationSteps(ctx context.Context, window, namespace, step string) ([]opencost.AllocationStep, error) {
+	return nil, nil
+}
+
+func (s *stubUsageSource) GetNamespaceAllocations(ctx context.Context, window string, includeIdle bool) ([]opencost.Allocation, error) {
+	return s.namespaces, nil
+}
+
+func (s *stubUsageSource) GetCostTrend(ctx context.Context, window string) ([]opencost.CostTrendPoint, error) {
+	return nil, nil
+}
+
+func TestOverheadSharesLeaveOutReservedTeams(t *testing.T) {
+	usage := &stubUsageSource{namespaces: []opencost.Allocation{
+		{Name: opencost.IdleAllocation, CPUCoreHours: 40},
+		{Name: "kube-system", CPUCoreHours: 60},
+		{Name: "ns-a", CPUCoreHours: 30},
+		{Name: "ns-b", CPUCoreHours: 10},
+		{Name: "ns-reserved", CPUCoreHours: 200},
+	}}
+	svc := &BillingService{usageSource: usage}
+	pricing := &resourcePricing{cpuPrice: 1}
+	nsToTeam := map[string]string{"ns-a": "team-a", "ns-b": "team-b", "ns-reserved": "team-reserved"}
+	reserved := map[string]bool{"team-reserved": true}
+
+	tests := []struct {
+		name     string
+		overhead *OverheadDistribution
+		want     map[string]float64
+	}{
+		{
+			name: "proportional",
+			overhead: &OverheadDistribution{
+				Enabled:          true,
+				Method:           OverheadProportional,
+				DistributeIdle:   true,
+				SharedNamespaces: []string{"kube-system"},
+			},
+			want: map[string]float64{"team-a": 75, "team-b": 25},
+		},
+		{
+			name: "weighted",
+			overhead: &OverheadDistribution{
+				Enabled:          true,
+				Method:           OverheadWeighted,
+				DistributeIdle:   true,
+				SharedNamespaces: []string{"kube-system"},
+				Weights:          map[string]float64{"team-a": 1, "team-b": 3, "team-reserved": 4},
+			},
+			want: map[string]float64{"team-a": 25, "team-b": 75},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &BillingConfig{Enabled: true, Overhead: tt.overhead}
+			shares, err := svc.overheadShares(context.Background(), config, pricing, "1d", nsToTeam, reserved)
+			if err != nil {
+				t.Fatalf("overheadShares failed: %v", err)
+			}
+			if len(shares) != len(tt.want) {
+				t.Fatalf("got shares %v, want %v", shares, tt.want)
+			}
+			for teamName, want := range tt.want {
+				if math.Abs(shares[teamName]-want) > ledgerTolerance {
+					t.Fatalf("team %s share is %.2f, want %.2f", teamName, shares[teamName], want)
+				}
+			}
+		})
+	}
+
+	// The configured weights are left untouched
+	weighted := tests[1].overhead
+	if weighted.Weights["team-reserved"] != 4 {
+		t.Fatalf("reserved team weight was changed to %.2f", weighted.Weights["team-reserved"])
+	}
+}
+
+func TestTimeOfUseBandAt(t *testing.T) {
+	bands := []PriceBand{
+		{Name: "off-peak", Days: BandDaysAll, StartHour: 22, EndHour: 6, Multiplier: 0.5},
+		{Name: "weekend", Days: BandDaysWeekend, StartHour: 0, EndHour: 24, Multiplier: 0.7},
+		{Name: "peak", Days: BandDaysWeekday, StartHour: 9, EndHour: 18, Multiplier: 1.5},
+	}
+	utc := func(value string) time.Time {
+		at, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatalf("invalid time %q: %v", value, err)
+		}
+		return at
+	}
+
+	tests := []struct {
+		name     string
+		timezone string
+		at       string
+		band     string
+	}{
+		{"weekday peak", "Asia/Shanghai", "2026-10-05T02:00:00Z", "peak"},
+		{"weekday outside bands", "Asia/Shanghai", "2026-10-05T11:00:00Z", StandardBand},
+		{"band start is inclusive", "Asia/Shanghai", "2026-10-05T14:00:00Z", "off-peak"},
+		{"wrapped band before midnight", "Asia/Shanghai", "2026-10-05T15:30:00Z", "off-peak"},
+		{"wrapped band after midnight", "Asia/Shanghai", "2026-10-05T21:59:00Z", "off-peak"},
+		{"wrapped band end is exclusive", "Asia/Shanghai", "2026-10-05T22:00:00Z", StandardBand},
+		{"weekend", "Asia/Shanghai", "2026-10-10T04:00:00Z", "weekend"},
+		{"earlier band wins on weekends", "Asia/Shanghai", "2026-10-10T15:00:00Z", "off-peak"},
+		{"holiday is a weekend day", "Asia/Shanghai", "2026-10-01T04:00:00Z", "weekend"},
+		{"holiday starts at local midnight", "Asia/Shanghai", "2026-09-30T23:00:00Z", "weekend"},
+		{"holiday ends at local midnight", "Asia/Shanghai", "2026-10-01T23:30:00Z", StandardBand},
+		{"local day is the weekend before UTC", "Asia/Shanghai", "2026-10-09T23:00:00Z", "weekend"},
+		{"empty timezone is UTC", "", "2026-10-05T10:00:00Z", "peak"},
+		{"standard time", "Europe/Berlin", "2026-03-27T07:30:00Z", StandardBand},
+		{"daylight saving time", "Europe/Berlin", "2026-03-30T07:30:00Z", "peak"},
+	}
+
+	multipliers := make(map[string]float64)
+	for _, band := range bands {
+		multipliers[band.Name] = band.Multiplier
+	}
+	multipliers[StandardBand] = 1
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pricing := &TimeOfUsePricing{Enabled: true, Timezone: tt.timezone, Holidays: []string{"2026-10-01"}, Bands: bands}
+			band, multiplier := pricing.BandAt(utc(tt.at))
+			if band != tt.band || multiplier != multipliers[tt.band] {
+				t.Fatalf("got band %s x%.2f at %s, want %s x%.2f", band, multiplier, tt.at, tt.band, multipliers[tt.band])
+			}
+		})
+	}
+}
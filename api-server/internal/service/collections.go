@@ -0,0 +1,26 @@
+package service
+
+// StoreCollections lists every store collection owned by the services, used
+// when copying state between storage backends
+var StoreCollections = []string{
+	BalancesConfigMap,
+	RechargeHistoryConfigMap,
+	AutoRechargeConfigMap,
+	CreditPoliciesConfigMap,
+	CreditGrantsConfigMap,
+	BillingConfigMap,
+	BillingStateConfigMap,
+	InvoicesConfigMap,
+	AuditLogsConfigMap,
+	AlertConfigConfigMap,
+	AlertHistoryConfigMap,
+	SuspensionNoticesConfigMap,
+	usersConfigMapName,
+	OnboardingJobsConfigMap,
+	InitScriptsConfigMap,
+	ControlPlaneConfigConfigMap,
+	TeamPricingConfigMap,
+	SuspensionsConfigMap,
+	ProjectBudgetsConfigMap,
+	UserLimitsConfigMap,
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/bison/api-server/internal/opencost"
+	"github.com/bison/api-server/pkg/logger"
+)
+
+// Commitment is the charge of a committed-billed team over a window: its quota at
+// reservation prices, plus usage above the quota at on-demand prices. Usage is compared
+// with the quota over the window as a whole.
+type Commitment struct {
+	CommittedCost float64              `json:"committedCost"`
+	BurstCost     float64              `json:"burstCost"`
+	Resources     []*CommittedResource `json:"resources"`
+	ResourceCosts map[string]float64   `json:"resourceCosts"` // Burst cost by resource, keyed like usage costs
+}
+
+// CommittedResource is one quota resource with a reservation price
+type CommittedResource struct {
+	Resource      string  `json:"resource"`
+	Committed     float64 `json:"committed"` // Quota × hours, in priced units (cores, GB, cards)
+	Used          float64 `json:"used"`
+	Burst         float64 `json:"burst"` // Usage above the committed amount
+	CommittedCost float64 `json:"committedCost"`
+	BurstCost     float64 `json:"burstCost"`
+}
+
+// BurstShare returns the part of a resource's usage cost charged at on-demand prices. Keys
+// may carry a ":<model>" suffix. Resources without a commitment are all on demand.
+func (c *Commitment) BurstShare(key string) float64 {
+	name, _, _ := strings.Cut(key, ":")
+	for _, r := range c.Resources {
+		if r.Resource != name {
+			continue
+		}
+		if r.Used <= 0 {
+			return 0
+		}
+		return r.Burst / r.Used
+	}
+	return 1
+}
+
+// priceCommitment splits a committed-billed team's allocations over a window of the given
+// length into committed and burst charges. Quota resources without a reservation price
+// are not committed.
+func (s *BillingService) priceCommitment(config *BillingConfig, pricing *resourcePricing, policy *TeamPricingPolicy, team *Team, hours float64, allocations []bandedAllocation) *Commitment {
+	commitment := &Commitment{
+		Resources:     make([]*CommittedResource, 0),
+		ResourceCosts: make(map[string]float64),
+	}
+
+	committed := make(map[string]*CommittedResource)
+	for name, value := range team.Quota {
+		price, ok := config.ReservationPricing[name]
+		if !ok || price.Price <= 0 {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			logger.Warn("Skipping unparsable quota for committed billing", "team", team.Name, "resource", name, "value", value)
+			continue
+		}
+
+		amount := quantity.AsApproximateFloat64()
+		if name == "memory" {
+			amount /= bytesPerGB
+		}
+		r := &CommittedResource{
+			Resource:      name,
+			Committed:     amount * hours,
+			CommittedCost: amount * hours * price.Price,
+		}
+		committed[name] = r
+		commitment.Resources = append(commitment.Resources, r)
+		commitment.CommittedCost += r.CommittedCost
+	}
+	sort.Slice(commitment.Resources, func(i, j int) bool {
+		return commitment.Resources[i].Resource < commitment.Resources[j].Resource
+	})
+
+	usageCosts := make(map[string]float64)
+	for _, alloc := range allocations {
+		for key, cost := range s.calculateCost(config, pricing, policy, &alloc.Allocation) {
+			usageCosts[key] += cost * alloc.Multiplier
+		}
+		for name, used := range allocationQuantities(pricing, &alloc.Allocation) {
+			if r, ok := committed[name]; ok {
+				r.Used += used
+			}
+		}
+	}
+	for _, r := range committed {
+		r.Burst = math.Max(0, r.Used-r.Committed)
+	}
+
+	for key, cost := range usageCosts {
+		burst := cost * commitment.BurstShare(key)
+		commitment.ResourceCosts[key] += burst
+		commitment.BurstCost += burst
+		name, _, _ := strings.Cut(key, ":")
+		if r, ok := committed[name]; ok {
+			r.BurstCost += burst
+		}
+	}
+
+	return commitment
+}
+
+// teamCommitment prices a committed-billed team's usage in [start, end)
+func (s *BillingService) teamCommitment(ctx context.Context, config *BillingConfig, team *Team, start, end time.Time) (*Commitment, error) {
+	window := opencost.FormatWindow(start, end)
+
+	projects, err := s.projectSvc.ListByTeam(ctx, team.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	var allocations []bandedAllocation
+	for _, project := range projects {
+		projectAllocations, err := s.getPodAllocations(ctx, config, window, project.Name)
+		if err != nil {
+			return nil, err
+		}
+		allocations = append(allocations, projectAllocations...)
+	}
+
+	policy := s.activePricingPolicy(ctx, team.Name, start)
+	return s.priceCommitment(config, s.loadPricing(ctx), policy, team, end.Sub(start).Hours(), allocations), nil
+}
+
+// applyCommitment replaces the usage charges of a bill with the committed and burst split.
+// Band costs and the discount are scaled to the burst part.
+func applyCommitment(bill *Bill, commitment *Commitment) {
+	var share float64
+	if bill.TotalCost > 0 {
+		share = commitment.BurstCost / bill.TotalCost
+	}
+	for band := range bill.BandCosts {
+		bill.BandCosts[band] *= share
+	}
+	bill.Discount *= share
+
+	bill.ResourceCosts = commitment.ResourceCosts
+	bill.CommittedCost = commitment.CommittedCost
+	bill.BurstCost = commitment.BurstCost
+	bill.Commitments = commitment.Resources
+	bill.TotalCost = commitment.CommittedCost + commitment.BurstCost
+	bill.UsageDetails.TotalCost = bill.TotalCost
+}
+
+// allocationQuantities returns the resource-hours of an allocation by resource name, in
+// priced units. OpenCost accelerator hours are attributed to the node's accelerator.
+func allocationQuantities(pricing *resourcePricing, alloc *opencost.Allocation) map[string]float64 {
+	quantities := map[string]float64{
+		"cpu":    alloc.CPUCoreHours,
+		"memory": alloc.RAMGBHours,
+	}
+	if len(alloc.ResourceHours) > 0 {
+		for name, hours := range alloc.ResourceHours {
+			quantities[name] += hours
+		}
+	} else if alloc.GPUHours > 0 {
+		if name, _, _ := pricing.acceleratorPrice(alloc.Properties.Node); name != "" {
+			quantities[name] += alloc.GPUHours
+		}
+	}
+	return quantities
+}
@@ -10,16 +10,17 @@ import (
 )
 
 const (
-	ExportVersion    = "1.0"
-	RedactedValue    = "***REDACTED***"
-	SectionBilling   = "billing"
-	SectionAlerts    = "alerts"
-	SectionResources = "resources"
-	SectionCP        = "controlPlane"
-	SectionScripts   = "initScripts"
+	ExportVersion      = "1.0"
+	RedactedValue      = "***REDACTED***"
+	SectionBilling     = "billing"
+	SectionAlerts      = "alerts"
+	SectionResources   = "resources"
+	SectionCP          = "controlPlane"
+	SectionScripts     = "initScripts"
+	SectionTeamPricing = "teamPricing"
 )
 
-var AllSections = []string{SectionBilling, SectionAlerts, SectionResources, SectionCP, SectionScripts}
+var AllSections = []string{SectionBilling, SectionAlerts, SectionResources, SectionCP, SectionScripts, SectionTeamPricing}
 
 // ExportConfig represents the full export file structure
 type ExportConfig struct {
@@ -85,6 +86,7 @@ type ConfigTransferService struct {
 	alertSvc          *AlertService
 	resourceConfigSvc *ResourceConfigService
 	initScriptSvc     *InitScriptService
+	teamPricingSvc    *TeamPricingService
 }
 
 // NewConfigTransferService creates a new ConfigTransferService
@@ -93,12 +95,14 @@ func NewConfigTransferService(
 	alertSvc *AlertService,
 	resourceConfigSvc *ResourceConfigService,
 	initScriptSvc *InitScriptService,
+	teamPricingSvc *TeamPricingService,
 ) *ConfigTransferService {
 	return &ConfigTransferService{
 		billingSvc:        billingSvc,
 		alertSvc:          alertSvc,
 		resourceConfigSvc: resourceConfigSvc,
 		initScriptSvc:     initScriptSvc,
+		teamPricingSvc:    teamPricingSvc,
 	}
 }
 
@@ -174,6 +178,15 @@ func (s *ConfigTransferService) Export(ctx context.Context, sections []string, i
 		result.Sections[SectionScripts] = data
 	}
 
+	if sectionSet[SectionTeamPricing] {
+		policies, err := s.teamPricingSvc.List(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export team pricing: %w", err)
+		}
+		data, _ := json.Marshal(policies)
+		result.Sections[SectionTeamPricing] = data
+	}
+
 	return result, nil
 }
 
@@ -252,6 +265,12 @@ func (s *ConfigTransferService) Preview(ctx context.Context, config *ExportConfi
 			if !preview.Valid {
 				result.Valid = false
 			}
+		case SectionTeamPricing:
+			preview := s.previewTeamPricing(ctx, raw)
+			result.Sections[section] = preview
+			if !preview.Valid {
+				result.Valid = false
+			}
 		default:
 			result.Warnings = append(result.Warnings, fmt.Sprintf("未知的配置模块: %s (将被忽略)", section))
 		}
@@ -533,6 +552,66 @@ func (s *ConfigTransferService) previewInitScripts(ctx context.Context, raw json
 	return preview
 }
 
+func (s *ConfigTransferService) previewTeamPricing(ctx context.Context, raw json.RawMessage) *SectionPreview {
+	preview := &SectionPreview{Present: true, Valid: true}
+
+	var imported []*TeamPricingPolicy
+	if err := json.Unmarshal(raw, &imported); err != nil {
+		preview.Valid = false
+		preview.Errors = append(preview.Errors, "团队定价配置格式无效: "+err.Error())
+		return preview
+	}
+
+	for _, p := range imported {
+		if err := p.Validate(); err != nil {
+			preview.Errors = append(preview.Errors, fmt.Sprintf("团队 '%s' 的定价策略无效: %s", p.Team, err.Error()))
+			preview.Valid = false
+		}
+	}
+
+	current, err := s.teamPricingSvc.List(ctx)
+	if err != nil {
+		preview.Warnings = append(preview.Warnings, "无法获取当前团队定价进行对比")
+		return preview
+	}
+
+	currentMap := make(map[string][]byte)
+	for _, p := range current {
+		p.UpdatedAt, p.UpdatedBy = time.Time{}, ""
+		currentMap[p.Team], _ = json.Marshal(p)
+	}
+	importedMap := make(map[string]bool)
+
+	summary := &ResourceSummary{}
+	for _, p := range imported {
+		importedMap[p.Team] = true
+		cur, exists := currentMap[p.Team]
+		if !exists {
+			summary.Added = append(summary.Added, p.Team)
+			continue
+		}
+		cmp := *p
+		cmp.UpdatedAt, cmp.UpdatedBy = time.Time{}, ""
+		if data, _ := json.Marshal(&cmp); string(data) != string(cur) {
+			summary.Modified = append(summary.Modified, p.Team)
+		} else {
+			summary.Unchanged = append(summary.Unchanged, p.Team)
+		}
+	}
+	for _, p := range current {
+		if !importedMap[p.Team] {
+			summary.Removed = append(summary.Removed, p.Team)
+		}
+	}
+
+	if len(summary.Removed) > 0 {
+		preview.Warnings = append(preview.Warnings, fmt.Sprintf("以下团队的定价策略将被移除: %v", summary.Removed))
+	}
+
+	preview.Summary = summary
+	return preview
+}
+
 // Apply applies the imported configuration
 func (s *ConfigTransferService) Apply(ctx context.Context, req *ImportRequest) (*ImportResult, error) {
 	logger.Info("Applying imported configuration", "sections", req.Sections)
@@ -569,6 +648,8 @@ func (s *ConfigTransferService) Apply(ctx context.Context, req *ImportRequest) (
 			err = s.applyControlPlane(ctx, raw, req.PreserveSensitive)
 		case SectionScripts:
 			err = s.applyInitScripts(ctx, raw)
+		case SectionTeamPricing:
+			err = s.applyTeamPricing(ctx, raw)
 		}
 
 		if err != nil {
@@ -662,3 +743,11 @@ func (s *ConfigTransferService) applyInitScripts(ctx context.Context, raw json.R
 	}
 	return s.initScriptSvc.SaveAllScriptGroups(ctx, groups)
 }
+
+func (s *ConfigTransferService) applyTeamPricing(ctx context.Context, raw json.RawMessage) error {
+	var policies []*TeamPricingPolicy
+	if err := json.Unmarshal(raw, &policies); err != nil {
+		return fmt.Errorf("解析团队定价配置失败: %w", err)
+	}
+	return s.teamPricingSvc.ReplaceAll(ctx, policies)
+}
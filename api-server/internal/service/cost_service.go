@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"strings"
 
 	"github.com/bison/api-server/internal/k8s"
 	"github.com/bison/api-server/internal/opencost"
@@ -19,6 +20,34 @@ type UsageData struct {
 	RAMCost      float64 `json:"ramCost"`
 	GPUCost      float64 `json:"gpuCost"`
 	Minutes      float64 `json:"minutes"`
+
+	PVGBHours        float64 `json:"pvGBHours,omitempty"`
+	PVCost           float64 `json:"pvCost,omitempty"`
+	NetworkGB        float64 `json:"networkGB,omitempty"` // Egress
+	NetworkCost      float64 `json:"networkCost,omitempty"`
+	LoadBalancerCost float64 `json:"loadBalancerCost,omitempty"`
+	OverheadCost     float64 `json:"overheadCost,omitempty"` // Share of idle and shared-namespace cost
+}
+
+// addResourceCost adds a cost from a bill breakdown key to the matching usage cost field
+func (u *UsageData) addResourceCost(resource string, cost float64) {
+	name, _, _ := strings.Cut(resource, ":")
+	switch name {
+	case "cpu":
+		u.CPUCost += cost
+	case "memory":
+		u.RAMCost += cost
+	case ResourcePersistentStorage:
+		u.PVCost += cost
+	case ResourceNetworkEgress:
+		u.NetworkCost += cost
+	case ResourceLoadBalancer:
+		u.LoadBalancerCost += cost
+	case "other":
+	default:
+		// Accelerators
+		u.GPUCost += cost
+	}
 }
 
 // UsageReport represents a usage report
@@ -29,27 +58,28 @@ type UsageReport struct {
 	TotalCost   float64      `json:"totalCost"`
 }
 
-// CostService handles cost and usage statistics using OpenCost
+// CostService handles cost and usage statistics from the configured usage source
 type CostService struct {
-	opencostClient *opencost.Client
+	usageSource    UsageSource
+	opencostClient *opencost.Client // Only for user-level usage, which needs OpenCost labels
 	k8sClient      *k8s.Client
+	billingSvc     *BillingService
 	enabled        bool
 }
 
-// NewCostService creates a new CostService
-func NewCostService(opencostURL string, k8sClient *k8s.Client) *CostService {
-	if opencostURL == "" {
-		logger.Warn("OpenCost URL not configured, cost service disabled")
-		return &CostService{enabled: false, k8sClient: k8sClient}
+// NewCostService creates a new CostService. opencostClient may be nil.
+func NewCostService(usageSource UsageSource, opencostClient *opencost.Client, k8sClient *k8s.Client, billingSvc *BillingService) *CostService {
+	enabled := usageSource != nil && usageSource.IsEnabled()
+	if !enabled {
+		logger.Warn("No usage source available, cost service disabled")
 	}
 
-	client := opencost.NewClient(opencostURL)
-	logger.Info("OpenCost client initialized", "url", opencostURL)
-
 	return &CostService{
-		opencostClient: client,
+		usageSource:    usageSource,
+		opencostClient: opencostClient,
 		k8sClient:      k8sClient,
-		enabled:        true,
+		billingSvc:     billingSvc,
+		enabled:        enabled,
 	}
 }
 
@@ -79,8 +109,14 @@ func (s *CostService) GetTeamUsage(ctx context.Context, window string) (*UsageRe
 
 	logger.Debug("Getting team usage", "window", window)
 
-	// Get namespace-level usage from OpenCost
-	summaries, err := s.opencostClient.GetProjectUsage(ctx, window)
+	// Idle and shared-namespace cost may be redistributed to teams
+	var overhead *OverheadDistribution
+	if config, err := s.billingSvc.GetConfig(ctx); err == nil && overheadEnabled(config) {
+		overhead = config.Overhead
+	}
+
+	// Get namespace-level usage
+	summaries, err := s.namespaceSummaries(ctx, window, overhead != nil)
 	if err != nil {
 		logger.Error("Failed to get namespace usage", "error", err)
 		return nil, err
@@ -111,8 +147,13 @@ func (s *CostService) GetTeamUsage(ctx context.Context, window string) (*UsageRe
 
 	// Aggregate by team
 	teamData := make(map[string]*UsageData)
+	var overheadPool float64
 	for _, summary := range summaries {
-		if summary.Name == "__idle__" || summary.Name == "__unmounted__" {
+		if overhead != nil && (overhead.DistributeIdle && opencost.IsIdle(summary.Name) || overhead.IsShared(summary.Name)) {
+			overheadPool += summary.TotalCost
+			continue
+		}
+		if opencost.IsIdle(summary.Name) || summary.Name == opencost.UnmountedAllocation {
 			continue
 		}
 
@@ -137,6 +178,22 @@ func (s *CostService) GetTeamUsage(ctx context.Context, window string) (*UsageRe
 		teamData[teamName].Minutes += summary.Minutes
 	}
 
+	if overhead != nil {
+		teamCosts := make(map[string]float64)
+		for teamName, data := range teamData {
+			if teamName != "未分配" {
+				teamCosts[teamName] = data.TotalCost
+			}
+		}
+		for teamName, share := range overhead.Split(overheadPool, teamCosts) {
+			if _, exists := teamData[teamName]; !exists {
+				teamData[teamName] = &UsageData{Name: teamName}
+			}
+			teamData[teamName].OverheadCost += share
+			teamData[teamName].TotalCost += share
+		}
+	}
+
 	// Convert to report
 	report := &UsageReport{
 		Window:      window,
@@ -167,7 +224,7 @@ func (s *CostService) GetProjectUsage(ctx context.Context, window string) (*Usag
 
 	logger.Debug("Getting project usage", "window", window)
 
-	summaries, err := s.opencostClient.GetProjectUsage(ctx, window)
+	summaries, err := s.namespaceSummaries(ctx, window, false)
 	if err != nil {
 		logger.Error("Failed to get project usage", "error", err)
 		return nil, err
@@ -178,7 +235,7 @@ func (s *CostService) GetProjectUsage(ctx context.Context, window string) (*Usag
 
 // GetUserUsage returns usage statistics for all users
 func (s *CostService) GetUserUsage(ctx context.Context, window string) (*UsageReport, error) {
-	if !s.enabled {
+	if !s.enabled || s.opencostClient == nil {
 		return &UsageReport{
 			Window:      window,
 			AggregateBy: "user",
@@ -241,7 +298,16 @@ func (s *CostService) GetTotalCost(ctx context.Context, window string) (float64,
 		return 0, nil
 	}
 
-	return s.opencostClient.GetTotalCost(ctx, window)
+	summaries, err := s.namespaceSummaries(ctx, window, false)
+	if err != nil {
+		return 0, err
+	}
+
+	var total float64
+	for _, summary := range summaries {
+		total += summary.TotalCost
+	}
+	return total, nil
 }
 
 // CostTrendPoint represents a daily cost point
@@ -256,7 +322,7 @@ func (s *CostService) GetCostTrend(ctx context.Context, window string) ([]CostTr
 		return []CostTrendPoint{}, nil
 	}
 
-	trend, err := s.opencostClient.GetCostTrend(ctx, window)
+	trend, err := s.usageSource.GetCostTrend(ctx, window)
 	if err != nil {
 		return nil, err
 	}
@@ -272,6 +338,20 @@ func (s *CostService) GetCostTrend(ctx context.Context, window string) ([]CostTr
 	return result, nil
 }
 
+// namespaceSummaries returns usage per namespace from the usage source
+func (s *CostService) namespaceSummaries(ctx context.Context, window string, includeIdle bool) ([]opencost.UsageSummary, error) {
+	allocations, err := s.usageSource.GetNamespaceAllocations(ctx, window, includeIdle)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]opencost.UsageSummary, 0, len(allocations))
+	for _, alloc := range allocations {
+		summaries = append(summaries, alloc.ToUsageSummary())
+	}
+	return summaries, nil
+}
+
 // summariesToReport converts OpenCost summaries to a UsageReport
 func (s *CostService) summariesToReport(summaries []opencost.UsageSummary, window, aggregateBy string) *UsageReport {
 	report := &UsageReport{
@@ -282,7 +362,7 @@ func (s *CostService) summariesToReport(summaries []opencost.UsageSummary, windo
 
 	for _, summary := range summaries {
 		// Skip idle/system entries
-		if summary.Name == "__idle__" || summary.Name == "__unmounted__" {
+		if opencost.IsIdle(summary.Name) || summary.Name == opencost.UnmountedAllocation {
 			continue
 		}
 
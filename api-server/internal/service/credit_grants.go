@@ -0,0 +1,343 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/bison/api-server/pkg/logger"
+)
+
+const (
+	CreditGrantsConfigMap = "bison-credit-grants"
+
+	// GrantDrawRetention is how long a grant remembers the charges drawn from it. Charges
+	// whose draw-down failed are retried within this window.
+	GrantDrawRetention = 7 * 24 * time.Hour
+)
+
+// CreditGrant is a block of promotional or onboarding credit that expires. Grants are
+// part of the team balance; deductions draw on them oldest first before paid funds, and
+// whatever is left at expiry is removed from the balance.
+type CreditGrant struct {
+	ID        string     `json:"id"`
+	Amount    float64    `json:"amount"`    // Amount granted
+	Remaining float64    `json:"remaining"` // Amount not yet consumed or expired
+	Source    string     `json:"source"`    // Where the credit came from, e.g. "onboarding" or "promo-2026q4"
+	Reason    string     `json:"reason,omitempty"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	ExpiredAt *time.Time `json:"expiredAt,omitempty"` // When the unused remainder was removed
+	CreatedAt time.Time  `json:"createdAt"`
+	CreatedBy string     `json:"createdBy"`
+
+	// Draws holds the ledger entries of recent charges drawn from the grant, with their
+	// times, so a charge is never drawn twice
+	Draws map[string]time.Time `json:"draws,omitempty"`
+}
+
+// Active reports whether the grant can still be consumed at t
+func (g *CreditGrant) Active(t time.Time) bool {
+	return g.ExpiredAt == nil && g.Remaining > 0 && t.Before(g.ExpiresAt)
+}
+
+// covers reports whether a charge recorded at t draws on the grant
+func (g *CreditGrant) covers(t time.Time) bool {
+	return !g.CreatedAt.After(t) && g.Active(t)
+}
+
+// Validate checks a new grant
+func (g *CreditGrant) Validate() error {
+	if g.Amount <= 0 {
+		return fmt.Errorf("grant amount must be positive")
+	}
+	if g.Source == "" {
+		return fmt.Errorf("grant source is required")
+	}
+	if !g.ExpiresAt.After(time.Now()) {
+		return fmt.Errorf("grant expiry must be in the future")
+	}
+	return nil
+}
+
+// GrantCredit adds an expiring credit grant to a team. The grant is recorded in the ledger
+// as a "grant" entry so the team balance includes it.
+func (s *BalanceService) GrantCredit(ctx context.Context, teamName string, grant *CreditGrant, operator string) (*CreditGrant, error) {
+	logger.Info("Granting credit", "team", teamName, "amount", grant.Amount, "source", grant.Source, "expiresAt", grant.ExpiresAt)
+
+	if err := grant.Validate(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	grant.ID = fmt.Sprintf("%d", now.UnixNano())
+	grant.Remaining = grant.Amount
+	grant.ExpiredAt = nil
+	grant.CreatedAt = now
+	grant.CreatedBy = operator
+
+	reason := fmt.Sprintf("Credit grant from %s, expires %s", grant.Source, grant.ExpiresAt.Format("2006-01-02"))
+	if grant.Reason != "" {
+		reason += ": " + grant.Reason
+	}
+	if err := s.applyEntry(ctx, teamName, &RechargeRecord{
+		ID:        grant.ID,
+		Timestamp: now,
+		Type:      RecordTypeGrant,
+		Amount:    grant.Amount,
+		Operator:  operator,
+		Reason:    reason,
+		RefID:     grant.ID,
+	}); err != nil {
+		return nil, err
+	}
+
+	err := s.modifyGrants(ctx, teamName, func(grants []*CreditGrant) ([]*CreditGrant, error) {
+		return append(grants, grant), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ledger entry %s recorded but grant could not be saved: %w", grant.ID, err)
+	}
+
+	return grant, nil
+}
+
+// GetCreditGrants returns all grants of a team, oldest first
+func (s *BalanceService) GetCreditGrants(ctx context.Context, teamName string) ([]*CreditGrant, error) {
+	entries, err := s.store.Load(ctx, CreditGrantsConfigMap)
+	if err != nil {
+		return nil, err
+	}
+	return parseGrants(entries, teamName)
+}
+
+// GrantedAmount returns the part of a team's balance that is unexpired granted credit
+func (s *BalanceService) GrantedAmount(ctx context.Context, teamName string) (float64, error) {
+	grants, err := s.GetCreditGrants(ctx, teamName)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	var granted float64
+	for _, grant := range grants {
+		if grant.Active(now) {
+			granted += grant.Remaining
+		}
+	}
+	return granted, nil
+}
+
+// SplitBalance fills in the paid and granted parts of a balance
+func (s *BalanceService) SplitBalance(ctx context.Context, balance *Balance) error {
+	granted, err := s.GrantedAmount(ctx, balance.TeamName)
+	if err != nil {
+		return err
+	}
+	balance.GrantedAmount = granted
+	balance.PaidAmount = balance.Amount - granted
+	return nil
+}
+
+// ExpireGrants removes the unused remainder of every expired grant from its team's
+// balance, recording a "grant_expiry" ledger entry for each. Charges whose draw-down
+// failed are drawn first, so they still count against grants that expired since.
+func (s *BalanceService) ExpireGrants(ctx context.Context) error {
+	entries, err := s.store.Load(ctx, CreditGrantsConfigMap)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for teamName := range entries {
+		if err := s.drawPendingGrants(ctx, teamName); err != nil {
+			logger.Error("Failed to draw pending charges from credit grants", "team", teamName, "error", err)
+			continue
+		}
+
+		grants, err := s.GetCreditGrants(ctx, teamName)
+		if err != nil {
+			logger.Warn("Skipping unreadable credit grants", "team", teamName, "error", err)
+			continue
+		}
+
+		for _, grant := range grants {
+			if grant.ExpiredAt != nil || now.Before(grant.ExpiresAt) {
+				continue
+			}
+			if err := s.expireGrant(ctx, teamName, grant.ID, now); err != nil {
+				logger.Error("Failed to expire credit grant", "team", teamName, "grant", grant.ID, "error", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// expireGrant marks a grant expired and then removes its remainder from the ledger. The
+// grant is marked first so a retry never removes the remainder twice.
+func (s *BalanceService) expireGrant(ctx context.Context, teamName, grantID string, now time.Time) error {
+	var expired *CreditGrant
+	err := s.modifyGrants(ctx, teamName, func(grants []*CreditGrant) ([]*CreditGrant, error) {
+		expired = nil
+		for _, grant := range grants {
+			if grant.ID == grantID && grant.ExpiredAt == nil {
+				copied := *grant
+				expired = &copied
+				grant.Remaining = 0
+				grant.ExpiredAt = &now
+			}
+		}
+		return grants, nil
+	})
+	if err != nil || expired == nil {
+		return err
+	}
+
+	logger.Info("Credit grant expired", "team", teamName, "grant", grantID, "remaining", expired.Remaining)
+	if expired.Remaining <= 0 {
+		return nil
+	}
+
+	return s.applyEntry(ctx, teamName, &RechargeRecord{
+		ID:        grantID + "-expiry",
+		Timestamp: now,
+		Type:      RecordTypeGrantExpiry,
+		Amount:    -expired.Remaining,
+		Operator:  "system",
+		Reason:    fmt.Sprintf("Unused credit from %s expired (%.2f of %.2f)", expired.Source, expired.Remaining, expired.Amount),
+		RefID:     grantID,
+	})
+}
+
+// consumeGrants draws a charge from the grants of a team that were active when it was
+// recorded, oldest first, and returns how much they covered. The rest of the charge comes
+// out of paid funds. The draw is recorded on the grants under the charge's ledger entry,
+// so drawing the same charge again does nothing.
+func (s *BalanceService) consumeGrants(ctx context.Context, teamName string, record *RechargeRecord) (float64, error) {
+	amount := -record.Amount
+	var consumed float64
+	err := s.modifyGrants(ctx, teamName, func(grants []*CreditGrant) ([]*CreditGrant, error) {
+		consumed = 0
+		if grantsDrew(grants, record.ID) {
+			return grants, nil
+		}
+
+		cutoff := time.Now().Add(-GrantDrawRetention)
+		for _, grant := range grants {
+			for id, at := range grant.Draws {
+				if at.Before(cutoff) {
+					delete(grant.Draws, id)
+				}
+			}
+
+			if consumed >= amount || !grant.covers(record.Timestamp) {
+				continue
+			}
+			take := math.Min(grant.Remaining, amount-consumed)
+			grant.Remaining -= take
+			consumed += take
+			if grant.Draws == nil {
+				grant.Draws = make(map[string]time.Time)
+			}
+			grant.Draws[record.ID] = record.Timestamp
+		}
+		return grants, nil
+	})
+	return consumed, err
+}
+
+// drawPendingGrants draws the team's recent charges that were recorded in the ledger but
+// not drawn from its grants, because the draw-down failed after the entry was written
+func (s *BalanceService) drawPendingGrants(ctx context.Context, teamName string) error {
+	grants, err := s.GetCreditGrants(ctx, teamName)
+	if err != nil {
+		return err
+	}
+	ledger, err := s.GetLedger(ctx, teamName)
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().Add(-GrantDrawRetention)
+	for _, record := range ledger {
+		if !isUsageCharge(record.Type) || record.Timestamp.Before(cutoff) || grantsDrew(grants, record.ID) {
+			continue
+		}
+		// A charge no grant was active for has nothing to draw
+		if !grantsCover(grants, record.Timestamp) {
+			continue
+		}
+
+		granted, err := s.consumeGrants(ctx, teamName, record)
+		if err != nil {
+			return err
+		}
+		if granted > 0 {
+			logger.Info("Pending charge drawn from credit grants", "team", teamName, "entry", record.ID, "granted", granted)
+		}
+	}
+	return nil
+}
+
+// grantsCover reports whether any of the grants can be drawn by a charge recorded at t
+func grantsCover(grants []*CreditGrant, t time.Time) bool {
+	for _, grant := range grants {
+		if grant.covers(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// grantsDrew reports whether a charge was already drawn from any of the grants
+func grantsDrew(grants []*CreditGrant, recordID string) bool {
+	for _, grant := range grants {
+		if _, ok := grant.Draws[recordID]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// modifyGrants applies mutate to a team's grants with conflict retry. mutate may be
+// called more than once and must only change the grants it is given.
+func (s *BalanceService) modifyGrants(ctx context.Context, teamName string, mutate func(grants []*CreditGrant) ([]*CreditGrant, error)) error {
+	return s.store.Modify(ctx, CreditGrantsConfigMap, func(entries map[string]string) error {
+		grants, err := parseGrants(entries, teamName)
+		if err != nil {
+			return err
+		}
+
+		if grants, err = mutate(grants); err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(grants)
+		if err != nil {
+			return fmt.Errorf("failed to marshal credit grants: %w", err)
+		}
+		entries[teamName] = string(data)
+		return nil
+	})
+}
+
+// parseGrants returns a team's grants sorted oldest first
+func parseGrants(entries map[string]string, teamName string) ([]*CreditGrant, error) {
+	data, ok := entries[teamName]
+	if !ok {
+		return []*CreditGrant{}, nil
+	}
+
+	var grants []*CreditGrant
+	if err := json.Unmarshal([]byte(data), &grants); err != nil {
+		return nil, fmt.Errorf("failed to parse credit grants: %w", err)
+	}
+
+	sort.SliceStable(grants, func(i, j int) bool {
+		return grants[i].CreatedAt.Before(grants[j].CreatedAt)
+	})
+	return grants, nil
+}
@@ -8,11 +8,7 @@ import (
 	"strings"
 	"time"
 
-	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-
-	"github.com/bison/api-server/internal/k8s"
+	"github.com/bison/api-server/internal/store"
 	"github.com/bison/api-server/pkg/logger"
 )
 
@@ -73,13 +69,13 @@ type ControlPlaneConfig struct {
 
 // InitScriptService handles initialization script operations
 type InitScriptService struct {
-	k8sClient *k8s.Client
+	store store.Store
 }
 
 // NewInitScriptService creates a new InitScriptService
-func NewInitScriptService(k8sClient *k8s.Client) *InitScriptService {
+func NewInitScriptService(st store.Store) *InitScriptService {
 	return &InitScriptService{
-		k8sClient: k8sClient,
+		store: st,
 	}
 }
 
@@ -122,149 +118,122 @@ func (s *InitScriptService) GetScriptGroup(ctx context.Context, id string) (*Scr
 func (s *InitScriptService) CreateScriptGroup(ctx context.Context, group *ScriptGroup) error {
 	logger.Info("Creating script group", "name", group.Name)
 
-	config, err := s.getInitScriptsConfig(ctx)
-	if err != nil {
-		return err
-	}
-
 	// Generate ID if not provided
 	if group.ID == "" {
 		group.ID = fmt.Sprintf("custom-%d", time.Now().UnixNano())
 	}
 
-	// Check for duplicate ID
-	for _, existing := range config.Groups {
-		if existing.ID == group.ID {
-			return fmt.Errorf("script group with ID %s already exists", group.ID)
-		}
-	}
+	// Custom scripts are not builtin
+	group.Builtin = false
+	order := group.Order
 
-	// Set order to last
-	if group.Order == 0 {
-		maxOrder := 0
-		for _, g := range config.Groups {
-			if g.Order > maxOrder {
-				maxOrder = g.Order
+	return s.modifyInitScriptsConfig(ctx, func(config *InitScriptsConfig) error {
+		// Check for duplicate ID
+		for _, existing := range config.Groups {
+			if existing.ID == group.ID {
+				return fmt.Errorf("script group with ID %s already exists", group.ID)
 			}
 		}
-		group.Order = maxOrder + 1
-	}
-
-	// Custom scripts are not builtin
-	group.Builtin = false
 
-	config.Groups = append(config.Groups, *group)
+		// Set order to last
+		group.Order = order
+		if group.Order == 0 {
+			maxOrder := 0
+			for _, g := range config.Groups {
+				if g.Order > maxOrder {
+					maxOrder = g.Order
+				}
+			}
+			group.Order = maxOrder + 1
+		}
 
-	return s.saveInitScriptsConfig(ctx, config)
+		config.Groups = append(config.Groups, *group)
+		return nil
+	})
 }
 
 // UpdateScriptGroup updates an existing script group
 func (s *InitScriptService) UpdateScriptGroup(ctx context.Context, id string, group *ScriptGroup) error {
 	logger.Info("Updating script group", "id", id)
 
-	config, err := s.getInitScriptsConfig(ctx)
-	if err != nil {
-		return err
-	}
-
-	found := false
-	for i, existing := range config.Groups {
-		if existing.ID == id {
-			// Preserve builtin status and ID
-			group.ID = id
-			group.Builtin = existing.Builtin
-			config.Groups[i] = *group
-			found = true
-			break
+	return s.modifyInitScriptsConfig(ctx, func(config *InitScriptsConfig) error {
+		for i, existing := range config.Groups {
+			if existing.ID == id {
+				// Preserve builtin status and ID
+				group.ID = id
+				group.Builtin = existing.Builtin
+				config.Groups[i] = *group
+				return nil
+			}
 		}
-	}
 
-	if !found {
 		return fmt.Errorf("script group not found: %s", id)
-	}
-
-	return s.saveInitScriptsConfig(ctx, config)
+	})
 }
 
 // DeleteScriptGroup deletes a script group (only custom scripts can be deleted)
 func (s *InitScriptService) DeleteScriptGroup(ctx context.Context, id string) error {
 	logger.Info("Deleting script group", "id", id)
 
-	config, err := s.getInitScriptsConfig(ctx)
-	if err != nil {
-		return err
-	}
-
-	newGroups := make([]ScriptGroup, 0, len(config.Groups))
-	deleted := false
-
-	for _, group := range config.Groups {
-		if group.ID == id {
-			if group.Builtin {
-				return fmt.Errorf("cannot delete builtin script group: %s", id)
+	return s.modifyInitScriptsConfig(ctx, func(config *InitScriptsConfig) error {
+		newGroups := make([]ScriptGroup, 0, len(config.Groups))
+		deleted := false
+
+		for _, group := range config.Groups {
+			if group.ID == id {
+				if group.Builtin {
+					return fmt.Errorf("cannot delete builtin script group: %s", id)
+				}
+				deleted = true
+				continue
 			}
-			deleted = true
-			continue
+			newGroups = append(newGroups, group)
 		}
-		newGroups = append(newGroups, group)
-	}
 
-	if !deleted {
-		return fmt.Errorf("script group not found: %s", id)
-	}
+		if !deleted {
+			return fmt.Errorf("script group not found: %s", id)
+		}
 
-	config.Groups = newGroups
-	return s.saveInitScriptsConfig(ctx, config)
+		config.Groups = newGroups
+		return nil
+	})
 }
 
 // ToggleScriptGroup enables or disables a script group
 func (s *InitScriptService) ToggleScriptGroup(ctx context.Context, id string, enabled bool) error {
 	logger.Info("Toggling script group", "id", id, "enabled", enabled)
 
-	config, err := s.getInitScriptsConfig(ctx)
-	if err != nil {
-		return err
-	}
-
-	found := false
-	for i, group := range config.Groups {
-		if group.ID == id {
-			config.Groups[i].Enabled = enabled
-			found = true
-			break
+	return s.modifyInitScriptsConfig(ctx, func(config *InitScriptsConfig) error {
+		for i, group := range config.Groups {
+			if group.ID == id {
+				config.Groups[i].Enabled = enabled
+				return nil
+			}
 		}
-	}
 
-	if !found {
 		return fmt.Errorf("script group not found: %s", id)
-	}
-
-	return s.saveInitScriptsConfig(ctx, config)
+	})
 }
 
 // ReorderScriptGroups updates the order of script groups
 func (s *InitScriptService) ReorderScriptGroups(ctx context.Context, ids []string) error {
 	logger.Info("Reordering script groups", "ids", ids)
 
-	config, err := s.getInitScriptsConfig(ctx)
-	if err != nil {
-		return err
-	}
-
-	// Create a map of current groups
-	groupMap := make(map[string]*ScriptGroup)
-	for i := range config.Groups {
-		groupMap[config.Groups[i].ID] = &config.Groups[i]
-	}
-
-	// Update orders based on the provided order
-	for i, id := range ids {
-		if group, ok := groupMap[id]; ok {
-			group.Order = i + 1
+	return s.modifyInitScriptsConfig(ctx, func(config *InitScriptsConfig) error {
+		// Create a map of current groups
+		groupMap := make(map[string]*ScriptGroup)
+		for i := range config.Groups {
+			groupMap[config.Groups[i].ID] = &config.Groups[i]
 		}
-	}
 
-	return s.saveInitScriptsConfig(ctx, config)
+		// Update orders based on the provided order
+		for i, id := range ids {
+			if group, ok := groupMap[id]; ok {
+				group.Order = i + 1
+			}
+		}
+		return nil
+	})
 }
 
 // GetMatchingScript returns the best matching script for a given platform
@@ -349,18 +318,12 @@ func (s *InitScriptService) GetScriptsForPhase(ctx context.Context, phase Script
 func (s *InitScriptService) GetControlPlaneConfig(ctx context.Context) (*ControlPlaneConfig, error) {
 	logger.Debug("Getting control plane config")
 
-	cm, err := s.k8sClient.GetConfigMap(ctx, BisonNamespace, ControlPlaneConfigConfigMap)
+	entries, err := s.store.Load(ctx, ControlPlaneConfigConfigMap)
 	if err != nil {
-		if errors.IsNotFound(err) {
-			return &ControlPlaneConfig{
-				SSHPort: 22,
-				SSHUser: "root",
-			}, nil
-		}
 		return nil, fmt.Errorf("failed to get control plane config: %w", err)
 	}
 
-	data, ok := cm.Data["config"]
+	data, ok := entries["config"]
 	if !ok {
 		return &ControlPlaneConfig{
 			SSHPort: 22,
@@ -393,31 +356,10 @@ func (s *InitScriptService) SaveControlPlaneConfig(ctx context.Context, config *
 		return fmt.Errorf("failed to marshal control plane config: %w", err)
 	}
 
-	cm, err := s.k8sClient.GetConfigMap(ctx, BisonNamespace, ControlPlaneConfigConfigMap)
-	if err != nil {
-		if errors.IsNotFound(err) {
-			// Create new ConfigMap
-			cm = &corev1.ConfigMap{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      ControlPlaneConfigConfigMap,
-					Namespace: BisonNamespace,
-				},
-				Data: map[string]string{
-					"config": string(data),
-				},
-			}
-			return s.k8sClient.CreateConfigMap(ctx, BisonNamespace, cm)
-		}
-		return fmt.Errorf("failed to get control plane config: %w", err)
-	}
-
-	// Update existing ConfigMap
-	if cm.Data == nil {
-		cm.Data = make(map[string]string)
-	}
-	cm.Data["config"] = string(data)
-
-	return s.k8sClient.UpdateConfigMap(ctx, BisonNamespace, cm)
+	return s.store.Modify(ctx, ControlPlaneConfigConfigMap, func(entries map[string]string) error {
+		entries["config"] = string(data)
+		return nil
+	})
 }
 
 // SaveAllScriptGroups replaces all script groups at once (used by config import)
@@ -429,21 +371,14 @@ func (s *InitScriptService) SaveAllScriptGroups(ctx context.Context, groups []Sc
 
 // getInitScriptsConfig returns the init scripts configuration, initializing with defaults if not found
 func (s *InitScriptService) getInitScriptsConfig(ctx context.Context) (*InitScriptsConfig, error) {
-	cm, err := s.k8sClient.GetConfigMap(ctx, BisonNamespace, InitScriptsConfigMap)
+	entries, err := s.store.Load(ctx, InitScriptsConfigMap)
 	if err != nil {
-		if errors.IsNotFound(err) {
-			// Initialize with default builtin scripts
-			config := s.getDefaultInitScriptsConfig()
-			if err := s.saveInitScriptsConfig(ctx, config); err != nil {
-				return nil, err
-			}
-			return config, nil
-		}
 		return nil, fmt.Errorf("failed to get init scripts config: %w", err)
 	}
 
-	data, ok := cm.Data["config"]
+	data, ok := entries["config"]
 	if !ok {
+		// Initialize with default builtin scripts
 		config := s.getDefaultInitScriptsConfig()
 		if err := s.saveInitScriptsConfig(ctx, config); err != nil {
 			return nil, err
@@ -466,31 +401,37 @@ func (s *InitScriptService) saveInitScriptsConfig(ctx context.Context, config *I
 		return fmt.Errorf("failed to marshal init scripts config: %w", err)
 	}
 
-	cm, err := s.k8sClient.GetConfigMap(ctx, BisonNamespace, InitScriptsConfigMap)
-	if err != nil {
-		if errors.IsNotFound(err) {
-			// Create new ConfigMap
-			cm = &corev1.ConfigMap{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      InitScriptsConfigMap,
-					Namespace: BisonNamespace,
-				},
-				Data: map[string]string{
-					"config": string(data),
-				},
-			}
-			return s.k8sClient.CreateConfigMap(ctx, BisonNamespace, cm)
-		}
-		return fmt.Errorf("failed to get init scripts config: %w", err)
-	}
+	return s.store.Modify(ctx, InitScriptsConfigMap, func(entries map[string]string) error {
+		entries["config"] = string(data)
+		return nil
+	})
+}
 
-	// Update existing ConfigMap
-	if cm.Data == nil {
-		cm.Data = make(map[string]string)
+// modifyInitScriptsConfig applies mutate to the init scripts configuration,
+// re-reading and retrying when another writer updated it first
+func (s *InitScriptService) modifyInitScriptsConfig(ctx context.Context, mutate func(config *InitScriptsConfig) error) error {
+	// Make sure defaults are seeded before the first modification
+	if _, err := s.getInitScriptsConfig(ctx); err != nil {
+		return err
 	}
-	cm.Data["config"] = string(data)
 
-	return s.k8sClient.UpdateConfigMap(ctx, BisonNamespace, cm)
+	return s.store.Modify(ctx, InitScriptsConfigMap, func(entries map[string]string) error {
+		var config InitScriptsConfig
+		if err := json.Unmarshal([]byte(entries["config"]), &config); err != nil {
+			return fmt.Errorf("failed to parse init scripts config: %w", err)
+		}
+
+		if err := mutate(&config); err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(&config)
+		if err != nil {
+			return fmt.Errorf("failed to marshal init scripts config: %w", err)
+		}
+		entries["config"] = string(data)
+		return nil
+	})
 }
 
 // getDefaultInitScriptsConfig returns the default builtin script groups
@@ -0,0 +1,728 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bison/api-server/internal/opencost"
+	"github.com/bison/api-server/internal/pdf"
+	"github.com/bison/api-server/internal/store"
+	"github.com/bison/api-server/pkg/logger"
+)
+
+const (
+	// InvoicesConfigMap holds the invoice number sequence and the number given to each
+	// team's invoice for a period, keyed "<team>.<period>"
+	InvoicesConfigMap = "bison-invoices"
+
+	// InvoiceConfigMapPrefix names the per-team invoice collections, e.g. bison-invoices-team-a.
+	// Each invoice is stored under its period key.
+	InvoiceConfigMapPrefix = "bison-invoices-"
+
+	// invoiceSequenceKey holds the invoice number sequence. Team names cannot contain
+	// underscores.
+	invoiceSequenceKey = "_sequence"
+
+	// DefaultInvoicePrefix starts invoice numbers, e.g. "INV-000042"
+	DefaultInvoicePrefix = "INV"
+)
+
+// Invoice periods
+const (
+	InvoicePeriodMonthly = "monthly"
+	InvoicePeriodWeekly  = "weekly" // Monday to Monday
+)
+
+// Invoice statuses
+const (
+	InvoiceStatusDraft     = "draft"     // May be regenerated
+	InvoiceStatusFinalized = "finalized" // Locked
+)
+
+// Invoice export formats
+const (
+	InvoiceFormatJSON = "json"
+	InvoiceFormatCSV  = "csv"
+	InvoiceFormatPDF  = "pdf"
+)
+
+// InvoiceConfig controls invoice generation
+type InvoiceConfig struct {
+	Enabled      bool   `json:"enabled"`
+	Period       string `json:"period"`       // "monthly" (default) or "weekly"
+	Timezone     string `json:"timezone"`     // IANA zone periods are cut in; empty = UTC
+	NumberPrefix string `json:"numberPrefix"` // Defaults to "INV"
+	AutoFinalize bool   `json:"autoFinalize"` // Lock invoices as soon as they are generated
+}
+
+// Validate checks the invoice settings
+func (c *InvoiceConfig) Validate() error {
+	switch c.Period {
+	case "", InvoicePeriodMonthly, InvoicePeriodWeekly:
+	default:
+		return fmt.Errorf("invalid invoice period %q", c.Period)
+	}
+	if _, err := time.LoadLocation(c.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone %q: %w", c.Timezone, err)
+	}
+	return nil
+}
+
+// periodAt returns the invoice period containing t and its key, e.g. "2026-09" or "2026-W38"
+func (c *InvoiceConfig) periodAt(t time.Time) (time.Time, time.Time, string) {
+	if loc, err := time.LoadLocation(c.Timezone); err == nil {
+		t = t.In(loc)
+	}
+
+	if c.Period == InvoicePeriodWeekly {
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		start := day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+		year, week := start.ISOWeek()
+		return start, start.AddDate(0, 0, 7), fmt.Sprintf("%d-W%02d", year, week)
+	}
+
+	start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	return start, start.AddDate(0, 1, 0), start.Format("2006-01")
+}
+
+// prefix returns the invoice number prefix
+func (c *InvoiceConfig) prefix() string {
+	if c.NumberPrefix == "" {
+		return DefaultInvoicePrefix
+	}
+	return c.NumberPrefix
+}
+
+// InvoiceLineItem is the cost of one resource in one project, of one reserved node for
+// reservation-billed teams, or of one committed quota resource for committed-billed teams
+type InvoiceLineItem struct {
+	Project   string  `json:"project"`
+	Node      string  `json:"node,omitempty"`
+	Committed bool    `json:"committed,omitempty"` // Quota at the reservation price
+	Resource  string  `json:"resource"`
+	Cost      float64 `json:"cost"`
+}
+
+// label names what the line item charges for
+func (i *InvoiceLineItem) label() string {
+	switch {
+	case i.Node != "":
+		return "node/" + i.Node
+	case i.Committed:
+		return "committed capacity"
+	}
+	return i.Project
+}
+
+// Invoice is a team's bill for a closed period. Costs are captured when the invoice is
+// generated, so later changes to usage data or prices do not alter it.
+type Invoice struct {
+	Number         string            `json:"number"`
+	Team           string            `json:"team"`
+	Period         string            `json:"period"` // e.g. "2026-09" or "2026-W38"
+	PeriodStart    time.Time         `json:"periodStart"`
+	PeriodEnd      time.Time         `json:"periodEnd"`
+	Status         string            `json:"status"` // "draft" or "finalized"
+	Currency       string            `json:"currency"`
+	CurrencySymbol string            `json:"currencySymbol"`
+	LineItems      []InvoiceLineItem `json:"lineItems"`
+	Subtotal       float64           `json:"subtotal"`               // Sum of line items
+	Discount       float64           `json:"discount,omitempty"`     // Savings from the team pricing policy, already in the line items
+	OverheadCost   float64           `json:"overheadCost,omitempty"` // Share of idle and shared-namespace cost
+	Total          float64           `json:"total"`
+
+	// Ledger activity in the period
+	OpeningBalance float64           `json:"openingBalance"`
+	ClosingBalance float64           `json:"closingBalance"`
+	Charged        float64           `json:"charged"` // Usage deductions recorded in the period
+	Recharges      []*RechargeRecord `json:"recharges"`
+	Adjustments    []*RechargeRecord `json:"adjustments,omitempty"`
+
+	GeneratedAt time.Time  `json:"generatedAt"`
+	FinalizedAt *time.Time `json:"finalizedAt,omitempty"`
+	FinalizedBy string     `json:"finalizedBy,omitempty"`
+}
+
+// invoiceState tracks the invoice number sequence
+type invoiceState struct {
+	LastNumber int `json:"lastNumber"`
+}
+
+// InvoiceService generates and stores team invoices
+type InvoiceService struct {
+	store      store.Store
+	billingSvc *BillingService
+	balanceSvc *BalanceService
+	tenantSvc  *TenantService
+	projectSvc *ProjectService
+}
+
+// NewInvoiceService creates a new InvoiceService
+func NewInvoiceService(
+	st store.Store,
+	billingSvc *BillingService,
+	balanceSvc *BalanceService,
+	tenantSvc *TenantService,
+	projectSvc *ProjectService,
+) *InvoiceService {
+	return &InvoiceService{
+		store:      st,
+		billingSvc: billingSvc,
+		balanceSvc: balanceSvc,
+		tenantSvc:  tenantSvc,
+		projectSvc: projectSvc,
+	}
+}
+
+// GenerateDueInvoices creates invoices for the last closed period for every team that
+// does not have one yet. When billing is enabled it waits until billing has charged the
+// whole period, so the ledger figures are complete.
+func (s *InvoiceService) GenerateDueInvoices(ctx context.Context) error {
+	config, err := s.billingSvc.GetConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if config.Invoicing == nil || !config.Invoicing.Enabled {
+		return nil
+	}
+
+	current, _, _ := config.Invoicing.periodAt(time.Now())
+	start, end, period := config.Invoicing.periodAt(current.Add(-time.Nanosecond))
+
+	if config.Enabled {
+		state, err := s.billingSvc.GetBillingState(ctx)
+		if err != nil {
+			return err
+		}
+		if state.LastBilledEnd.Before(end) {
+			logger.Debug("Waiting for billing to close the invoice period", "period", period, "lastBilledEnd", state.LastBilledEnd)
+			return nil
+		}
+	}
+
+	teams, err := s.tenantSvc.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, team := range teams {
+		existing, err := s.findInvoice(ctx, team.Name, period)
+		if err != nil {
+			logger.Error("Failed to load invoices", "team", team.Name, "error", err)
+			continue
+		}
+		if existing != nil {
+			continue
+		}
+		if _, err := s.generate(ctx, config, team.Name, start, end, period); err != nil {
+			logger.Error("Failed to generate invoice", "team", team.Name, "period", period, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// GenerateInvoice creates or regenerates a team's invoice for the closed period containing
+// date (YYYY-MM-DD in the invoicing timezone), or for the last closed period when date is
+// empty. A draft invoice keeps its number; a finalized invoice cannot be regenerated.
+func (s *InvoiceService) GenerateInvoice(ctx context.Context, teamName, date string) (*Invoice, error) {
+	config, err := s.billingSvc.GetConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	invoicing := config.Invoicing
+	if invoicing == nil {
+		invoicing = &InvoiceConfig{}
+	}
+
+	var at time.Time
+	if date == "" {
+		current, _, _ := invoicing.periodAt(time.Now())
+		at = current.Add(-time.Nanosecond)
+	} else {
+		loc, err := time.LoadLocation(invoicing.Timezone)
+		if err != nil {
+			return nil, err
+		}
+		if at, err = time.ParseInLocation("2006-01-02", date, loc); err != nil {
+			return nil, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date)
+		}
+	}
+
+	start, end, period := invoicing.periodAt(at)
+	if end.After(time.Now()) {
+		return nil, fmt.Errorf("invoice period %s has not closed yet", period)
+	}
+
+	return s.generate(ctx, config, teamName, start, end, period)
+}
+
+// InvoiceCollection returns the name of a team's invoice collection
+func InvoiceCollection(teamName string) string {
+	return InvoiceConfigMapPrefix + teamName
+}
+
+// ListInvoices returns a team's invoices, newest first
+func (s *InvoiceService) ListInvoices(ctx context.Context, teamName string) ([]*Invoice, error) {
+	entries, err := s.store.Load(ctx, InvoiceCollection(teamName))
+	if err != nil {
+		return nil, err
+	}
+
+	invoices := make([]*Invoice, 0, len(entries))
+	for _, data := range entries {
+		invoice, err := parseInvoice(data)
+		if err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, invoice)
+	}
+	sort.Slice(invoices, func(i, j int) bool {
+		return invoices[i].PeriodStart.After(invoices[j].PeriodStart)
+	})
+	return invoices, nil
+}
+
+// GetInvoice returns a team's invoice by number, or nil if there is none
+func (s *InvoiceService) GetInvoice(ctx context.Context, teamName, number string) (*Invoice, error) {
+	invoices, err := s.ListInvoices(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	for _, invoice := range invoices {
+		if invoice.Number == number {
+			return invoice, nil
+		}
+	}
+	return nil, nil
+}
+
+// FinalizeInvoice locks a draft invoice
+func (s *InvoiceService) FinalizeInvoice(ctx context.Context, teamName, number, operator string) (*Invoice, error) {
+	logger.Info("Finalizing invoice", "team", teamName, "number", number, "operator", operator)
+
+	var finalized *Invoice
+	err := s.store.Modify(ctx, InvoiceCollection(teamName), func(entries map[string]string) error {
+		finalized = nil
+		for _, data := range entries {
+			invoice, err := parseInvoice(data)
+			if err != nil {
+				return err
+			}
+			if invoice.Number != number {
+				continue
+			}
+			if invoice.Status == InvoiceStatusFinalized {
+				return fmt.Errorf("invoice %s is already finalized", number)
+			}
+			now := time.Now()
+			invoice.Status = InvoiceStatusFinalized
+			invoice.FinalizedAt = &now
+			invoice.FinalizedBy = operator
+			finalized = invoice
+		}
+		if finalized == nil {
+			return fmt.Errorf("invoice %s not found", number)
+		}
+		return writeInvoice(entries, finalized)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return finalized, nil
+}
+
+// ExportInvoice renders an invoice as JSON, CSV or PDF and returns the content type
+func (s *InvoiceService) ExportInvoice(invoice *Invoice, format string) ([]byte, string, error) {
+	switch format {
+	case InvoiceFormatJSON:
+		data, err := json.MarshalIndent(invoice, "", "  ")
+		return data, "application/json", err
+	case InvoiceFormatCSV:
+		data, err := invoiceToCSV(invoice)
+		return data, "text/csv", err
+	case InvoiceFormatPDF:
+		return invoiceToPDF(invoice), "application/pdf", nil
+	default:
+		return nil, "", fmt.Errorf("unsupported invoice format %q", format)
+	}
+}
+
+// generate builds a team's invoice for a period and stores it
+func (s *InvoiceService) generate(ctx context.Context, config *BillingConfig, teamName string, start, end time.Time, period string) (*Invoice, error) {
+	logger.Info("Generating invoice", "team", teamName, "period", period)
+
+	existing, err := s.findInvoice(ctx, teamName, period)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.Status == InvoiceStatusFinalized {
+		return nil, fmt.Errorf("invoice %s for %s is finalized", existing.Number, period)
+	}
+
+	invoice, err := s.buildInvoice(ctx, config, teamName, start, end)
+	if err != nil {
+		return nil, err
+	}
+	invoice.Period = period
+
+	invoicing := config.Invoicing
+	if invoicing == nil {
+		invoicing = &InvoiceConfig{}
+	}
+	if invoicing.AutoFinalize {
+		invoice.Status = InvoiceStatusFinalized
+		invoice.FinalizedAt = &invoice.GeneratedAt
+		invoice.FinalizedBy = "system"
+	}
+
+	if existing != nil {
+		invoice.Number = existing.Number
+	} else if invoice.Number, err = s.assignNumber(ctx, teamName, period, invoicing.prefix()); err != nil {
+		return nil, err
+	}
+
+	err = s.store.Modify(ctx, InvoiceCollection(teamName), func(entries map[string]string) error {
+		if data, ok := entries[period]; ok {
+			stored, err := parseInvoice(data)
+			if err != nil {
+				return err
+			}
+			// Another replica may have finalized the invoice meanwhile
+			if stored.Status == InvoiceStatusFinalized || stored.Number != invoice.Number {
+				return fmt.Errorf("invoice %s for %s changed while generating", stored.Number, period)
+			}
+		}
+		return writeInvoice(entries, invoice)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return invoice, nil
+}
+
+// buildInvoice prices a team's usage in [start, end) per project and resource, or its
+// reserved node-hours per node, and summarizes its ledger over the period
+func (s *InvoiceService) buildInvoice(ctx context.Context, config *BillingConfig, teamName string, start, end time.Time) (*Invoice, error) {
+	invoice := &Invoice{
+		Team:           teamName,
+		PeriodStart:    start,
+		PeriodEnd:      end,
+		Status:         InvoiceStatusDraft,
+		Currency:       config.Currency,
+		CurrencySymbol: config.CurrencySymbol,
+		LineItems:      make([]InvoiceLineItem, 0),
+		Recharges:      make([]*RechargeRecord, 0),
+		GeneratedAt:    time.Now(),
+	}
+
+	team, err := s.tenantSvc.Get(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	if team.BillingMode == BillingModeReservation {
+		if err := s.addReservation(ctx, config, invoice, teamName, start, end); err != nil {
+			return nil, err
+		}
+	} else if err := s.addUsage(ctx, config, invoice, team, start, end); err != nil {
+		return nil, err
+	}
+	invoice.Total = invoice.Subtotal + invoice.OverheadCost
+
+	ledger, err := s.balanceSvc.GetLedger(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range ledger {
+		if record.Timestamp.Before(start) {
+			invoice.OpeningBalance += record.Amount
+		}
+		if !record.Timestamp.Before(end) {
+			continue
+		}
+		invoice.ClosingBalance += record.Amount
+		if record.Timestamp.Before(start) {
+			continue
+		}
+		switch {
+		case isUsageCharge(record.Type):
+			invoice.Charged -= record.Amount
+		case record.Type == RecordTypeRecharge || record.Type == RecordTypeAutoRecharge:
+			invoice.Recharges = append(invoice.Recharges, record)
+		case IsAdjustment(record.Type):
+			invoice.Adjustments = append(invoice.Adjustments, record)
+		}
+	}
+
+	return invoice, nil
+}
+
+// addUsage adds a line item per project and resource, and the team's overhead share. For
+// committed-billed teams it adds a line item per committed resource, and project usage is
+// cut down to the part above the quota.
+func (s *InvoiceService) addUsage(ctx context.Context, config *BillingConfig, invoice *Invoice, team *Team, start, end time.Time) error {
+	teamName := team.Name
+	window := opencost.FormatWindow(start, end)
+
+	var commitment *Commitment
+	if team.BillingMode == BillingModeCommitted {
+		var err error
+		if commitment, err = s.billingSvc.teamCommitment(ctx, config, team, start, end); err != nil {
+			return fmt.Errorf("failed to price committed capacity: %w", err)
+		}
+		for _, r := range commitment.Resources {
+			invoice.LineItems = append(invoice.LineItems, InvoiceLineItem{Committed: true, Resource: r.Resource, Cost: r.CommittedCost})
+			invoice.Subtotal += r.CommittedCost
+		}
+	}
+
+	projects, err := s.projectSvc.ListByTeam(ctx, teamName)
+	if err != nil {
+		return err
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].Name < projects[j].Name
+	})
+
+	// Usage is priced under the pricing policy billing charged it with
+	for _, project := range projects {
+		bill, err := s.billingSvc.projectBill(ctx, project.Name, window, start)
+		if err != nil {
+			return fmt.Errorf("failed to price project %s: %w", project.Name, err)
+		}
+
+		resources := make([]string, 0, len(bill.ResourceCosts))
+		for resource := range bill.ResourceCosts {
+			resources = append(resources, resource)
+		}
+		sort.Strings(resources)
+		var charged float64
+		for _, resource := range resources {
+			cost := bill.ResourceCosts[resource]
+			if commitment != nil {
+				cost *= commitment.BurstShare(resource)
+			}
+			invoice.LineItems = append(invoice.LineItems, InvoiceLineItem{Project: project.Name, Resource: resource, Cost: cost})
+			invoice.Subtotal += cost
+			charged += cost
+		}
+		discount := bill.Discount
+		if commitment != nil && bill.TotalCost > 0 {
+			discount *= charged / bill.TotalCost
+		}
+		invoice.Discount += discount
+	}
+
+	if overheadEnabled(config) && s.billingSvc.usageSource != nil && s.billingSvc.usageSource.IsEnabled() {
+		invoice.OverheadCost, err = s.billingSvc.teamOverheadShare(ctx, config, s.billingSvc.loadPricing(ctx), teamName, window)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addReservation adds a line item per node the team held in [start, end)
+func (s *InvoiceService) addReservation(ctx context.Context, config *BillingConfig, invoice *Invoice, teamName string, start, end time.Time) error {
+	policy := s.billingSvc.activePricingPolicy(ctx, teamName, start)
+	reservation, err := s.billingSvc.teamReservationCost(ctx, config, s.billingSvc.loadPricing(ctx), policy, teamName, start, end)
+	if err != nil {
+		return fmt.Errorf("failed to price reserved nodes: %w", err)
+	}
+
+	nodes := make([]string, 0, len(reservation.Nodes))
+	for node := range reservation.Nodes {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	for _, node := range nodes {
+		held := reservation.Nodes[node]
+		invoice.LineItems = append(invoice.LineItems, InvoiceLineItem{Node: node, Resource: fmt.Sprintf("%.0f node-hours", held.Hours), Cost: held.Cost})
+		invoice.Subtotal += held.Cost
+	}
+	invoice.Discount = reservation.Discount
+	return nil
+}
+
+// findInvoice returns a team's invoice for a period, or nil if there is none
+func (s *InvoiceService) findInvoice(ctx context.Context, teamName, period string) (*Invoice, error) {
+	entries, err := s.store.Load(ctx, InvoiceCollection(teamName))
+	if err != nil {
+		return nil, err
+	}
+	data, ok := entries[period]
+	if !ok {
+		return nil, nil
+	}
+	return parseInvoice(data)
+}
+
+// assignNumber returns the number of a team's invoice for a period, taking the next one in
+// the sequence the first time. The number stays assigned, so a generation that fails to
+// store the invoice, or races another replica, never leaves a gap in the sequence.
+func (s *InvoiceService) assignNumber(ctx context.Context, teamName, period, prefix string) (string, error) {
+	key := teamName + "." + period
+	var number string
+	err := s.store.Modify(ctx, InvoicesConfigMap, func(entries map[string]string) error {
+		if assigned, ok := entries[key]; ok {
+			number = assigned
+			return nil
+		}
+
+		var err error
+		if number, err = nextNumber(entries, prefix); err != nil {
+			return err
+		}
+		entries[key] = number
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return number, nil
+}
+
+// nextNumber takes the next number in the invoice sequence kept in the invoices collection
+func nextNumber(entries map[string]string, prefix string) (string, error) {
+	var state invoiceState
+	if data, ok := entries[invoiceSequenceKey]; ok {
+		if err := json.Unmarshal([]byte(data), &state); err != nil {
+			return "", fmt.Errorf("failed to parse invoice state: %w", err)
+		}
+	}
+
+	state.LastNumber++
+	data, err := json.Marshal(state)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal invoice state: %w", err)
+	}
+	entries[invoiceSequenceKey] = string(data)
+	return fmt.Sprintf("%s-%06d", prefix, state.LastNumber), nil
+}
+
+// writeInvoice stores an invoice under its period in a team's invoice collection
+func writeInvoice(entries map[string]string, invoice *Invoice) error {
+	data, err := json.Marshal(invoice)
+	if err != nil {
+		return fmt.Errorf("failed to marshal invoice: %w", err)
+	}
+	entries[invoice.Period] = string(data)
+	return nil
+}
+
+func parseInvoice(data string) (*Invoice, error) {
+	var invoice Invoice
+	if err := json.Unmarshal([]byte(data), &invoice); err != nil {
+		return nil, fmt.Errorf("failed to parse invoice: %w", err)
+	}
+	return &invoice, nil
+}
+
+func invoiceToCSV(invoice *Invoice) ([]byte, error) {
+	var buf bytes.Buffer
+	csvWriter := csv.NewWriter(&buf)
+
+	csvWriter.Write([]string{"Invoice", invoice.Number})
+	csvWriter.Write([]string{"Team", invoice.Team})
+	csvWriter.Write([]string{"Period", invoice.Period, invoice.PeriodStart.Format(time.RFC3339), invoice.PeriodEnd.Format(time.RFC3339)})
+	csvWriter.Write([]string{"Status", invoice.Status})
+	csvWriter.Write([]string{"Currency", invoice.Currency})
+	csvWriter.Write([]string{})
+
+	csvWriter.Write([]string{"Project", "Resource", "Cost"})
+	for _, item := range invoice.LineItems {
+		csvWriter.Write([]string{item.label(), item.Resource, fmt.Sprintf("%.2f", item.Cost)})
+	}
+	csvWriter.Write([]string{"Subtotal", "", fmt.Sprintf("%.2f", invoice.Subtotal)})
+	if invoice.Discount != 0 {
+		csvWriter.Write([]string{"Contract Discount (included)", "", fmt.Sprintf("%.2f", invoice.Discount)})
+	}
+	if invoice.OverheadCost != 0 {
+		csvWriter.Write([]string{"Shared Overhead", "", fmt.Sprintf("%.2f", invoice.OverheadCost)})
+	}
+	csvWriter.Write([]string{"Total", "", fmt.Sprintf("%.2f", invoice.Total)})
+	csvWriter.Write([]string{})
+
+	csvWriter.Write([]string{"Opening Balance", fmt.Sprintf("%.2f", invoice.OpeningBalance)})
+	csvWriter.Write([]string{"Charged", fmt.Sprintf("%.2f", invoice.Charged)})
+	csvWriter.Write([]string{"Closing Balance", fmt.Sprintf("%.2f", invoice.ClosingBalance)})
+
+	writeRecords := func(title string, records []*RechargeRecord) {
+		if len(records) == 0 {
+			return
+		}
+		csvWriter.Write([]string{})
+		csvWriter.Write([]string{title, "Time", "Amount", "Operator", "Reason"})
+		for _, record := range records {
+			csvWriter.Write([]string{record.Type, record.Timestamp.Format(time.RFC3339), fmt.Sprintf("%.2f", record.Amount), record.Operator, record.Reason})
+		}
+	}
+	writeRecords("Recharge", invoice.Recharges)
+	writeRecords("Adjustment", invoice.Adjustments)
+
+	csvWriter.Flush()
+	return buf.Bytes(), csvWriter.Error()
+}
+
+func invoiceToPDF(invoice *Invoice) []byte {
+	doc := pdf.New()
+	amount := func(value float64) string {
+		return fmt.Sprintf("%.2f %s", value, invoice.Currency)
+	}
+
+	doc.Title("Invoice " + invoice.Number)
+	doc.Text("Team: " + invoice.Team)
+	doc.Text(fmt.Sprintf("Period: %s (%s to %s)", invoice.Period, invoice.PeriodStart.Format("2006-01-02"), invoice.PeriodEnd.Format("2006-01-02")))
+	doc.Text("Status: " + invoice.Status)
+	doc.Text("Generated: " + invoice.GeneratedAt.Format(time.RFC3339))
+	doc.Space()
+
+	doc.Heading("Charges")
+	doc.Mono(fmt.Sprintf("%-28s %-28s %20s", "Project", "Resource", "Cost"))
+	doc.Mono(strings.Repeat("-", 78))
+	for _, item := range invoice.LineItems {
+		doc.Mono(fmt.Sprintf("%-28.28s %-28.28s %20s", item.label(), item.Resource, amount(item.Cost)))
+	}
+	doc.Mono(strings.Repeat("-", 78))
+	doc.Mono(fmt.Sprintf("%-57s %20s", "Subtotal", amount(invoice.Subtotal)))
+	if invoice.Discount != 0 {
+		doc.Mono(fmt.Sprintf("%-57s %20s", "Contract discount (included)", amount(invoice.Discount)))
+	}
+	if invoice.OverheadCost != 0 {
+		doc.Mono(fmt.Sprintf("%-57s %20s", "Shared overhead", amount(invoice.OverheadCost)))
+	}
+	doc.Mono(fmt.Sprintf("%-57s %20s", "Total", amount(invoice.Total)))
+	doc.Space()
+
+	doc.Heading("Balance")
+	doc.Mono(fmt.Sprintf("%-57s %20s", "Opening balance", amount(invoice.OpeningBalance)))
+	doc.Mono(fmt.Sprintf("%-57s %20s", "Charged", amount(invoice.Charged)))
+	doc.Mono(fmt.Sprintf("%-57s %20s", "Closing balance", amount(invoice.ClosingBalance)))
+
+	writeRecords := func(title string, records []*RechargeRecord) {
+		if len(records) == 0 {
+			return
+		}
+		doc.Space()
+		doc.Heading(title)
+		for _, record := range records {
+			doc.Mono(fmt.Sprintf("%-20s %-16.16s %-20.20s %20s", record.Timestamp.Format("2006-01-02 15:04"), record.Type, record.Operator, amount(record.Amount)))
+		}
+	}
+	writeRecords("Recharges", invoice.Recharges)
+	writeRecords("Adjustments", invoice.Adjustments)
+
+	if invoice.FinalizedAt != nil {
+		doc.Space()
+		doc.Text(fmt.Sprintf("Finalized %s by %s", invoice.FinalizedAt.Format(time.RFC3339), invoice.FinalizedBy))
+	}
+
+	return doc.Bytes()
+}
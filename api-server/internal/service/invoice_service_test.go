@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bison/api-server/internal/k8s"
+	"github.com/bison/api-server/internal/store"
+)
+
+func TestAssignNumberKeepsNumbersPerTeamPeriod(t *testing.T) {
+	ctx := context.Background()
+	svc := &InvoiceService{store: store.NewConfigMapStore(k8s.NewClientFromInterfaces(newConflictingClientset(0), nil), store.Namespace)}
+
+	assign := func(teamName, period string) string {
+		t.Helper()
+		number, err := svc.assignNumber(ctx, teamName, period, DefaultInvoicePrefix)
+		if err != nil {
+			t.Fatalf("failed to assign number for %s %s: %v", teamName, period, err)
+		}
+		return number
+	}
+
+	first := assign("team-a", "2026-09")
+	if first != "INV-000001" {
+		t.Fatalf("got first number %s, want INV-000001", first)
+	}
+	if again := assign("team-a", "2026-09"); again != first {
+		t.Fatalf("got %s for the same team and period again, want %s", again, first)
+	}
+	if other := assign("team-b", "2026-09"); other != "INV-000002" {
+		t.Fatalf("got %s for another team, want INV-000002", other)
+	}
+	if next := assign("team-a", "2026-10"); next != "INV-000003" {
+		t.Fatalf("got %s for the next period, want INV-000003", next)
+	}
+}
@@ -0,0 +1,609 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/bison/api-server/internal/k8s"
+	"github.com/bison/api-server/internal/opencost"
+	"github.com/bison/api-server/internal/store"
+	"github.com/bison/api-server/pkg/logger"
+)
+
+// MeteringConfigMapPrefix names the per-day collections of metered usage, e.g. bison-metering-20261016.
+// Each collection maps the UTC hour ("00"-"23") to that hour's usage.
+const MeteringConfigMapPrefix = "bison-metering-"
+
+// MeteredUsage is the usage of one user's pods in a namespace on one node within an hour,
+// integrated from the resource requests of the running pods
+type MeteredUsage struct {
+	Namespace     string             `json:"namespace"`
+	Node          string             `json:"node"`
+	User          string             `json:"user,omitempty"` // Creator recorded at admission; empty for unattributed pods
+	CPUCoreHours  float64            `json:"cpuCoreHours"`
+	RAMByteHours  float64            `json:"ramByteHours"`
+	ResourceHours map[string]float64 `json:"resourceHours,omitempty"` // Extended resources by name
+	Minutes       float64            `json:"minutes"`                 // Time with at least one running pod
+}
+
+// meteredHour is the stored usage of one hour
+type meteredHour struct {
+	Slots []int           `json:"slots"` // Sample slots already integrated, so no slot is counted twice
+	Usage []*MeteredUsage `json:"usage"`
+}
+
+// MeteringService samples pod resource requests on a fixed cadence and persists the integrated
+// resource-hours. It is the usage source when OpenCost is not installed.
+type MeteringService struct {
+	k8sClient         *k8s.Client
+	store             store.Store
+	resourceConfigSvc *ResourceConfigService
+	interval          time.Duration
+	retentionDays     int
+
+	prunedDay string // Last day expired collections were pruned on
+}
+
+// NewMeteringService creates a new MeteringService. interval must divide an hour evenly.
+func NewMeteringService(
+	k8sClient *k8s.Client,
+	st store.Store,
+	resourceConfigSvc *ResourceConfigService,
+	interval time.Duration,
+	retentionDays int,
+) *MeteringService {
+	return &MeteringService{
+		k8sClient:         k8sClient,
+		store:             st,
+		resourceConfigSvc: resourceConfigSvc,
+		interval:          interval,
+		retentionDays:     retentionDays,
+	}
+}
+
+// MeteringCollection returns the collection holding a UTC day's metered usage
+func MeteringCollection(day time.Time) string {
+	return MeteringConfigMapPrefix + day.UTC().Format("20060102")
+}
+
+// MeteringCollections lists the collections that may hold metered usage within the retention period
+func MeteringCollections(now time.Time, retentionDays int) []string {
+	collections := make([]string, 0, retentionDays+1)
+	for i := retentionDays; i >= 0; i-- {
+		collections = append(collections, MeteringCollection(now.AddDate(0, 0, -i)))
+	}
+	return collections
+}
+
+// Name implements UsageSource
+func (s *MeteringService) Name() string {
+	return UsageSourceMetering
+}
+
+// IsEnabled implements UsageSource; metering only needs the Kubernetes API
+func (s *MeteringService) IsEnabled() bool {
+	return s.k8sClient != nil
+}
+
+// Interval returns the sampling cadence
+func (s *MeteringService) Interval() time.Duration {
+	return s.interval
+}
+
+// Sample records the resource requests of all running pods for the current sample slot.
+// Every API server replica may sample; a slot already recorded by another replica is skipped.
+func (s *MeteringService) Sample(ctx context.Context) error {
+	slotStart := time.Now().UTC().Truncate(s.interval)
+	hour := slotStart.Truncate(time.Hour)
+	slot := int(slotStart.Sub(hour) / s.interval)
+
+	pods, err := s.k8sClient.ListPods(ctx, "", "")
+	if err != nil {
+		return fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	extended := s.meteredResources(ctx)
+	hours := s.interval.Hours()
+	sample := make(map[string]*MeteredUsage)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase != corev1.PodRunning || pod.Spec.NodeName == "" {
+			continue
+		}
+
+		key := meteredUsageKey(pod.Namespace, pod.Spec.NodeName, pod.Annotations[AnnotationCreatedBy])
+		usage, ok := sample[key]
+		if !ok {
+			usage = &MeteredUsage{
+				Namespace:     pod.Namespace,
+				Node:          pod.Spec.NodeName,
+				User:          pod.Annotations[AnnotationCreatedBy],
+				ResourceHours: make(map[string]float64),
+				Minutes:       s.interval.Minutes(),
+			}
+			sample[key] = usage
+		}
+
+		requests := podRequests(pod)
+		if quantity, ok := requests[corev1.ResourceCPU]; ok {
+			usage.CPUCoreHours += quantity.AsApproximateFloat64() * hours
+		}
+		if quantity, ok := requests[corev1.ResourceMemory]; ok {
+			usage.RAMByteHours += quantity.AsApproximateFloat64() * hours
+		}
+		for _, name := range extended {
+			if quantity, ok := requests[corev1.ResourceName(name)]; ok && !quantity.IsZero() {
+				usage.ResourceHours[name] += quantity.AsApproximateFloat64() * hours
+			}
+		}
+	}
+
+	key := hour.Format("15")
+	err = s.store.Modify(ctx, MeteringCollection(hour), func(data map[string]string) error {
+		var stored meteredHour
+		if raw, ok := data[key]; ok {
+			if err := json.Unmarshal([]byte(raw), &stored); err != nil {
+				return fmt.Errorf("failed to parse metered usage: %w", err)
+			}
+		}
+		for _, recorded := range stored.Slots {
+			if recorded == slot {
+				return nil
+			}
+		}
+
+		stored.Slots = append(stored.Slots, slot)
+		stored.Usage = mergeUsage(stored.Usage, sample)
+
+		raw, err := json.Marshal(stored)
+		if err != nil {
+			return fmt.Errorf("failed to marshal metered usage: %w", err)
+		}
+		data[key] = string(raw)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Debug("Metered pod requests", "hour", hour, "slot", slot, "namespaceNodes", len(sample))
+	s.pruneExpired(ctx, hour)
+	return nil
+}
+
+// meteredResources returns the extended resources to meter besides CPU and memory
+func (s *MeteringService) meteredResources(ctx context.Context) []string {
+	configs, err := s.resourceConfigSvc.GetEnabledResourceConfigs(ctx)
+	if err != nil {
+		logger.Warn("Failed to load resource configs, metering CPU and memory only", "error", err)
+		return nil
+	}
+
+	var names []string
+	for _, rc := range configs {
+		if rc.Name == "cpu" || rc.Name == "memory" || IsBillingOnlyResource(rc.Name) {
+			continue
+		}
+		names = append(names, rc.Name)
+	}
+	return names
+}
+
+// pruneExpired deletes collections that fell out of the retention period, once per day
+func (s *MeteringService) pruneExpired(ctx context.Context, now time.Time) {
+	day := now.Format("20060102")
+	if day == s.prunedDay {
+		return
+	}
+	s.prunedDay = day
+
+	pruneDailyCollections(ctx, s.store, MeteringConfigMapPrefix, now.AddDate(0, 0, -s.retentionDays))
+}
+
+// pruneDailyCollections deletes the per-day collections with the prefix that are dated
+// before cutoff. Every stored day is checked, so days missed while the server was down
+// are pruned too.
+func pruneDailyCollections(ctx context.Context, st store.Store, prefix string, cutoff time.Time) {
+	collections, err := st.Collections(ctx, prefix)
+	if err != nil {
+		logger.Warn("Failed to list collections to prune", "prefix", prefix, "error", err)
+		return
+	}
+
+	oldest := cutoff.UTC().Format("20060102")
+	for _, collection := range collections {
+		day := strings.TrimPrefix(collection, prefix)
+		if _, err := time.Parse("20060102", day); err != nil || day >= oldest {
+			continue
+		}
+		if err := st.Delete(ctx, collection); err != nil {
+			logger.Warn("Failed to prune expired collection", "collection", collection, "error", err)
+			continue
+		}
+		logger.Info("Pruned expired collection", "collection", collection)
+	}
+}
+
+// podRequests returns the resources a running pod holds: its containers' requests plus
+// those of sidecar init containers, which keep running alongside them
+func podRequests(pod *corev1.Pod) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	add := func(list corev1.ResourceList) {
+		for name, quantity := range list {
+			total, ok := requests[name]
+			if !ok {
+				total = resource.Quantity{}
+			}
+			total.Add(quantity)
+			requests[name] = total
+		}
+	}
+
+	for _, container := range pod.Spec.Containers {
+		add(container.Resources.Requests)
+	}
+	for _, container := range pod.Spec.InitContainers {
+		if container.RestartPolicy != nil && *container.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			add(container.Resources.Requests)
+		}
+	}
+	return requests
+}
+
+// mergeUsage adds a sample to the stored usage of an hour. The sample is not modified,
+// since store updates may be retried.
+func mergeUsage(stored []*MeteredUsage, sample map[string]*MeteredUsage) []*MeteredUsage {
+	index := make(map[string]*MeteredUsage, len(stored))
+	for _, usage := range stored {
+		index[meteredUsageKey(usage.Namespace, usage.Node, usage.User)] = usage
+	}
+
+	for key, add := range sample {
+		usage, ok := index[key]
+		if !ok {
+			usage = &MeteredUsage{Namespace: add.Namespace, Node: add.Node, User: add.User}
+			index[key] = usage
+			stored = append(stored, usage)
+		}
+		usage.CPUCoreHours += add.CPUCoreHours
+		usage.RAMByteHours += add.RAMByteHours
+		usage.Minutes += add.Minutes
+		for name, hours := range add.ResourceHours {
+			if usage.ResourceHours == nil {
+				usage.ResourceHours = make(map[string]float64)
+			}
+			usage.ResourceHours[name] += hours
+		}
+	}
+	return stored
+}
+
+// meteredUsageKey identifies the usage of one user's pods in a namespace on a node
+func meteredUsageKey(namespace, node, user string) string {
+	return namespace + "/" + node + "/" + user
+}
+
+// GetPodAllocations implements UsageSource. Metered usage is kept per namespace, node and
+// creator, so each allocation covers one user's pods in a namespace on one node. The
+// creator is reported as the LabelUser label.
+func (s *MeteringService) GetPodAllocations(ctx context.Context, window, namespace string) ([]opencost.Allocation, error) {
+	start, end, err := parseWindow(window, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	hours, err := s.loadUsage(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return s.loadListPrices(ctx).aggregate(hours, namespace, true, start, end), nil
+}
+
+// GetPodAllocationSteps implements UsageSource
+func (s *MeteringService) GetPodAllocationSteps(ctx context.Context, window, namespace, step string) ([]opencost.AllocationStep, error) {
+	start, end, err := parseWindow(window, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	stepDuration, err := parseStep(step)
+	if err != nil {
+		return nil, err
+	}
+	hours, err := s.loadUsage(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	// Group the stored hours into steps
+	grouped := make(map[time.Time]map[time.Time][]*MeteredUsage)
+	for hour, usage := range hours {
+		stepStart := hour.Truncate(stepDuration)
+		if grouped[stepStart] == nil {
+			grouped[stepStart] = make(map[time.Time][]*MeteredUsage)
+		}
+		grouped[stepStart][hour] = usage
+	}
+
+	prices := s.loadListPrices(ctx)
+	var steps []opencost.AllocationStep
+	for stepStart, stepHours := range grouped {
+		stepEnd := stepStart.Add(stepDuration)
+		allocations := prices.aggregate(stepHours, namespace, true, stepStart, stepEnd)
+		if len(allocations) == 0 {
+			continue
+		}
+		steps = append(steps, opencost.AllocationStep{Start: stepStart, End: stepEnd, Allocations: allocations})
+	}
+
+	sort.Slice(steps, func(i, j int) bool {
+		return steps[i].Start.Before(steps[j].Start)
+	})
+	return steps, nil
+}
+
+// GetNamespaceAllocations implements UsageSource. Metering does not know the cost of
+// unallocated capacity, so includeIdle adds nothing.
+func (s *MeteringService) GetNamespaceAllocations(ctx context.Context, window string, includeIdle bool) ([]opencost.Allocation, error) {
+	start, end, err := parseWindow(window, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	hours, err := s.loadUsage(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return s.loadListPrices(ctx).aggregate(hours, "", false, start, end), nil
+}
+
+// GetCostTrend implements UsageSource
+func (s *MeteringService) GetCostTrend(ctx context.Context, window string) ([]opencost.CostTrendPoint, error) {
+	days := 7
+	switch window {
+	case "1d", "today":
+		days = 1
+	case "2d", "yesterday":
+		days = 2
+	case "30d", "month":
+		days = 30
+	}
+
+	now := time.Now().UTC()
+	start := now.Truncate(24*time.Hour).AddDate(0, 0, -(days - 1))
+	hours, err := s.loadUsage(ctx, start, now)
+	if err != nil {
+		return nil, err
+	}
+
+	prices := s.loadListPrices(ctx)
+	daily := make(map[string]float64)
+	for hour, usage := range hours {
+		for _, alloc := range prices.aggregate(map[time.Time][]*MeteredUsage{hour: usage}, "", false, hour, hour.Add(time.Hour)) {
+			daily[hour.Format("2006-01-02")] += alloc.TotalCost
+		}
+	}
+
+	trend := make([]opencost.CostTrendPoint, 0, days)
+	for day := start; day.Before(now); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		trend = append(trend, opencost.CostTrendPoint{Date: date, TotalCost: daily[date]})
+	}
+	return trend, nil
+}
+
+// loadUsage returns the metered usage of every hour starting within [start, end)
+func (s *MeteringService) loadUsage(ctx context.Context, start, end time.Time) (map[time.Time][]*MeteredUsage, error) {
+	from := start.UTC().Truncate(time.Hour)
+	hours := make(map[time.Time][]*MeteredUsage)
+
+	for day := from.Truncate(24 * time.Hour); day.Before(end); day = day.AddDate(0, 0, 1) {
+		data, err := s.store.Load(ctx, MeteringCollection(day))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load metered usage: %w", err)
+		}
+		for key, raw := range data {
+			h, err := strconv.Atoi(key)
+			if err != nil {
+				continue
+			}
+			hour := day.Add(time.Duration(h) * time.Hour)
+			if hour.Before(from) || !hour.Before(end) {
+				continue
+			}
+
+			var stored meteredHour
+			if err := json.Unmarshal([]byte(raw), &stored); err != nil {
+				logger.Warn("Skipping invalid metered usage", "hour", hour, "error", err)
+				continue
+			}
+			hours[hour] = stored.Usage
+		}
+	}
+
+	return hours, nil
+}
+
+// meteringPrices holds the list prices used to cost metered usage
+type meteringPrices struct {
+	cpu       float64
+	memory    float64
+	resources map[string]ResourceDefinition // Enabled extended resources by name
+}
+
+// loadListPrices reads the configured resource prices. Metered costs are always at list
+// prices, like OpenCost's; billing reprices allocations with team policies and bands.
+func (s *MeteringService) loadListPrices(ctx context.Context) *meteringPrices {
+	prices := &meteringPrices{resources: make(map[string]ResourceDefinition)}
+
+	configs, err := s.resourceConfigSvc.GetEnabledResourceConfigs(ctx)
+	if err != nil {
+		logger.Warn("Failed to load resource prices for metered usage", "error", err)
+		return prices
+	}
+	for _, rc := range configs {
+		switch rc.Name {
+		case "cpu":
+			prices.cpu = rc.Price
+		case "memory":
+			prices.memory = rc.Price
+		default:
+			prices.resources[rc.Name] = rc
+		}
+	}
+	return prices
+}
+
+// aggregate sums metered hours into allocations per namespace, or per namespace, node and
+// creator when byNode is set, optionally limited to one namespace
+func (p *meteringPrices) aggregate(hours map[time.Time][]*MeteredUsage, namespace string, byNode bool, start, end time.Time) []opencost.Allocation {
+	groups := make(map[string]*opencost.Allocation)
+	for _, records := range hours {
+		// A namespace on several nodes is still only running for the hour once
+		minutes := make(map[string]float64)
+		for _, usage := range records {
+			if namespace != "" && usage.Namespace != namespace {
+				continue
+			}
+
+			key := usage.Namespace
+			if byNode {
+				key = meteredUsageKey(usage.Namespace, usage.Node, usage.User)
+			}
+			alloc, ok := groups[key]
+			if !ok {
+				alloc = &opencost.Allocation{
+					Name:          key,
+					Properties:    opencost.AllocationProps{Namespace: usage.Namespace},
+					Start:         start.Format(time.RFC3339),
+					End:           end.Format(time.RFC3339),
+					ResourceHours: make(map[string]float64),
+				}
+				if byNode {
+					alloc.Properties.Node = usage.Node
+					if usage.User != "" {
+						alloc.Properties.Labels = map[string]string{LabelUser: usage.User}
+					}
+				}
+				groups[key] = alloc
+			}
+
+			alloc.CPUCoreHours += usage.CPUCoreHours
+			alloc.RAMByteHours += usage.RAMByteHours
+			for name, resourceHours := range usage.ResourceHours {
+				alloc.ResourceHours[name] += resourceHours
+			}
+			if usage.Minutes > minutes[key] {
+				minutes[key] = usage.Minutes
+			}
+		}
+		for key, m := range minutes {
+			groups[key].Minutes += m
+		}
+	}
+
+	allocations := make([]opencost.Allocation, 0, len(groups))
+	for _, alloc := range groups {
+		alloc.RAMGBHours = alloc.RAMByteHours / bytesPerGB
+		alloc.CPUCost = alloc.CPUCoreHours * p.cpu
+		alloc.RAMCost = alloc.RAMGBHours * p.memory
+		alloc.TotalCost = alloc.CPUCost + alloc.RAMCost
+		for name, resourceHours := range alloc.ResourceHours {
+			rc, ok := p.resources[name]
+			if !ok {
+				continue
+			}
+			cost := resourceHours * rc.Price
+			if rc.Category == CategoryAccelerator {
+				alloc.GPUHours += resourceHours
+				alloc.GPUCost += cost
+			}
+			alloc.TotalCost += cost
+		}
+		allocations = append(allocations, *alloc)
+	}
+	return allocations
+}
+
+// parseWindow resolves an OpenCost-style window to a UTC [start, end) range
+func parseWindow(window string, now time.Time) (time.Time, time.Time, error) {
+	now = now.UTC()
+	today := now.Truncate(24 * time.Hour)
+
+	if from, to, ok := strings.Cut(window, ","); ok {
+		start, err := time.Parse(time.RFC3339, strings.TrimSpace(from))
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid window start %q: %w", from, err)
+		}
+		end, err := time.Parse(time.RFC3339, strings.TrimSpace(to))
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid window end %q: %w", to, err)
+		}
+		return start.UTC(), end.UTC(), nil
+	}
+
+	switch window {
+	case "today":
+		return today, now, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), today, nil
+	case "week":
+		return today.AddDate(0, 0, -int(today.Weekday())), now, nil
+	case "lastweek":
+		weekStart := today.AddDate(0, 0, -int(today.Weekday()))
+		return weekStart.AddDate(0, 0, -7), weekStart, nil
+	case "month":
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC), now, nil
+	case "lastmonth":
+		monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return monthStart.AddDate(0, -1, 0), monthStart, nil
+	}
+
+	if len(window) < 2 {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid window: %s", window)
+	}
+	n, err := strconv.Atoi(window[:len(window)-1])
+	if err != nil || n <= 0 {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid window: %s", window)
+	}
+	var unit time.Duration
+	switch window[len(window)-1] {
+	case 'm':
+		unit = time.Minute
+	case 'h':
+		unit = time.Hour
+	case 'd':
+		unit = 24 * time.Hour
+	case 'w':
+		unit = 7 * 24 * time.Hour
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid window: %s", window)
+	}
+	return now.Add(-time.Duration(n) * unit), now, nil
+}
+
+// parseStep parses a query step such as "1h" or "1d"; metered usage has hourly resolution
+func parseStep(step string) (time.Duration, error) {
+	var d time.Duration
+	if days, ok := strings.CutSuffix(step, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid step: %s", step)
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if d, err = time.ParseDuration(step); err != nil {
+			return 0, fmt.Errorf("invalid step: %s", step)
+		}
+	}
+	if d < time.Hour || d%time.Hour != 0 {
+		return 0, fmt.Errorf("step must be a whole number of hours: %s", step)
+	}
+	return d, nil
+}
@@ -8,11 +8,11 @@ import (
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/bison/api-server/internal/k8s"
 	"github.com/bison/api-server/internal/ssh"
+	"github.com/bison/api-server/internal/store"
 	"github.com/bison/api-server/pkg/logger"
 )
 
@@ -82,6 +82,7 @@ type OnboardingRequest struct {
 // OnboardingService handles node onboarding operations
 type OnboardingService struct {
 	k8sClient       *k8s.Client
+	store           store.Store
 	nodeSvc         *NodeService
 	initScriptSvc   *InitScriptService
 	runningJobs     map[string]context.CancelFunc
@@ -89,9 +90,10 @@ type OnboardingService struct {
 }
 
 // NewOnboardingService creates a new OnboardingService
-func NewOnboardingService(k8sClient *k8s.Client, nodeSvc *NodeService, initScriptSvc *InitScriptService) *OnboardingService {
+func NewOnboardingService(k8sClient *k8s.Client, st store.Store, nodeSvc *NodeService, initScriptSvc *InitScriptService) *OnboardingService {
 	return &OnboardingService{
 		k8sClient:     k8sClient,
+		store:         st,
 		nodeSvc:       nodeSvc,
 		initScriptSvc: initScriptSvc,
 		runningJobs:   make(map[string]context.CancelFunc),
@@ -680,47 +682,19 @@ func (s *OnboardingService) saveJob(ctx context.Context, job *OnboardingJob) err
 		return fmt.Errorf("failed to marshal job: %w", err)
 	}
 
-	cm, err := s.k8sClient.GetConfigMap(ctx, BisonNamespace, OnboardingJobsConfigMap)
-	if err != nil {
-		if errors.IsNotFound(err) {
-			// Create new ConfigMap
-			cm = &corev1.ConfigMap{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      OnboardingJobsConfigMap,
-					Namespace: BisonNamespace,
-				},
-				Data: map[string]string{
-					job.ID: string(data),
-				},
-			}
-			return s.k8sClient.CreateConfigMap(ctx, BisonNamespace, cm)
-		}
-		return fmt.Errorf("failed to get jobs config: %w", err)
-	}
-
-	// Update existing ConfigMap
-	if cm.Data == nil {
-		cm.Data = make(map[string]string)
-	}
-	cm.Data[job.ID] = string(data)
-
-	return s.k8sClient.UpdateConfigMap(ctx, BisonNamespace, cm)
+	return s.store.Modify(ctx, OnboardingJobsConfigMap, func(entries map[string]string) error {
+		entries[job.ID] = string(data)
+		return nil
+	})
 }
 
 func (s *OnboardingService) getJobsMap(ctx context.Context) (map[string]string, error) {
-	cm, err := s.k8sClient.GetConfigMap(ctx, BisonNamespace, OnboardingJobsConfigMap)
+	jobs, err := s.store.Load(ctx, OnboardingJobsConfigMap)
 	if err != nil {
-		if errors.IsNotFound(err) {
-			return make(map[string]string), nil
-		}
 		return nil, fmt.Errorf("failed to get jobs config: %w", err)
 	}
 
-	if cm.Data == nil {
-		return make(map[string]string), nil
-	}
-
-	return cm.Data, nil
+	return jobs, nil
 }
 
 // TestControlPlaneConnection tests the SSH connection to the control plane
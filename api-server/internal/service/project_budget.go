@@ -0,0 +1,334 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/bison/api-server/pkg/logger"
+)
+
+// ProjectBudgetsConfigMap stores the monthly budget of each project, keyed by project name
+const ProjectBudgetsConfigMap = "bison-project-budgets"
+
+// ProjectBudget caps what a project may spend in a calendar month, so one project cannot
+// drain its team's balance. Spend is the project's month-to-date bill.
+type ProjectBudget struct {
+	Project       string    `json:"project"`
+	Team          string    `json:"team"`
+	MonthlyAmount float64   `json:"monthlyAmount"`
+	WarnPercent   float64   `json:"warnPercent"` // Warn team owners when spend reaches this share of the budget; 0 disables the warning
+	HardStop      bool      `json:"hardStop"`    // Suspend the project once spend reaches the budget
+	UpdatedAt     time.Time `json:"updatedAt,omitempty"`
+	UpdatedBy     string    `json:"updatedBy,omitempty"`
+
+	// Tracking, maintained by the budget check
+	Month       string     `json:"month,omitempty"` // UTC month the tracking applies to, e.g. 2026-10
+	Spent       float64    `json:"spent"`
+	CheckedAt   *time.Time `json:"checkedAt,omitempty"`
+	Warned      bool       `json:"warned"`                // Warning already sent this month
+	Exceeded    bool       `json:"exceeded"`              // Exceeded notice already sent this month
+	Suspended   bool       `json:"suspended"`             // Project is suspended by its budget
+	SuspendedAt *time.Time `json:"suspendedAt,omitempty"` // When the budget suspended the project
+}
+
+// Validate checks a project budget
+func (b *ProjectBudget) Validate() error {
+	if b.MonthlyAmount <= 0 {
+		return fmt.Errorf("monthly amount must be positive")
+	}
+	if b.WarnPercent < 0 || b.WarnPercent > 100 {
+		return fmt.Errorf("warn percent must be between 0 and 100")
+	}
+	return nil
+}
+
+// keepTracking copies the tracking state of a stored budget into an updated one
+func (b *ProjectBudget) keepTracking(stored *ProjectBudget) {
+	b.Month = stored.Month
+	b.Spent = stored.Spent
+	b.CheckedAt = stored.CheckedAt
+	b.Warned = stored.Warned
+	b.Exceeded = stored.Exceeded
+	b.Suspended = stored.Suspended
+	b.SuspendedAt = stored.SuspendedAt
+}
+
+// GetProjectBudget returns the budget of a project, or nil if it has none
+func (s *BillingService) GetProjectBudget(ctx context.Context, projectName string) (*ProjectBudget, error) {
+	entries, err := s.store.Load(ctx, ProjectBudgetsConfigMap)
+	if err != nil {
+		return nil, err
+	}
+
+	data, ok := entries[projectName]
+	if !ok {
+		return nil, nil
+	}
+
+	var budget ProjectBudget
+	if err := json.Unmarshal([]byte(data), &budget); err != nil {
+		logger.Error("Failed to unmarshal project budget", "project", projectName, "error", err)
+		return nil, fmt.Errorf("failed to parse project budget: %w", err)
+	}
+
+	return &budget, nil
+}
+
+// ListProjectBudgets returns the budgets of a team's projects, or of every project if teamName is empty
+func (s *BillingService) ListProjectBudgets(ctx context.Context, teamName string) ([]*ProjectBudget, error) {
+	entries, err := s.store.Load(ctx, ProjectBudgetsConfigMap)
+	if err != nil {
+		return nil, err
+	}
+
+	budgets := make([]*ProjectBudget, 0, len(entries))
+	for projectName, data := range entries {
+		var budget ProjectBudget
+		if err := json.Unmarshal([]byte(data), &budget); err != nil {
+			logger.Warn("Failed to unmarshal project budget", "project", projectName, "error", err)
+			continue
+		}
+		if teamName != "" && budget.Team != teamName {
+			continue
+		}
+		budgets = append(budgets, &budget)
+	}
+
+	sort.Slice(budgets, func(i, j int) bool {
+		return budgets[i].Project < budgets[j].Project
+	})
+	return budgets, nil
+}
+
+// SetProjectBudget creates or replaces the budget of a project and checks it right away, so
+// raising the budget of a suspended project resumes it without waiting for the next check
+func (s *BillingService) SetProjectBudget(ctx context.Context, projectName string, budget *ProjectBudget, operator string) (*ProjectBudget, error) {
+	logger.Info("Setting project budget", "project", projectName, "monthlyAmount", budget.MonthlyAmount, "hardStop", budget.HardStop)
+
+	project, err := s.projectSvc.Get(ctx, projectName)
+	if err != nil {
+		return nil, err
+	}
+	if project.Team == "" {
+		return nil, fmt.Errorf("project %s does not belong to a team", projectName)
+	}
+
+	budget.Project = projectName
+	budget.Team = project.Team
+	budget.UpdatedAt = time.Now()
+	budget.UpdatedBy = operator
+
+	err = s.store.Modify(ctx, ProjectBudgetsConfigMap, func(entries map[string]string) error {
+		updated := *budget
+		if data, ok := entries[projectName]; ok {
+			var stored ProjectBudget
+			if err := json.Unmarshal([]byte(data), &stored); err == nil {
+				updated.keepTracking(&stored)
+			}
+		}
+		raw, err := json.Marshal(&updated)
+		if err != nil {
+			return fmt.Errorf("failed to marshal project budget: %w", err)
+		}
+		entries[projectName] = string(raw)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	config, _ := s.GetConfig(ctx)
+	stored, err := s.GetProjectBudget(ctx, projectName)
+	if err != nil || stored == nil {
+		return budget, err
+	}
+	if err := s.checkProjectBudget(ctx, config, stored); err != nil {
+		logger.Warn("Failed to check project budget", "project", projectName, "error", err)
+	}
+	return stored, nil
+}
+
+// DeleteProjectBudget removes the budget of a project, resuming the project first if its
+// budget had suspended it. The budget is kept if the project cannot be resumed.
+func (s *BillingService) DeleteProjectBudget(ctx context.Context, projectName, operator string) error {
+	logger.Info("Deleting project budget", "project", projectName, "operator", operator)
+
+	budget, err := s.GetProjectBudget(ctx, projectName)
+	if err != nil {
+		return err
+	}
+
+	if budget != nil && budget.Suspended {
+		if _, err := s.resumeBudgetProject(ctx, budget, operator); err != nil {
+			return err
+		}
+	}
+
+	return s.store.Modify(ctx, ProjectBudgetsConfigMap, func(entries map[string]string) error {
+		delete(entries, projectName)
+		return nil
+	})
+}
+
+// CheckProjectBudgets updates the month-to-date spend of every budgeted project, warns team
+// owners as projects approach or exceed their budgets, and suspends or resumes hard-stop
+// projects. Only the project is suspended; the rest of its team keeps running.
+func (s *BillingService) CheckProjectBudgets(ctx context.Context) error {
+	budgets, err := s.ListProjectBudgets(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	config, _ := s.GetConfig(ctx)
+	for _, budget := range budgets {
+		if err := s.checkProjectBudget(ctx, config, budget); err != nil {
+			logger.Error("Failed to check project budget", "project", budget.Project, "error", err)
+		}
+	}
+	return nil
+}
+
+// checkProjectBudget compares a project's month-to-date spend with its budget and acts on it.
+// The budget is updated in place and saved.
+func (s *BillingService) checkProjectBudget(ctx context.Context, config *BillingConfig, budget *ProjectBudget) error {
+	now := time.Now()
+	bill, err := s.GetProjectBill(ctx, budget.Project, "month")
+	if err != nil {
+		return fmt.Errorf("failed to get project bill: %w", err)
+	}
+
+	team, err := s.tenantSvc.Get(ctx, budget.Team)
+	if err != nil {
+		return fmt.Errorf("failed to get team: %w", err)
+	}
+
+	// Notices are sent once per month
+	if month := now.UTC().Format("2006-01"); budget.Month != month {
+		budget.Month = month
+		budget.Warned = false
+		budget.Exceeded = false
+	}
+	budget.Spent = bill.TotalCost
+	budget.CheckedAt = &now
+
+	exceeded := budget.Spent >= budget.MonthlyAmount
+	warnAt := budget.MonthlyAmount * budget.WarnPercent / 100
+	if budget.WarnPercent > 0 && budget.Spent >= warnAt && !exceeded && !budget.Warned {
+		budget.Warned = true
+		if err := s.alertSvc.NotifyProjectBudget(ctx, budget, team.Owners); err != nil {
+			logger.Error("Failed to send project budget warning", "project", budget.Project, "error", err)
+		}
+	}
+	if exceeded && !budget.Exceeded {
+		budget.Exceeded = true
+		budget.Warned = true
+		if err := s.alertSvc.NotifyProjectBudget(ctx, budget, team.Owners); err != nil {
+			logger.Error("Failed to send project budget notice", "project", budget.Project, "error", err)
+		}
+	}
+
+	switch {
+	case budget.HardStop && exceeded:
+		// Suspended again on every check, stopping anything restarted since
+		_, stopped, err := s.suspendNamespace(ctx, config, budget.Team, budget.Project)
+		if err != nil {
+			logger.Error("Failed to suspend project over budget", "project", budget.Project, "error", err)
+			break
+		}
+		first := !budget.Suspended
+		if first || stopped > 0 {
+			s.auditSvc.LogAction(ctx, "system", "suspend", "project", budget.Project, map[string]interface{}{
+				"reason":  "budget",
+				"spent":   budget.Spent,
+				"budget":  budget.MonthlyAmount,
+				"stopped": stopped,
+			})
+		}
+		if !first {
+			break
+		}
+		budget.Suspended = true
+		budget.SuspendedAt = &now
+		if err := s.alertSvc.NotifyProjectSuspended(ctx, budget, team.Owners, stopped); err != nil {
+			logger.Error("Failed to send project suspension notice", "project", budget.Project, "error", err)
+		}
+
+	case budget.Suspended && (!budget.HardStop || !exceeded):
+		// A project that failed to resume stays marked suspended and is retried on the next check
+		resumed, err := s.resumeBudgetProject(ctx, budget, "system")
+		if err != nil {
+			logger.Error("Failed to resume project within budget", "project", budget.Project, "error", err)
+		} else if resumed {
+			budget.Suspended = false
+			budget.SuspendedAt = nil
+		}
+	}
+
+	return s.saveProjectBudgetTracking(ctx, budget)
+}
+
+// resumeBudgetProject restores a project its budget had suspended and reports whether it did.
+// A suspended team's projects stay stopped until the team itself is resumed.
+func (s *BillingService) resumeBudgetProject(ctx context.Context, budget *ProjectBudget, operator string) (bool, error) {
+	team, err := s.tenantSvc.Get(ctx, budget.Team)
+	if err != nil {
+		return false, fmt.Errorf("failed to get team: %w", err)
+	}
+	if team.Suspended {
+		logger.Info("Keeping project suspended with its team", "project", budget.Project, "team", budget.Team)
+		return false, nil
+	}
+
+	if err := s.resumeNamespace(ctx, budget.Project); err != nil {
+		return false, fmt.Errorf("failed to resume project %s: %w", budget.Project, err)
+	}
+	s.auditSvc.LogAction(ctx, operator, "resume", "project", budget.Project, map[string]interface{}{
+		"reason": "budget",
+	})
+	if err := s.alertSvc.NotifyProjectResumed(ctx, budget, team.Owners); err != nil {
+		logger.Error("Failed to send project resume notice", "project", budget.Project, "error", err)
+	}
+	return true, nil
+}
+
+// saveProjectBudgetTracking stores the tracking state of a budget, keeping any settings
+// changed since it was loaded. A budget deleted meanwhile stays deleted.
+func (s *BillingService) saveProjectBudgetTracking(ctx context.Context, budget *ProjectBudget) error {
+	return s.store.Modify(ctx, ProjectBudgetsConfigMap, func(entries map[string]string) error {
+		data, ok := entries[budget.Project]
+		if !ok {
+			return nil
+		}
+		var stored ProjectBudget
+		if err := json.Unmarshal([]byte(data), &stored); err != nil {
+			return fmt.Errorf("failed to parse project budget: %w", err)
+		}
+		stored.keepTracking(budget)
+		raw, err := json.Marshal(&stored)
+		if err != nil {
+			return fmt.Errorf("failed to marshal project budget: %w", err)
+		}
+		entries[budget.Project] = string(raw)
+		return nil
+	})
+}
+
+// budgetSuspendedProjects returns the projects of a team that their budgets keep suspended
+func (s *BillingService) budgetSuspendedProjects(ctx context.Context, teamName string) map[string]bool {
+	budgets, err := s.ListProjectBudgets(ctx, teamName)
+	if err != nil {
+		logger.Warn("Failed to load project budgets", "team", teamName, "error", err)
+		return nil
+	}
+
+	suspended := make(map[string]bool)
+	for _, budget := range budgets {
+		if budget.Suspended {
+			suspended[budget.Project] = true
+		}
+	}
+	return suspended
+}
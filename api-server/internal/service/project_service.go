@@ -462,7 +462,7 @@ func (s *ProjectService) GetProjectUsage(ctx context.Context, namespace string,
 	}
 
 	for _, cfg := range resourceConfigs {
-		if !cfg.Enabled {
+		if !cfg.Enabled || IsBillingOnlyResource(cfg.Name) {
 			continue
 		}
 
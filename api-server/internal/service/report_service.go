@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/csv"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/bison/api-server/internal/opencost"
@@ -20,7 +21,14 @@ type Report struct {
 	TotalCost      float64            `json:"totalCost"`
 	CostByDay      []DailyCost        `json:"costByDay,omitempty"`
 	CostByResource map[string]float64 `json:"costByResource"`
+	CostByBand     map[string]float64 `json:"costByBand,omitempty"`   // Cost by time-of-use band
+	Discount       float64            `json:"discount,omitempty"`     // Savings from the team pricing policy
+	OverheadCost   float64            `json:"overheadCost,omitempty"` // Share of idle and shared-namespace cost
 	UsageSummary   *UsageData         `json:"usageSummary"`
+
+	// Balance adjustments (refunds, credits, manual charges, corrections) in the window
+	Adjustments   []*RechargeRecord `json:"adjustments,omitempty"`
+	NetAdjustment float64           `json:"netAdjustment,omitempty"` // Sum of adjustment amounts
 }
 
 // DailyCost represents cost for a single day
@@ -67,6 +75,7 @@ type ReportService struct {
 	tenantSvc      *TenantService
 	projectSvc     *ProjectService
 	billingSvc     *BillingService
+	balanceSvc     *BalanceService
 }
 
 // NewReportService creates a new ReportService
@@ -75,12 +84,14 @@ func NewReportService(
 	tenantSvc *TenantService,
 	projectSvc *ProjectService,
 	billingSvc *BillingService,
+	balanceSvc *BalanceService,
 ) *ReportService {
 	return &ReportService{
 		opencostClient: opencostClient,
 		tenantSvc:      tenantSvc,
 		projectSvc:     projectSvc,
 		billingSvc:     billingSvc,
+		balanceSvc:     balanceSvc,
 	}
 }
 
@@ -104,9 +115,24 @@ func (s *ReportService) GenerateTeamReport(ctx context.Context, teamName, window
 		GeneratedAt:    time.Now(),
 		TotalCost:      bill.TotalCost,
 		CostByResource: bill.ResourceCosts,
+		CostByBand:     bill.BandCosts,
+		Discount:       bill.Discount,
+		OverheadCost:   bill.OverheadCost,
 		UsageSummary:   bill.UsageDetails,
 	}
 
+	start, end, err := parseWindow(window, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	report.Adjustments, err = s.balanceSvc.GetAdjustments(ctx, teamName, start, end)
+	if err != nil {
+		return nil, err
+	}
+	for _, adj := range report.Adjustments {
+		report.NetAdjustment += adj.Amount
+	}
+
 	return report, nil
 }
 
@@ -130,6 +156,8 @@ func (s *ReportService) GenerateProjectReport(ctx context.Context, projectName,
 		GeneratedAt:    time.Now(),
 		TotalCost:      bill.TotalCost,
 		CostByResource: bill.ResourceCosts,
+		CostByBand:     bill.BandCosts,
+		Discount:       bill.Discount,
 		UsageSummary:   bill.UsageDetails,
 	}
 
@@ -256,10 +284,30 @@ func (s *ReportService) teamReportToCSV(writer *csv.Writer, report *Report) ([]b
 		csvWriter.Write([]string{"CPU", fmt.Sprintf("%.2f core-hours", report.UsageSummary.CPUCoreHours), fmt.Sprintf("%.2f", report.UsageSummary.CPUCost)})
 		csvWriter.Write([]string{"Memory", fmt.Sprintf("%.2f GB-hours", report.UsageSummary.RAMGBHours), fmt.Sprintf("%.2f", report.UsageSummary.RAMCost)})
 		csvWriter.Write([]string{"GPU", fmt.Sprintf("%.2f hours", report.UsageSummary.GPUHours), fmt.Sprintf("%.2f", report.UsageSummary.GPUCost)})
+		csvWriter.Write([]string{"Storage", fmt.Sprintf("%.2f GB-hours", report.UsageSummary.PVGBHours), fmt.Sprintf("%.2f", report.UsageSummary.PVCost)})
+		csvWriter.Write([]string{"Network", fmt.Sprintf("%.2f GB", report.UsageSummary.NetworkGB), fmt.Sprintf("%.2f", report.UsageSummary.NetworkCost)})
+		csvWriter.Write([]string{"Load Balancer", "", fmt.Sprintf("%.2f", report.UsageSummary.LoadBalancerCost)})
 	}
 	csvWriter.Write([]string{})
+	writeCostBreakdownCSV(csvWriter, "Billed Resource", report.CostByResource)
+	writeCostBreakdownCSV(csvWriter, "Time Band", report.CostByBand)
+	if report.Discount != 0 {
+		csvWriter.Write([]string{"Contract Discount", fmt.Sprintf("%.2f", report.Discount)})
+	}
+	if report.OverheadCost != 0 {
+		csvWriter.Write([]string{"Shared Overhead", fmt.Sprintf("%.2f", report.OverheadCost)})
+	}
 	csvWriter.Write([]string{"Total Cost", fmt.Sprintf("%.2f", report.TotalCost)})
 
+	if len(report.Adjustments) > 0 {
+		csvWriter.Write([]string{})
+		csvWriter.Write([]string{"Adjustment", "Time", "Amount", "Reference", "Operator", "Reason"})
+		for _, adj := range report.Adjustments {
+			csvWriter.Write([]string{adj.Type, adj.Timestamp.Format(time.RFC3339), fmt.Sprintf("%.2f", adj.Amount), adj.RefID, adj.Operator, adj.Reason})
+		}
+		csvWriter.Write([]string{"Net Adjustment", fmt.Sprintf("%.2f", report.NetAdjustment)})
+	}
+
 	csvWriter.Flush()
 	return buf.Bytes(), csvWriter.Error()
 }
@@ -280,8 +328,16 @@ func (s *ReportService) projectReportToCSV(writer *csv.Writer, report *Report) (
 		csvWriter.Write([]string{"CPU", fmt.Sprintf("%.2f core-hours", report.UsageSummary.CPUCoreHours), fmt.Sprintf("%.2f", report.UsageSummary.CPUCost)})
 		csvWriter.Write([]string{"Memory", fmt.Sprintf("%.2f GB-hours", report.UsageSummary.RAMGBHours), fmt.Sprintf("%.2f", report.UsageSummary.RAMCost)})
 		csvWriter.Write([]string{"GPU", fmt.Sprintf("%.2f hours", report.UsageSummary.GPUHours), fmt.Sprintf("%.2f", report.UsageSummary.GPUCost)})
+		csvWriter.Write([]string{"Storage", fmt.Sprintf("%.2f GB-hours", report.UsageSummary.PVGBHours), fmt.Sprintf("%.2f", report.UsageSummary.PVCost)})
+		csvWriter.Write([]string{"Network", fmt.Sprintf("%.2f GB", report.UsageSummary.NetworkGB), fmt.Sprintf("%.2f", report.UsageSummary.NetworkCost)})
+		csvWriter.Write([]string{"Load Balancer", "", fmt.Sprintf("%.2f", report.UsageSummary.LoadBalancerCost)})
 	}
 	csvWriter.Write([]string{})
+	writeCostBreakdownCSV(csvWriter, "Billed Resource", report.CostByResource)
+	writeCostBreakdownCSV(csvWriter, "Time Band", report.CostByBand)
+	if report.Discount != 0 {
+		csvWriter.Write([]string{"Contract Discount", fmt.Sprintf("%.2f", report.Discount)})
+	}
 	csvWriter.Write([]string{"Total Cost", fmt.Sprintf("%.2f", report.TotalCost)})
 
 	csvWriter.Flush()
@@ -320,6 +376,25 @@ func (s *ReportService) summaryReportToCSV(writer *csv.Writer, report *SummaryRe
 	return buf.Bytes(), csvWriter.Error()
 }
 
+// writeCostBreakdownCSV writes a cost breakdown section sorted by key, if non-empty
+func writeCostBreakdownCSV(csvWriter *csv.Writer, title string, costs map[string]float64) {
+	if len(costs) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(costs))
+	for key := range costs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	csvWriter.Write([]string{title, "Cost"})
+	for _, key := range keys {
+		csvWriter.Write([]string{key, fmt.Sprintf("%.2f", costs[key])})
+	}
+	csvWriter.Write([]string{})
+}
+
 func sortTeamCostRank(ranks []TeamCostRank) {
 	for i := 0; i < len(ranks); i++ {
 		for j := i + 1; j < len(ranks); j++ {
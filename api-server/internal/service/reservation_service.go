@@ -0,0 +1,321 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/bison/api-server/internal/k8s"
+	"github.com/bison/api-server/internal/store"
+	"github.com/bison/api-server/pkg/logger"
+)
+
+// ReservationConfigMapPrefix names the per-day collections of reserved nodes, e.g. bison-reservations-20261016.
+// Each collection maps the UTC hour ("00"-"23") to the nodes each exclusive team held in that hour.
+const ReservationConfigMapPrefix = "bison-reservations-"
+
+// ReservationSampleInterval is how often exclusive pool labels are sampled. A node counts
+// as reserved for a whole hour if any sample in the hour saw it in a team's pool.
+const ReservationSampleInterval = 5 * time.Minute
+
+// ReservedNode is a node that carried a team's exclusive pool label during an hour
+type ReservedNode struct {
+	Node        string             `json:"node"`
+	Allocatable map[string]float64 `json:"allocatable"`        // By resource name: CPU cores, memory bytes, extended resource units
+	Products    map[string]string  `json:"products,omitempty"` // Hardware model of extended resources, by resource name
+}
+
+// ReservedHour is the nodes each team held in one hour
+type ReservedHour struct {
+	Hour  time.Time                  `json:"hour"`
+	Teams map[string][]*ReservedNode `json:"teams"`
+}
+
+// ReservationService records which nodes carried each team's exclusive pool label, hour by
+// hour, so reservation-billed teams can be charged per node-hour
+type ReservationService struct {
+	k8sClient         *k8s.Client
+	store             store.Store
+	resourceConfigSvc *ResourceConfigService
+	retentionDays     int
+
+	prunedDay string // Last day expired collections were pruned on
+}
+
+// NewReservationService creates a new ReservationService
+func NewReservationService(k8sClient *k8s.Client, st store.Store, resourceConfigSvc *ResourceConfigService, retentionDays int) *ReservationService {
+	return &ReservationService{
+		k8sClient:         k8sClient,
+		store:             st,
+		resourceConfigSvc: resourceConfigSvc,
+		retentionDays:     retentionDays,
+	}
+}
+
+// ReservationCollection returns the collection holding a UTC day's reserved nodes
+func ReservationCollection(day time.Time) string {
+	return ReservationConfigMapPrefix + day.UTC().Format("20060102")
+}
+
+// ReservationCollections lists the collections that may hold reserved nodes within the retention period
+func ReservationCollections(now time.Time, retentionDays int) []string {
+	collections := make([]string, 0, retentionDays+1)
+	for i := retentionDays; i >= 0; i-- {
+		collections = append(collections, ReservationCollection(now.AddDate(0, 0, -i)))
+	}
+	return collections
+}
+
+// Sample records the nodes currently in each team's exclusive pool for the current hour.
+// Every API server replica may sample; samples of the same hour are merged.
+func (s *ReservationService) Sample(ctx context.Context) error {
+	hour := time.Now().UTC().Truncate(time.Hour)
+
+	nodes, err := s.k8sClient.ListNodes(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	resources := s.reservedResources(ctx)
+	sample := make(map[string][]*ReservedNode)
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		teamName := ParseExclusivePoolLabel(node.Labels[LabelPoolKey])
+		if teamName == "" {
+			continue
+		}
+		sample[teamName] = append(sample[teamName], reservedNode(node, resources))
+	}
+
+	key := hour.Format("15")
+	err = s.store.Modify(ctx, ReservationCollection(hour), func(data map[string]string) error {
+		stored := make(map[string][]*ReservedNode)
+		if raw, ok := data[key]; ok {
+			if err := json.Unmarshal([]byte(raw), &stored); err != nil {
+				return fmt.Errorf("failed to parse reserved nodes: %w", err)
+			}
+		}
+
+		merged := mergeReservedNodes(stored, sample)
+		if len(merged) == 0 {
+			return nil
+		}
+		raw, err := json.Marshal(merged)
+		if err != nil {
+			return fmt.Errorf("failed to marshal reserved nodes: %w", err)
+		}
+		data[key] = string(raw)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Debug("Sampled reserved nodes", "hour", hour, "teams", len(sample))
+	s.pruneExpired(ctx, hour)
+	return nil
+}
+
+// ReservedHours returns the nodes each team held in the hours starting within [start, end), oldest first
+func (s *ReservationService) ReservedHours(ctx context.Context, start, end time.Time) ([]ReservedHour, error) {
+	var hours []ReservedHour
+	loaded := make(map[string]map[string]string)
+
+	first := start.UTC().Truncate(time.Hour)
+	if first.Before(start) {
+		first = first.Add(time.Hour)
+	}
+	for hour := first; hour.Before(end); hour = hour.Add(time.Hour) {
+		collection := ReservationCollection(hour)
+		data, ok := loaded[collection]
+		if !ok {
+			var err error
+			if data, err = s.store.Load(ctx, collection); err != nil {
+				return nil, fmt.Errorf("failed to load reserved nodes: %w", err)
+			}
+			loaded[collection] = data
+		}
+
+		raw, ok := data[hour.Format("15")]
+		if !ok {
+			continue
+		}
+		teams := make(map[string][]*ReservedNode)
+		if err := json.Unmarshal([]byte(raw), &teams); err != nil {
+			return nil, fmt.Errorf("failed to parse reserved nodes for %s: %w", hour.Format(time.RFC3339), err)
+		}
+		hours = append(hours, ReservedHour{Hour: hour, Teams: teams})
+	}
+
+	return hours, nil
+}
+
+// reservedResources returns the resources worth recording besides CPU and memory
+func (s *ReservationService) reservedResources(ctx context.Context) []ResourceDefinition {
+	configs, err := s.resourceConfigSvc.GetEnabledResourceConfigs(ctx)
+	if err != nil {
+		logger.Warn("Failed to load resource configs, recording CPU and memory only", "error", err)
+		return nil
+	}
+
+	var resources []ResourceDefinition
+	for _, rc := range configs {
+		if rc.Name == "cpu" || rc.Name == "memory" || IsBillingOnlyResource(rc.Name) {
+			continue
+		}
+		resources = append(resources, rc)
+	}
+	return resources
+}
+
+// reservedNode snapshots a node's allocatable resources and hardware models
+func reservedNode(node *corev1.Node, resources []ResourceDefinition) *ReservedNode {
+	reserved := &ReservedNode{
+		Node:        node.Name,
+		Allocatable: make(map[string]float64),
+	}
+	if quantity, ok := node.Status.Allocatable[corev1.ResourceCPU]; ok {
+		reserved.Allocatable["cpu"] = quantity.AsApproximateFloat64()
+	}
+	if quantity, ok := node.Status.Allocatable[corev1.ResourceMemory]; ok {
+		reserved.Allocatable["memory"] = quantity.AsApproximateFloat64()
+	}
+	for _, rc := range resources {
+		quantity, ok := node.Status.Allocatable[corev1.ResourceName(rc.Name)]
+		if !ok || quantity.IsZero() {
+			continue
+		}
+		reserved.Allocatable[rc.Name] = quantity.AsApproximateFloat64()
+		if product := node.Labels[rc.GetProductLabel()]; product != "" {
+			if reserved.Products == nil {
+				reserved.Products = make(map[string]string)
+			}
+			reserved.Products[rc.Name] = product
+		}
+	}
+	return reserved
+}
+
+// mergeReservedNodes adds a sample to an hour's stored nodes. A node seen again keeps its
+// latest snapshot.
+func mergeReservedNodes(stored, sample map[string][]*ReservedNode) map[string][]*ReservedNode {
+	for teamName, nodes := range sample {
+		for _, node := range nodes {
+			replaced := false
+			for i, existing := range stored[teamName] {
+				if existing.Node == node.Node {
+					stored[teamName][i] = node
+					replaced = true
+					break
+				}
+			}
+			if !replaced {
+				stored[teamName] = append(stored[teamName], node)
+			}
+		}
+	}
+	return stored
+}
+
+// pruneExpired deletes collections that fell out of the retention period, once per day
+func (s *ReservationService) pruneExpired(ctx context.Context, now time.Time) {
+	day := now.Format("20060102")
+	if day == s.prunedDay {
+		return
+	}
+	s.prunedDay = day
+
+	pruneDailyCollections(ctx, s.store, ReservationConfigMapPrefix, now.AddDate(0, 0, -s.retentionDays))
+}
+
+// ReservationCost is the price of the node-hours a team held
+type ReservationCost struct {
+	NodeHours     float64                     `json:"nodeHours"`
+	Cost          float64                     `json:"cost"`
+	Discount      float64                     `json:"discount,omitempty"`  // Savings from the team pricing policy
+	ResourceCosts map[string]float64          `json:"resourceCosts"`       // By resource, keyed like usage costs
+	Nodes         map[string]*NodeReservation `json:"nodes"`               // By node
+	BandCosts     map[string]float64          `json:"bandCosts,omitempty"` // By time-of-use band
+}
+
+// NodeReservation is the hours a team held one node and their cost
+type NodeReservation struct {
+	Hours float64 `json:"hours"`
+	Cost  float64 `json:"cost"`
+}
+
+// priceReservation prices the node-hours a team held in the given hours. Each node-hour
+// costs the node's allocatable resources at their unit prices, with the team's pricing
+// policy and the time-of-use band of the hour applied.
+func (s *BillingService) priceReservation(config *BillingConfig, pricing *resourcePricing, policy *TeamPricingPolicy, hours []ReservedHour, teamName string) *ReservationCost {
+	reservation := &ReservationCost{
+		ResourceCosts: make(map[string]float64),
+		Nodes:         make(map[string]*NodeReservation),
+		BandCosts:     make(map[string]float64),
+	}
+
+	for _, hour := range hours {
+		band, multiplier := "", 1.0
+		if config != nil && config.Enabled && config.TimeOfUse != nil && config.TimeOfUse.Enabled {
+			band, multiplier = config.TimeOfUse.BandAt(hour.Hour)
+		}
+
+		for _, node := range hour.Teams[teamName] {
+			reservation.NodeHours++
+
+			var charged float64
+			for resource, cost := range nodeHourCosts(pricing, policy, node) {
+				cost *= multiplier
+				reservation.ResourceCosts[resource] += cost
+				charged += cost
+			}
+			if reservation.Nodes[node.Node] == nil {
+				reservation.Nodes[node.Node] = &NodeReservation{}
+			}
+			reservation.Nodes[node.Node].Hours++
+			reservation.Nodes[node.Node].Cost += charged
+			reservation.Cost += charged
+			if band != "" {
+				reservation.BandCosts[band] += charged
+			}
+
+			if policy != nil {
+				reservation.Discount += sumCosts(nodeHourCosts(pricing, nil, node))*multiplier - charged
+			}
+		}
+	}
+
+	return reservation
+}
+
+// nodeHourCosts returns the cost of holding a node for an hour, broken down by resource like
+// calculateCost. Accelerators are priced by the model recorded with the node.
+func nodeHourCosts(pricing *resourcePricing, policy *TeamPricingPolicy, node *ReservedNode) map[string]float64 {
+	costs := make(map[string]float64)
+	costs["cpu"] = componentCost(policy, "cpu", "", node.Allocatable["cpu"], pricing.cpuPrice, 0)
+	costs["memory"] = componentCost(policy, "memory", "", node.Allocatable["memory"]/bytesPerGB, pricing.memoryPrice, 0)
+
+	for i := range pricing.extended {
+		rc := &pricing.extended[i]
+		quantity := node.Allocatable[rc.Name]
+		if quantity == 0 {
+			continue
+		}
+
+		product := node.Products[rc.Name]
+		price := rc.Price
+		key := rc.Name
+		if product != "" {
+			key = rc.Name + ":" + product
+			if productPrice, ok := rc.ProductPrices[product]; ok {
+				price = productPrice
+			}
+		}
+		costs[key] += componentCost(policy, rc.Name, product, quantity, price, 0)
+	}
+
+	return costs
+}
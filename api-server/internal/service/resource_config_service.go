@@ -42,6 +42,35 @@ type ResourceDefinition struct {
 	SortOrder   int              `json:"sortOrder"`   // Sort order (lower = first)
 	ShowInQuota bool             `json:"showInQuota"` // Whether to show in quota settings
 	Price       float64          `json:"price"`       // Price per unit per hour
+	// Variant pricing: accelerator model (value of ProductLabel on the node) or, for
+	// persistent storage, StorageClass name
+	ProductLabel  string             `json:"productLabel,omitempty"`  // Node label holding the model, defaults to <name>.product
+	ProductPrices map[string]float64 `json:"productPrices,omitempty"` // Price per unit per hour by variant, overrides Price
+}
+
+// Billing-only resources. They are priced from OpenCost allocations rather than node
+// capacity, so they never appear in quotas or capacity views.
+const (
+	ResourcePersistentStorage = "bison.io/persistent-storage" // Priced per GB·hour, optionally per StorageClass
+	ResourceNetworkEgress     = "bison.io/network-egress"     // Priced per GB sent
+	ResourceLoadBalancer      = "bison.io/load-balancer"      // Priced per load balancer hour
+)
+
+// IsBillingOnlyResource reports whether a resource is only used for billing
+func IsBillingOnlyResource(name string) bool {
+	switch name {
+	case ResourcePersistentStorage, ResourceNetworkEgress, ResourceLoadBalancer:
+		return true
+	}
+	return false
+}
+
+// GetProductLabel returns the node label that identifies the accelerator model
+func (r *ResourceDefinition) GetProductLabel() string {
+	if r.ProductLabel != "" {
+		return r.ProductLabel
+	}
+	return r.Name + ".product"
 }
 
 // DiscoveredResource represents a resource discovered from cluster
@@ -195,7 +224,7 @@ func (s *ResourceConfigService) GetQuotaResourceConfigs(ctx context.Context) ([]
 
 	var quotaResources []ResourceDefinition
 	for _, cfg := range configs {
-		if cfg.Enabled && cfg.ShowInQuota {
+		if cfg.Enabled && cfg.ShowInQuota && !IsBillingOnlyResource(cfg.Name) {
 			quotaResources = append(quotaResources, cfg)
 		}
 	}
@@ -88,6 +88,10 @@ func (s *ResourceService) GetClusterResources(ctx context.Context) ([]ResourceTy
 	// Build result with converted values
 	var resources []ResourceType
 	for _, cfg := range configs {
+		if IsBillingOnlyResource(cfg.Name) {
+			continue
+		}
+
 		divisor := cfg.Divisor
 		if divisor <= 0 {
 			divisor = 1
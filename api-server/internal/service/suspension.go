@@ -0,0 +1,796 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/bison/api-server/pkg/logger"
+)
+
+const (
+	SuspensionsConfigMap = "bison-suspensions"
+
+	// legacyReplicasAnnotation held the replica count of workloads suspended by older versions
+	legacyReplicasAnnotation = "bison.io/original-replicas"
+
+	// FreezeQuotaName is the ResourceQuota that blocks new pods in a frozen namespace. It is
+	// separate from the Capsule tenant quota, which team updates rewrite.
+	FreezeQuotaName = "bison-suspension-freeze"
+
+	// SuspensionExemptKey set to "true" as a label or annotation on a workload or namespace
+	// keeps it running when its team is suspended
+	SuspensionExemptKey = "bison.io/suspension-exempt"
+)
+
+// Suspension strategies
+const (
+	SuspensionStrategyScaleDown    = "scale-down"     // Stop every workload
+	SuspensionStrategyFreeze       = "freeze"         // Block new pods, let running pods finish
+	SuspensionStrategyEvictGPUOnly = "evict-gpu-only" // Stop only workloads requesting accelerators
+)
+
+// SuspensionConfig controls what suspending a team stops
+type SuspensionConfig struct {
+	// Strategy is scale-down (default), freeze or evict-gpu-only
+	Strategy string `json:"strategy,omitempty"`
+
+	// Operator-managed workload kinds to pause; nil uses DefaultSuspendableResources
+	CustomResources []SuspendableResource `json:"customResources"`
+
+	// Workloads or namespaces never stopped by suspension
+	Exemptions []SuspensionExemption `json:"exemptions,omitempty"`
+}
+
+// SuspensionExemption exempts a workload, or a whole namespace when Name is empty
+type SuspensionExemption struct {
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind,omitempty"` // e.g. Deployment; empty matches any kind
+	Name      string `json:"name,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// Validate checks the suspension settings
+func (c *SuspensionConfig) Validate() error {
+	switch c.Strategy {
+	case "", SuspensionStrategyScaleDown, SuspensionStrategyFreeze, SuspensionStrategyEvictGPUOnly:
+	default:
+		return fmt.Errorf("unknown suspension strategy %q", c.Strategy)
+	}
+	for _, r := range c.CustomResources {
+		if err := r.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, e := range c.Exemptions {
+		if e.Namespace == "" {
+			return fmt.Errorf("suspension exemption needs a namespace")
+		}
+		if e.Kind != "" && e.Name == "" {
+			return fmt.Errorf("suspension exemption for kind %s in %s needs a name", e.Kind, e.Namespace)
+		}
+	}
+	return nil
+}
+
+// SuspendableResource is a custom workload kind paused through the dynamic client, by setting
+// a boolean field to true or an integer field to 0. Paths are dot-separated, e.g. spec.suspend.
+type SuspendableResource struct {
+	Group        string `json:"group"`
+	Version      string `json:"version"`
+	Resource     string `json:"resource"`               // Plural resource name, e.g. pytorchjobs
+	Kind         string `json:"kind"`                   // e.g. PyTorchJob
+	SuspendPath  string `json:"suspendPath,omitempty"`  // Boolean field set to true
+	ReplicasPath string `json:"replicasPath,omitempty"` // Integer field set to 0
+}
+
+// Validate checks a suspendable resource definition
+func (r *SuspendableResource) Validate() error {
+	if r.Version == "" || r.Resource == "" {
+		return fmt.Errorf("custom resource needs a version and resource")
+	}
+	if (r.SuspendPath == "") == (r.ReplicasPath == "") {
+		return fmt.Errorf("custom resource %s needs exactly one of suspendPath or replicasPath", r.Resource)
+	}
+	return nil
+}
+
+func (r *SuspendableResource) gvr() schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: r.Group, Version: r.Version, Resource: r.Resource}
+}
+
+// DefaultSuspendableResources are the training and serving operators paused out of the box.
+// Kinds whose CRD is not installed are skipped.
+var DefaultSuspendableResources = []SuspendableResource{
+	{Group: "kubeflow.org", Version: "v1", Resource: "pytorchjobs", Kind: "PyTorchJob", SuspendPath: "spec.runPolicy.suspend"},
+	{Group: "kubeflow.org", Version: "v1", Resource: "tfjobs", Kind: "TFJob", SuspendPath: "spec.runPolicy.suspend"},
+	{Group: "kubeflow.org", Version: "v2beta1", Resource: "mpijobs", Kind: "MPIJob", SuspendPath: "spec.runPolicy.suspend"},
+	{Group: "ray.io", Version: "v1", Resource: "rayjobs", Kind: "RayJob", SuspendPath: "spec.suspend"},
+	{Group: "ray.io", Version: "v1", Resource: "rayclusters", Kind: "RayCluster", SuspendPath: "spec.suspend"},
+}
+
+// suspendableResources returns the custom workload kinds to pause
+func suspendableResources(config *BillingConfig) []SuspendableResource {
+	if config != nil && config.Suspension != nil && config.Suspension.CustomResources != nil {
+		return config.Suspension.CustomResources
+	}
+	return DefaultSuspendableResources
+}
+
+// suspensionStrategy returns the configured suspension strategy
+func suspensionStrategy(config *BillingConfig) string {
+	if config != nil && config.Suspension != nil && config.Suspension.Strategy != "" {
+		return config.Suspension.Strategy
+	}
+	return SuspensionStrategyScaleDown
+}
+
+// Suspension actions
+const (
+	SuspendActionScale   = "scale"   // Replicas set to 0; Previous holds the replica count
+	SuspendActionSuspend = "suspend" // Suspend flag set to true; Previous holds the prior flag
+	SuspendActionDelete  = "delete"  // Standalone pod deleted; not restored
+	SuspendActionFreeze  = "freeze"  // Freeze quota created; removed on resume
+)
+
+// SuspendedObject is one change made while suspending a namespace
+type SuspendedObject struct {
+	Kind     string `json:"kind"` // Deployment, StatefulSet, Job, CronJob, Pod, ResourceQuota or the custom kind
+	Name     string `json:"name"`
+	Action   string `json:"action"`
+	Previous string `json:"previous,omitempty"` // Value before suspension
+
+	// Custom resources only
+	Group    string `json:"group,omitempty"`
+	Version  string `json:"version,omitempty"`
+	Resource string `json:"resource,omitempty"`
+	Path     string `json:"path,omitempty"` // Field that was changed
+}
+
+// ExemptedObject is a workload or namespace left running by an exemption
+type ExemptedObject struct {
+	Kind   string `json:"kind"` // Workload kind, or Namespace
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// SuspensionRecord lists what was changed when a namespace was suspended, so resuming
+// restores each object to its prior state
+type SuspensionRecord struct {
+	Namespace   string            `json:"namespace"`
+	Team        string            `json:"team"`
+	Strategy    string            `json:"strategy"` // Strategy of the latest suspension
+	SuspendedAt time.Time         `json:"suspendedAt"`
+	Changes     []SuspendedObject `json:"changes"`
+	Exempted    []ExemptedObject  `json:"exempted,omitempty"` // Left running by the latest suspension
+}
+
+// recorded reports whether an object was already changed by an earlier suspension
+func (r *SuspensionRecord) recorded(kind, name string) bool {
+	for _, change := range r.Changes {
+		if change.Kind == kind && change.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SuspensionResult is the outcome of suspending a team
+type SuspensionResult struct {
+	Team       string              `json:"team"`
+	Stopped    int                 `json:"stopped"` // Objects stopped by this suspension
+	Namespaces []*SuspensionRecord `json:"namespaces"`
+}
+
+// suspensionExemptions decides which objects of a namespace a suspension leaves running
+type suspensionExemptions struct {
+	namespace string
+	list      []SuspensionExemption
+}
+
+func newSuspensionExemptions(config *BillingConfig, namespace string) *suspensionExemptions {
+	e := &suspensionExemptions{namespace: namespace}
+	if config != nil && config.Suspension != nil {
+		e.list = config.Suspension.Exemptions
+	}
+	return e
+}
+
+// reason returns why an object is exempt, or "" if it is not. Name and kind are empty for
+// the namespace itself.
+func (e *suspensionExemptions) reason(kind, name string, labels, annotations map[string]string) string {
+	subject := "workload"
+	if name == "" {
+		subject = "namespace"
+	}
+	if labels[SuspensionExemptKey] == "true" {
+		return subject + " label " + SuspensionExemptKey
+	}
+	if annotations[SuspensionExemptKey] == "true" {
+		return subject + " annotation " + SuspensionExemptKey
+	}
+	for _, exemption := range e.list {
+		if exemption.Namespace != e.namespace || exemption.Name != name || (exemption.Kind != "" && exemption.Kind != kind) {
+			continue
+		}
+		if exemption.Reason != "" {
+			return "exemption list: " + exemption.Reason
+		}
+		return "exemption list"
+	}
+	return ""
+}
+
+// exempt reports whether an object is exempt, noting it on the record if so
+func (e *suspensionExemptions) exempt(record *SuspensionRecord, kind, name string, labels, annotations map[string]string) bool {
+	reason := e.reason(kind, name, labels, annotations)
+	if reason == "" {
+		return false
+	}
+	record.Exempted = append(record.Exempted, ExemptedObject{Kind: kind, Name: name, Reason: reason})
+	return true
+}
+
+// GetSuspensionRecords returns what is currently suspended in a team's namespaces
+func (s *BillingService) GetSuspensionRecords(ctx context.Context, teamName string) ([]*SuspensionRecord, error) {
+	entries, err := s.store.Load(ctx, SuspensionsConfigMap)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*SuspensionRecord, 0)
+	for namespace, data := range entries {
+		var record SuspensionRecord
+		if err := json.Unmarshal([]byte(data), &record); err != nil {
+			logger.Warn("Skipping invalid suspension record", "namespace", namespace, "error", err)
+			continue
+		}
+		if record.Team == teamName {
+			records = append(records, &record)
+		}
+	}
+	return records, nil
+}
+
+func (s *BillingService) loadSuspensionRecord(ctx context.Context, namespace string) (*SuspensionRecord, error) {
+	entries, err := s.store.Load(ctx, SuspensionsConfigMap)
+	if err != nil {
+		return nil, err
+	}
+
+	data, ok := entries[namespace]
+	if !ok {
+		return nil, nil
+	}
+
+	var record SuspensionRecord
+	if err := json.Unmarshal([]byte(data), &record); err != nil {
+		return nil, fmt.Errorf("failed to parse suspension record: %w", err)
+	}
+	return &record, nil
+}
+
+// saveSuspensionRecord stores a record, or removes it once it holds neither changes to
+// restore nor exemptions
+func (s *BillingService) saveSuspensionRecord(ctx context.Context, record *SuspensionRecord) error {
+	var data []byte
+	if len(record.Changes) > 0 || len(record.Exempted) > 0 {
+		var err error
+		data, err = json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("failed to marshal suspension record: %w", err)
+		}
+	}
+
+	return s.store.Modify(ctx, SuspensionsConfigMap, func(entries map[string]string) error {
+		if data == nil {
+			delete(entries, record.Namespace)
+		} else {
+			entries[record.Namespace] = string(data)
+		}
+		return nil
+	})
+}
+
+// suspendNamespace applies the configured suspension strategy to a namespace, records each
+// change and returns how many objects it stopped. Suspending an already suspended namespace
+// stops anything restarted since, keeping the first recorded value of each object.
+func (s *BillingService) suspendNamespace(ctx context.Context, config *BillingConfig, teamName, namespace string) (*SuspensionRecord, int, error) {
+	record, err := s.loadSuspensionRecord(ctx, namespace)
+	if err != nil {
+		return nil, 0, err
+	}
+	if record == nil {
+		record = &SuspensionRecord{Namespace: namespace, Team: teamName, SuspendedAt: time.Now()}
+	}
+	recorded := len(record.Changes)
+	record.Strategy = suspensionStrategy(config)
+	record.Exempted = nil
+
+	exemptions := newSuspensionExemptions(config, namespace)
+	ns, err := s.k8sClient.GetNamespace(ctx, namespace)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get namespace: %w", err)
+	}
+	if reason := exemptions.reason("", "", ns.Labels, ns.Annotations); reason != "" {
+		logger.Info("Namespace exempt from suspension", "namespace", namespace, "reason", reason)
+		record.Exempted = []ExemptedObject{{Kind: "Namespace", Name: namespace, Reason: reason}}
+		return record, 0, s.saveSuspensionRecord(ctx, record)
+	}
+
+	switch record.Strategy {
+	case SuspensionStrategyFreeze:
+		err = s.freezeNamespace(ctx, namespace, exemptions, record)
+	case SuspensionStrategyEvictGPUOnly:
+		err = s.stopWorkloads(ctx, config, namespace, s.acceleratorFilter(ctx), exemptions, record)
+	default:
+		err = s.stopWorkloads(ctx, config, namespace, nil, exemptions, record)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	stopped := len(record.Changes) - recorded
+	if err := s.saveSuspensionRecord(ctx, record); err != nil {
+		return record, stopped, err
+	}
+	return record, stopped, nil
+}
+
+// freezeNamespace blocks new pods with a zero pod quota and suspends CronJobs, leaving
+// running pods to finish. The quota covers the whole namespace, so only exempt CronJobs
+// are left alone.
+func (s *BillingService) freezeNamespace(ctx context.Context, namespace string, exemptions *suspensionExemptions, record *SuspensionRecord) error {
+	quota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   FreezeQuotaName,
+			Labels: map[string]string{"bison.io/managed": "true"},
+		},
+		Spec: corev1.ResourceQuotaSpec{
+			Hard: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("0")},
+		},
+	}
+	if err := s.k8sClient.CreateResourceQuota(ctx, namespace, quota); err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create freeze quota: %w", err)
+	}
+	if !record.recorded("ResourceQuota", FreezeQuotaName) {
+		record.Changes = append(record.Changes, SuspendedObject{Kind: "ResourceQuota", Name: FreezeQuotaName, Action: SuspendActionFreeze})
+	}
+
+	// Jobs created by CronJobs could not start their pods anyway
+	return s.suspendCronJobs(ctx, namespace, nil, exemptions, record)
+}
+
+// stopWorkloads stops the workloads in a namespace selected by filter, except exempt ones
+func (s *BillingService) stopWorkloads(ctx context.Context, config *BillingConfig, namespace string, filter acceleratorFilter, exemptions *suspensionExemptions, record *SuspensionRecord) error {
+	// Scale down deployments
+	deployments, err := s.k8sClient.ListDeployments(ctx, namespace)
+	if err != nil {
+		return err
+	}
+	for _, deploy := range deployments.Items {
+		if deploy.Spec.Replicas == nil || *deploy.Spec.Replicas == 0 || !filter.podSpec(&deploy.Spec.Template.Spec) ||
+			exemptions.exempt(record, "Deployment", deploy.Name, deploy.Labels, deploy.Annotations) {
+			continue
+		}
+		previous := *deploy.Spec.Replicas
+		zero := int32(0)
+		deploy.Spec.Replicas = &zero
+		if err := s.k8sClient.UpdateDeployment(ctx, namespace, &deploy); err != nil {
+			logger.Error("Failed to scale down deployment", "namespace", namespace, "name", deploy.Name, "error", err)
+			continue
+		}
+		if !record.recorded("Deployment", deploy.Name) {
+			record.Changes = append(record.Changes, SuspendedObject{Kind: "Deployment", Name: deploy.Name, Action: SuspendActionScale, Previous: strconv.Itoa(int(previous))})
+		}
+	}
+
+	// Scale down statefulsets
+	statefulsets, err := s.k8sClient.ListStatefulSets(ctx, namespace)
+	if err != nil {
+		return err
+	}
+	for _, sts := range statefulsets.Items {
+		if sts.Spec.Replicas == nil || *sts.Spec.Replicas == 0 || !filter.podSpec(&sts.Spec.Template.Spec) ||
+			exemptions.exempt(record, "StatefulSet", sts.Name, sts.Labels, sts.Annotations) {
+			continue
+		}
+		previous := *sts.Spec.Replicas
+		zero := int32(0)
+		sts.Spec.Replicas = &zero
+		if err := s.k8sClient.UpdateStatefulSet(ctx, namespace, &sts); err != nil {
+			logger.Error("Failed to scale down statefulset", "namespace", namespace, "name", sts.Name, "error", err)
+			continue
+		}
+		if !record.recorded("StatefulSet", sts.Name) {
+			record.Changes = append(record.Changes, SuspendedObject{Kind: "StatefulSet", Name: sts.Name, Action: SuspendActionScale, Previous: strconv.Itoa(int(previous))})
+		}
+	}
+
+	if err := s.suspendCronJobs(ctx, namespace, filter, exemptions, record); err != nil {
+		return err
+	}
+
+	// Suspend unfinished Jobs; Kubernetes terminates their running pods
+	jobs, err := s.k8sClient.ListJobs(ctx, namespace, "")
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs.Items {
+		if jobFinished(&job) || (job.Spec.Suspend != nil && *job.Spec.Suspend) || !filter.podSpec(&job.Spec.Template.Spec) ||
+			exemptions.exempt(record, "Job", job.Name, job.Labels, job.Annotations) {
+			continue
+		}
+		suspend := true
+		job.Spec.Suspend = &suspend
+		if err := s.k8sClient.UpdateJob(ctx, namespace, &job); err != nil {
+			logger.Error("Failed to suspend job", "namespace", namespace, "name", job.Name, "error", err)
+			continue
+		}
+		if !record.recorded("Job", job.Name) {
+			record.Changes = append(record.Changes, SuspendedObject{Kind: "Job", Name: job.Name, Action: SuspendActionSuspend, Previous: "false"})
+		}
+	}
+
+	// Pause operator-managed workloads
+	for _, r := range suspendableResources(config) {
+		s.suspendCustomResources(ctx, r, namespace, filter, exemptions, record)
+	}
+
+	// Delete orphan pods (pods not managed by a controller)
+	pods, err := s.k8sClient.ListPods(ctx, namespace, "")
+	if err != nil {
+		logger.Error("Failed to list pods", "namespace", namespace, "error", err)
+	} else {
+		for _, pod := range pods.Items {
+			if len(pod.OwnerReferences) > 0 || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed || !filter.podSpec(&pod.Spec) ||
+				exemptions.exempt(record, "Pod", pod.Name, pod.Labels, pod.Annotations) {
+				continue
+			}
+			logger.Info("Deleting orphan pod", "namespace", namespace, "name", pod.Name)
+			if err := s.k8sClient.DeletePod(ctx, namespace, pod.Name); err != nil {
+				logger.Error("Failed to delete orphan pod", "namespace", namespace, "name", pod.Name, "error", err)
+				continue
+			}
+			record.Changes = append(record.Changes, SuspendedObject{Kind: "Pod", Name: pod.Name, Action: SuspendActionDelete})
+		}
+	}
+
+	return nil
+}
+
+// suspendCronJobs suspends the non-exempt CronJobs selected by filter so they stop creating Jobs
+func (s *BillingService) suspendCronJobs(ctx context.Context, namespace string, filter acceleratorFilter, exemptions *suspensionExemptions, record *SuspensionRecord) error {
+	cronJobs, err := s.k8sClient.ListCronJobs(ctx, namespace)
+	if err != nil {
+		return err
+	}
+	for _, cronJob := range cronJobs.Items {
+		if (cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend) || !filter.podSpec(&cronJob.Spec.JobTemplate.Spec.Template.Spec) ||
+			exemptions.exempt(record, "CronJob", cronJob.Name, cronJob.Labels, cronJob.Annotations) {
+			continue
+		}
+		suspend := true
+		cronJob.Spec.Suspend = &suspend
+		if err := s.k8sClient.UpdateCronJob(ctx, namespace, &cronJob); err != nil {
+			logger.Error("Failed to suspend cronjob", "namespace", namespace, "name", cronJob.Name, "error", err)
+			continue
+		}
+		if !record.recorded("CronJob", cronJob.Name) {
+			record.Changes = append(record.Changes, SuspendedObject{Kind: "CronJob", Name: cronJob.Name, Action: SuspendActionSuspend, Previous: "false"})
+		}
+	}
+	return nil
+}
+
+// acceleratorFilter selects workloads that request any of a set of accelerator resources.
+// A nil filter selects every workload.
+type acceleratorFilter map[string]bool
+
+// acceleratorFilter returns the resources configured in the accelerator category, falling
+// back to the common GPU resource names
+func (s *BillingService) acceleratorFilter(ctx context.Context) acceleratorFilter {
+	filter := acceleratorFilter{}
+	configs, err := s.resourceConfigSvc.GetResourceConfigs(ctx)
+	if err != nil {
+		logger.Warn("Failed to load resource configs, using default accelerators", "error", err)
+	}
+	for _, rc := range configs {
+		if rc.Category == CategoryAccelerator {
+			filter[rc.Name] = true
+		}
+	}
+	if len(filter) == 0 {
+		filter["nvidia.com/gpu"] = true
+		filter["amd.com/gpu"] = true
+	}
+	return filter
+}
+
+// podSpec reports whether a pod spec is selected
+func (f acceleratorFilter) podSpec(spec *corev1.PodSpec) bool {
+	if f == nil {
+		return true
+	}
+	containers := append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, container := range containers {
+		for name := range container.Resources.Requests {
+			if f[string(name)] {
+				return true
+			}
+		}
+		for name := range container.Resources.Limits {
+			if f[string(name)] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// object reports whether a custom resource is selected, by looking for accelerators in any
+// requests or limits of its embedded pod templates
+func (f acceleratorFilter) object(value interface{}) bool {
+	if f == nil {
+		return true
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if resources, ok := child.(map[string]interface{}); ok && (key == "requests" || key == "limits") {
+				for name := range resources {
+					if f[name] {
+						return true
+					}
+				}
+			}
+			if f.object(child) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, child := range v {
+			if f.object(child) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// suspendCustomResources pauses the non-exempt objects of one custom kind in a namespace
+// selected by filter
+func (s *BillingService) suspendCustomResources(ctx context.Context, r SuspendableResource, namespace string, filter acceleratorFilter, exemptions *suspensionExemptions, record *SuspensionRecord) {
+	list, err := s.k8sClient.ListCustomResources(ctx, r.gvr(), namespace)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return // CRD not installed
+		}
+		logger.Error("Failed to list custom resources", "resource", r.Resource, "namespace", namespace, "error", err)
+		return
+	}
+
+	for i := range list.Items {
+		obj := &list.Items[i]
+		kind := obj.GetKind()
+		if !filter.object(obj.Object["spec"]) {
+			continue
+		}
+
+		change := SuspendedObject{Kind: kind, Name: obj.GetName(), Group: r.Group, Version: r.Version, Resource: r.Resource}
+		if r.SuspendPath != "" {
+			path := strings.Split(r.SuspendPath, ".")
+			suspended, _, _ := unstructured.NestedBool(obj.Object, path...)
+			if suspended {
+				continue
+			}
+			if err := unstructured.SetNestedField(obj.Object, true, path...); err != nil {
+				logger.Error("Failed to set suspend field", "kind", kind, "name", obj.GetName(), "path", r.SuspendPath, "error", err)
+				continue
+			}
+			change.Action, change.Path, change.Previous = SuspendActionSuspend, r.SuspendPath, "false"
+		} else {
+			path := strings.Split(r.ReplicasPath, ".")
+			replicas, found, _ := unstructured.NestedInt64(obj.Object, path...)
+			if !found || replicas == 0 {
+				continue
+			}
+			if err := unstructured.SetNestedField(obj.Object, int64(0), path...); err != nil {
+				logger.Error("Failed to set replicas field", "kind", kind, "name", obj.GetName(), "path", r.ReplicasPath, "error", err)
+				continue
+			}
+			change.Action, change.Path, change.Previous = SuspendActionScale, r.ReplicasPath, strconv.FormatInt(replicas, 10)
+		}
+
+		if exemptions.exempt(record, kind, obj.GetName(), obj.GetLabels(), obj.GetAnnotations()) {
+			continue
+		}
+		if err := s.k8sClient.UpdateCustomResource(ctx, r.gvr(), namespace, obj); err != nil {
+			logger.Error("Failed to suspend custom resource", "kind", kind, "namespace", namespace, "name", obj.GetName(), "error", err)
+			continue
+		}
+		if !record.recorded(kind, obj.GetName()) {
+			record.Changes = append(record.Changes, change)
+		}
+	}
+}
+
+// jobFinished reports whether a Job has completed or failed
+func jobFinished(job *batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if (condition.Type == batchv1.JobComplete || condition.Type == batchv1.JobFailed) && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// resumeNamespace restores every object recorded when the namespace was suspended. Changes
+// that fail to restore stay in the record so a later resume retries them.
+func (s *BillingService) resumeNamespace(ctx context.Context, namespace string) error {
+	record, err := s.loadSuspensionRecord(ctx, namespace)
+	if err != nil {
+		return err
+	}
+	if record == nil {
+		// Suspended by an older version, which kept replica counts in annotations
+		return s.restoreLegacyReplicas(ctx, namespace)
+	}
+
+	var remaining []SuspendedObject
+	for _, change := range record.Changes {
+		if change.Action == SuspendActionDelete {
+			continue
+		}
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			return s.restoreObject(ctx, namespace, change)
+		})
+		if err != nil && !errors.IsNotFound(err) {
+			logger.Error("Failed to restore object", "namespace", namespace, "kind", change.Kind, "name", change.Name, "error", err)
+			remaining = append(remaining, change)
+		}
+	}
+
+	record.Changes = remaining
+	if len(remaining) == 0 {
+		// Fully resumed, so the exemptions no longer apply either
+		record.Exempted = nil
+	}
+	if err := s.saveSuspensionRecord(ctx, record); err != nil {
+		return err
+	}
+	if len(remaining) > 0 {
+		return fmt.Errorf("failed to restore %d objects in namespace %s", len(remaining), namespace)
+	}
+	return nil
+}
+
+// restoreObject sets one object back to its value before suspension
+func (s *BillingService) restoreObject(ctx context.Context, namespace string, change SuspendedObject) error {
+	switch change.Kind {
+	case "Deployment":
+		deploy, err := s.k8sClient.GetDeployment(ctx, namespace, change.Name)
+		if err != nil {
+			return err
+		}
+		replicas, err := strconv.ParseInt(change.Previous, 10, 32)
+		if err != nil {
+			return err
+		}
+		r := int32(replicas)
+		deploy.Spec.Replicas = &r
+		return s.k8sClient.UpdateDeployment(ctx, namespace, deploy)
+
+	case "StatefulSet":
+		sts, err := s.k8sClient.GetStatefulSet(ctx, namespace, change.Name)
+		if err != nil {
+			return err
+		}
+		replicas, err := strconv.ParseInt(change.Previous, 10, 32)
+		if err != nil {
+			return err
+		}
+		r := int32(replicas)
+		sts.Spec.Replicas = &r
+		return s.k8sClient.UpdateStatefulSet(ctx, namespace, sts)
+
+	case "CronJob":
+		cronJob, err := s.k8sClient.GetCronJob(ctx, namespace, change.Name)
+		if err != nil {
+			return err
+		}
+		suspend := change.Previous == "true"
+		cronJob.Spec.Suspend = &suspend
+		return s.k8sClient.UpdateCronJob(ctx, namespace, cronJob)
+
+	case "Job":
+		job, err := s.k8sClient.GetJob(ctx, namespace, change.Name)
+		if err != nil {
+			return err
+		}
+		suspend := change.Previous == "true"
+		job.Spec.Suspend = &suspend
+		return s.k8sClient.UpdateJob(ctx, namespace, job)
+
+	case "ResourceQuota":
+		return s.k8sClient.DeleteResourceQuota(ctx, namespace, change.Name)
+	}
+
+	// Custom resource
+	if change.Resource == "" {
+		return fmt.Errorf("unknown suspended kind %s", change.Kind)
+	}
+	gvr := schema.GroupVersionResource{Group: change.Group, Version: change.Version, Resource: change.Resource}
+	obj, err := s.k8sClient.GetCustomResource(ctx, gvr, namespace, change.Name)
+	if err != nil {
+		return err
+	}
+	path := strings.Split(change.Path, ".")
+	switch change.Action {
+	case SuspendActionSuspend:
+		err = unstructured.SetNestedField(obj.Object, change.Previous == "true", path...)
+	case SuspendActionScale:
+		var replicas int64
+		replicas, err = strconv.ParseInt(change.Previous, 10, 64)
+		if err == nil {
+			err = unstructured.SetNestedField(obj.Object, replicas, path...)
+		}
+	default:
+		err = fmt.Errorf("unknown suspension action %s", change.Action)
+	}
+	if err != nil {
+		return err
+	}
+	return s.k8sClient.UpdateCustomResource(ctx, gvr, namespace, obj)
+}
+
+// restoreLegacyReplicas scales up workloads suspended before suspension records existed
+func (s *BillingService) restoreLegacyReplicas(ctx context.Context, namespace string) error {
+	deployments, err := s.k8sClient.ListDeployments(ctx, namespace)
+	if err != nil {
+		return err
+	}
+	for _, deploy := range deployments.Items {
+		original, err := strconv.ParseInt(deploy.Annotations[legacyReplicasAnnotation], 10, 32)
+		if err != nil {
+			continue
+		}
+		replicas := int32(original)
+		deploy.Spec.Replicas = &replicas
+		delete(deploy.Annotations, legacyReplicasAnnotation)
+		if err := s.k8sClient.UpdateDeployment(ctx, namespace, &deploy); err != nil {
+			logger.Error("Failed to scale up deployment", "namespace", namespace, "name", deploy.Name, "error", err)
+		}
+	}
+
+	statefulsets, err := s.k8sClient.ListStatefulSets(ctx, namespace)
+	if err != nil {
+		return err
+	}
+	for _, sts := range statefulsets.Items {
+		original, err := strconv.ParseInt(sts.Annotations[legacyReplicasAnnotation], 10, 32)
+		if err != nil {
+			continue
+		}
+		replicas := int32(original)
+		sts.Spec.Replicas = &replicas
+		delete(sts.Annotations, legacyReplicasAnnotation)
+		if err := s.k8sClient.UpdateStatefulSet(ctx, namespace, &sts); err != nil {
+			logger.Error("Failed to scale up statefulset", "namespace", namespace, "name", sts.Name, "error", err)
+		}
+	}
+
+	return nil
+}
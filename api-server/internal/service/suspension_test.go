@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bison/api-server/internal/k8s"
+	"github.com/bison/api-server/internal/store"
+)
+
+func TestExemptionOnlySuspensionRecordIsKept(t *testing.T) {
+	ctx := context.Background()
+	svc := &BillingService{store: store.NewConfigMapStore(k8s.NewClientFromInterfaces(newConflictingClientset(0), nil), store.Namespace)}
+
+	record := &SuspensionRecord{
+		Namespace:   "ns-a",
+		Team:        "team-a",
+		Strategy:    SuspensionStrategyScaleDown,
+		SuspendedAt: time.Now(),
+		Exempted:    []ExemptedObject{{Kind: "Namespace", Name: "ns-a", Reason: "namespace label " + SuspensionExemptKey}},
+	}
+	if err := svc.saveSuspensionRecord(ctx, record); err != nil {
+		t.Fatalf("failed to save suspension record: %v", err)
+	}
+
+	stored, err := svc.loadSuspensionRecord(ctx, "ns-a")
+	if err != nil {
+		t.Fatalf("failed to load suspension record: %v", err)
+	}
+	if stored == nil || len(stored.Exempted) != 1 {
+		t.Fatalf("got record %+v, want the exemption kept", stored)
+	}
+
+	// Nothing to restore, and the exemptions are dropped with the record
+	if err := svc.resumeNamespace(ctx, "ns-a"); err != nil {
+		t.Fatalf("failed to resume namespace: %v", err)
+	}
+	if stored, err = svc.loadSuspensionRecord(ctx, "ns-a"); err != nil {
+		t.Fatalf("failed to load suspension record: %v", err)
+	}
+	if stored != nil {
+		t.Fatalf("got record %+v after resume, want none", stored)
+	}
+}
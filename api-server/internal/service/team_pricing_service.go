@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/bison/api-server/internal/store"
+	"github.com/bison/api-server/pkg/logger"
+)
+
+const (
+	TeamPricingConfigMap = "bison-team-pricing"
+)
+
+// TeamPricingPolicy is a negotiated rate for one team, layered over global pricing.
+// Resource keys are resource names (cpu, memory, nvidia.com/gpu); accelerator keys may
+// also name a model as "<resource>:<model>", which takes precedence over the plain resource.
+type TeamPricingPolicy struct {
+	Team              string             `json:"team"`
+	DiscountPercent   float64            `json:"discountPercent"`             // Percent off every resource, 0-100
+	ResourceDiscounts map[string]float64 `json:"resourceDiscounts,omitempty"` // Percent off per resource, replaces DiscountPercent
+	PriceOverrides    map[string]float64 `json:"priceOverrides,omitempty"`    // Fixed price per unit per hour, 0 = free
+	ValidFrom         *time.Time         `json:"validFrom,omitempty"`         // Policy applies from this time (inclusive)
+	ValidUntil        *time.Time         `json:"validUntil,omitempty"`        // Policy applies until this time (exclusive)
+	Remark            string             `json:"remark,omitempty"`
+	UpdatedAt         time.Time          `json:"updatedAt"`
+	UpdatedBy         string             `json:"updatedBy,omitempty"`
+}
+
+// Validate checks the policy values
+func (p *TeamPricingPolicy) Validate() error {
+	if p.Team == "" {
+		return fmt.Errorf("team is required")
+	}
+	if p.DiscountPercent < 0 || p.DiscountPercent > 100 {
+		return fmt.Errorf("discount must be between 0 and 100")
+	}
+	for resource, discount := range p.ResourceDiscounts {
+		if discount < 0 || discount > 100 {
+			return fmt.Errorf("discount for %s must be between 0 and 100", resource)
+		}
+	}
+	for resource, price := range p.PriceOverrides {
+		if price < 0 {
+			return fmt.Errorf("price override for %s must not be negative", resource)
+		}
+	}
+	if p.ValidFrom != nil && p.ValidUntil != nil && !p.ValidUntil.After(*p.ValidFrom) {
+		return fmt.Errorf("validUntil must be after validFrom")
+	}
+	return nil
+}
+
+// ActiveAt reports whether the policy is in effect at the given time
+func (p *TeamPricingPolicy) ActiveAt(at time.Time) bool {
+	if p == nil {
+		return false
+	}
+	if p.ValidFrom != nil && at.Before(*p.ValidFrom) {
+		return false
+	}
+	if p.ValidUntil != nil && !at.Before(*p.ValidUntil) {
+		return false
+	}
+	return true
+}
+
+// PriceOverride returns the fixed price for a resource, checking the model-specific key first
+func (p *TeamPricingPolicy) PriceOverride(resource, product string) (float64, bool) {
+	if product != "" {
+		if price, ok := p.PriceOverrides[resource+":"+product]; ok {
+			return price, true
+		}
+	}
+	price, ok := p.PriceOverrides[resource]
+	return price, ok
+}
+
+// Discount returns the fraction of the price to take off for a resource
+func (p *TeamPricingPolicy) Discount(resource, product string) float64 {
+	if product != "" {
+		if discount, ok := p.ResourceDiscounts[resource+":"+product]; ok {
+			return discount / 100
+		}
+	}
+	if discount, ok := p.ResourceDiscounts[resource]; ok {
+		return discount / 100
+	}
+	return p.DiscountPercent / 100
+}
+
+// TeamPricingService manages per-team pricing policies
+type TeamPricingService struct {
+	store store.Store
+}
+
+// NewTeamPricingService creates a new TeamPricingService
+func NewTeamPricingService(st store.Store) *TeamPricingService {
+	return &TeamPricingService{
+		store: st,
+	}
+}
+
+// Get returns the pricing policy of a team, or nil if it has none
+func (s *TeamPricingService) Get(ctx context.Context, teamName string) (*TeamPricingPolicy, error) {
+	entries, err := s.store.Load(ctx, TeamPricingConfigMap)
+	if err != nil {
+		return nil, err
+	}
+
+	data, ok := entries[teamName]
+	if !ok {
+		return nil, nil
+	}
+
+	var policy TeamPricingPolicy
+	if err := json.Unmarshal([]byte(data), &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse pricing policy: %w", err)
+	}
+	return &policy, nil
+}
+
+// List returns all team pricing policies sorted by team
+func (s *TeamPricingService) List(ctx context.Context) ([]*TeamPricingPolicy, error) {
+	entries, err := s.store.Load(ctx, TeamPricingConfigMap)
+	if err != nil {
+		return nil, err
+	}
+
+	policies := make([]*TeamPricingPolicy, 0, len(entries))
+	for teamName, data := range entries {
+		var policy TeamPricingPolicy
+		if err := json.Unmarshal([]byte(data), &policy); err != nil {
+			logger.Warn("Skipping invalid pricing policy", "team", teamName, "error", err)
+			continue
+		}
+		policies = append(policies, &policy)
+	}
+
+	sort.Slice(policies, func(i, j int) bool {
+		return policies[i].Team < policies[j].Team
+	})
+	return policies, nil
+}
+
+// Set creates or replaces the pricing policy of a team
+func (s *TeamPricingService) Set(ctx context.Context, policy *TeamPricingPolicy, operator string) error {
+	logger.Info("Setting team pricing policy", "team", policy.Team, "operator", operator)
+
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	policy.UpdatedAt = time.Now()
+	policy.UpdatedBy = operator
+
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pricing policy: %w", err)
+	}
+
+	return s.store.Modify(ctx, TeamPricingConfigMap, func(entries map[string]string) error {
+		entries[policy.Team] = string(data)
+		return nil
+	})
+}
+
+// Delete removes the pricing policy of a team
+func (s *TeamPricingService) Delete(ctx context.Context, teamName string) error {
+	logger.Info("Deleting team pricing policy", "team", teamName)
+
+	return s.store.Modify(ctx, TeamPricingConfigMap, func(entries map[string]string) error {
+		delete(entries, teamName)
+		return nil
+	})
+}
+
+// ReplaceAll replaces every team pricing policy, used by configuration import
+func (s *TeamPricingService) ReplaceAll(ctx context.Context, policies []*TeamPricingPolicy) error {
+	data := make(map[string]string, len(policies))
+	for _, policy := range policies {
+		if err := policy.Validate(); err != nil {
+			return err
+		}
+		raw, err := json.Marshal(policy)
+		if err != nil {
+			return fmt.Errorf("failed to marshal pricing policy: %w", err)
+		}
+		data[policy.Team] = string(raw)
+	}
+
+	return s.store.Modify(ctx, TeamPricingConfigMap, func(entries map[string]string) error {
+		for key := range entries {
+			delete(entries, key)
+		}
+		for key, value := range data {
+			entries[key] = value
+		}
+		return nil
+	})
+}
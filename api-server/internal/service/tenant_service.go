@@ -19,6 +19,15 @@ const (
 	TeamModeExclusive TeamMode = "exclusive" // Team has exclusive nodes
 )
 
+// BillingMode is how a team is charged
+type BillingMode string
+
+const (
+	BillingModeUsage       BillingMode = "usage"       // Charged for what its pods use
+	BillingModeReservation BillingMode = "reservation" // Charged per node-hour for the nodes in its exclusive pool
+	BillingModeCommitted   BillingMode = "committed"   // Charged for its quota at reservation prices, plus usage above it
+)
+
 // Reserved team names that cannot be used
 var reservedTeamNames = map[string]bool{
 	"shared":    true,
@@ -62,6 +71,7 @@ type Team struct {
 	Description    string            `json:"description,omitempty"`
 	Owners         []OwnerRef        `json:"owners"`                   // User or Group owners
 	Mode           TeamMode          `json:"mode"`                     // "shared" or "exclusive"
+	BillingMode    BillingMode       `json:"billingMode"`              // "usage", "reservation" or "committed"
 	ExclusiveNodes []string          `json:"exclusiveNodes,omitempty"` // Node names for exclusive mode
 	NodeSelector   map[string]string `json:"nodeSelector,omitempty"`   // Auto-generated based on mode
 	Quota          map[string]string `json:"quota"`                    // Dynamic quota: {"cpu": "10", "memory": "20Gi", "nvidia.com/gpu": "4"}
@@ -71,6 +81,24 @@ type Team struct {
 	Suspended      bool              `json:"suspended"` // Whether team is suspended due to insufficient balance
 }
 
+// ValidateBillingMode checks that the team's billing mode fits its resource mode
+func (t *Team) ValidateBillingMode() error {
+	switch t.BillingMode {
+	case BillingModeUsage:
+	case BillingModeReservation:
+		if t.Mode != TeamModeExclusive {
+			return fmt.Errorf("reservation billing requires exclusive mode")
+		}
+	case BillingModeCommitted:
+		if t.Mode != TeamModeShared {
+			return fmt.Errorf("committed capacity billing requires shared mode")
+		}
+	default:
+		return fmt.Errorf("invalid billing mode %q", t.BillingMode)
+	}
+	return nil
+}
+
 // TeamStatus represents the current status of a team
 type TeamStatus struct {
 	Ready      bool   `json:"ready"`
@@ -281,10 +309,11 @@ func (s *TenantService) tenantToTeam(tenant *unstructured.Unstructured) (*Team,
 		Quota:          make(map[string]string),
 		Owners:         []OwnerRef{},
 		Mode:           TeamModeShared, // Default to shared
+		BillingMode:    BillingModeUsage,
 		ExclusiveNodes: []string{},
 	}
 
-	// Get annotations for display name, description, mode, and suspended status
+	// Get annotations for display name, description, mode, billing mode, and suspended status
 	annotations := tenant.GetAnnotations()
 	if annotations != nil {
 		team.DisplayName = annotations["bison.io/display-name"]
@@ -295,6 +324,10 @@ func (s *TenantService) tenantToTeam(tenant *unstructured.Unstructured) (*Team,
 		if mode := annotations["bison.io/mode"]; mode == string(TeamModeExclusive) {
 			team.Mode = TeamModeExclusive
 		}
+		switch mode := BillingMode(annotations["bison.io/billing-mode"]); mode {
+		case BillingModeReservation, BillingModeCommitted:
+			team.BillingMode = mode
+		}
 
 		// Parse exclusive nodes (comma-separated)
 		if nodes := annotations["bison.io/exclusive-nodes"]; nodes != "" {
@@ -422,6 +455,7 @@ func (s *TenantService) teamToTenant(team *Team) *unstructured.Unstructured {
 		"bison.io/display-name": team.DisplayName,
 		"bison.io/description":  team.Description,
 		"bison.io/mode":         string(team.Mode),
+		"bison.io/billing-mode": string(team.BillingMode),
 	}
 	if team.Suspended {
 		annotations["bison.io/suspended"] = "true"
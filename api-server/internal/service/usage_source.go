@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+
+	"github.com/bison/api-server/internal/opencost"
+)
+
+// Usage sources
+const (
+	UsageSourceOpenCost = "opencost"
+	UsageSourceMetering = "metering"
+)
+
+// UsageSource provides resource usage to billing, bills and statistics. Every source
+// reports usage as OpenCost allocations, so pricing does not depend on where it came from.
+// Windows use the OpenCost syntax: "7d", "24h", "today", "month" or "<RFC3339>,<RFC3339>".
+type UsageSource interface {
+	// Name identifies the source, e.g. "opencost"
+	Name() string
+
+	// IsEnabled reports whether the source can serve queries
+	IsEnabled() bool
+
+	// GetPodAllocations returns workload-level usage for a window, optionally limited to one
+	// namespace. Each allocation carries the node it ran on.
+	GetPodAllocations(ctx context.Context, window, namespace string) ([]opencost.Allocation, error)
+
+	// GetPodAllocationSteps returns workload-level usage split into consecutive steps (e.g. "1h")
+	GetPodAllocationSteps(ctx context.Context, window, namespace, step string) ([]opencost.AllocationStep, error)
+
+	// GetNamespaceAllocations returns usage aggregated by namespace. With includeIdle, sources
+	// that know the cluster's unallocated cost add it as opencost.IdleAllocation entries.
+	GetNamespaceAllocations(ctx context.Context, window string, includeIdle bool) ([]opencost.Allocation, error)
+
+	// GetCostTrend returns the daily total cost for a window
+	GetCostTrend(ctx context.Context, window string) ([]opencost.CostTrendPoint, error)
+}
+
+// openCostUsageSource reads usage from the OpenCost allocation API
+type openCostUsageSource struct {
+	client *opencost.Client
+}
+
+// NewOpenCostUsageSource creates a usage source backed by OpenCost
+func NewOpenCostUsageSource(client *opencost.Client) UsageSource {
+	return &openCostUsageSource{client: client}
+}
+
+func (s *openCostUsageSource) Name() string {
+	return UsageSourceOpenCost
+}
+
+func (s *openCostUsageSource) IsEnabled() bool {
+	return s.client != nil && s.client.IsEnabled()
+}
+
+func (s *openCostUsageSource) GetPodAllocations(ctx context.Context, window, namespace string) ([]opencost.Allocation, error) {
+	if namespace == "" {
+		return s.client.GetAllocationByPod(ctx, window)
+	}
+	return s.client.GetPodAllocationsForNamespace(ctx, window, namespace)
+}
+
+func (s *openCostUsageSource) GetPodAllocationSteps(ctx context.Context, window, namespace, step string) ([]opencost.AllocationStep, error) {
+	return s.client.GetPodAllocationSteps(ctx, window, namespace, step)
+}
+
+func (s *openCostUsageSource) GetNamespaceAllocations(ctx context.Context, window string, includeIdle bool) ([]opencost.Allocation, error) {
+	if includeIdle {
+		return s.client.GetAllocationByNamespaceWithIdle(ctx, window)
+	}
+	return s.client.GetAllocationByNamespace(ctx, window)
+}
+
+func (s *openCostUsageSource) GetCostTrend(ctx context.Context, window string) ([]opencost.CostTrendPoint, error) {
+	return s.client.GetCostTrend(ctx, window)
+}
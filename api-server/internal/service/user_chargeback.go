@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bison/api-server/internal/opencost"
+)
+
+const (
+	// AnnotationCreatedBy records the user who created a pod or workload, set at admission
+	AnnotationCreatedBy = "bison.io/created-by"
+
+	// LabelUser carries the creator as a label value, so usage sources that only report
+	// labels can attribute cost. Usernames are sanitized to fit label value rules.
+	LabelUser = "bison.io/user"
+)
+
+// UserLabelValue converts a username to the value of its LabelUser label
+func UserLabelValue(username string) string {
+	value := sanitizeForK8s(username)
+	if len(value) > 63 {
+		value = strings.TrimRight(value[:63], "-")
+	}
+	return value
+}
+
+// allocationCreator returns the creator an allocation is attributed to, if any. OpenCost
+// reports label names in Prometheus form, with dots and slashes replaced by underscores.
+func allocationCreator(alloc *opencost.Allocation) string {
+	if user := alloc.Properties.Labels[LabelUser]; user != "" {
+		return user
+	}
+	return alloc.Properties.Labels["bison_io_user"]
+}
+
+// UserCost is what one user's pods cost within a team
+type UserCost struct {
+	User     string             `json:"user"` // Creator recorded at admission; empty for pods created before attribution or by operators
+	Cost     float64            `json:"cost"`
+	GPUHours float64            `json:"gpuHours"`
+	Projects map[string]float64 `json:"projects"` // Cost by project
+}
+
+// GetTeamUserCosts rolls a team's pod costs up by the user who created each pod, most
+// expensive first. Costs are priced like usage bills, with the team's pricing policy, so
+// they show each user's share of consumption whatever the team's billing mode.
+func (s *BillingService) GetTeamUserCosts(ctx context.Context, teamName, window string) ([]*UserCost, error) {
+	if window == "" {
+		window = "7d"
+	}
+
+	projects, err := s.projectSvc.ListByTeam(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	costs := make(map[string]*UserCost)
+	if s.usageSource != nil && s.usageSource.IsEnabled() {
+		config, _ := s.GetConfig(ctx)
+		pricing := s.loadPricing(ctx)
+		policy := s.activePricingPolicy(ctx, teamName, time.Now())
+		names := s.teamUserNames(ctx, teamName, projects)
+
+		for _, project := range projects {
+			allocations, err := s.getPodAllocations(ctx, config, window, project.Name)
+			if err != nil {
+				return nil, err
+			}
+
+			byUser := make(map[string][]bandedAllocation)
+			for _, alloc := range allocations {
+				user := allocationCreator(&alloc.Allocation)
+				if name, ok := names[user]; ok {
+					user = name
+				}
+				byUser[user] = append(byUser[user], alloc)
+			}
+
+			for user, userAllocations := range byUser {
+				bill := newBill(user, window)
+				s.addToBill(bill, config, pricing, policy, userAllocations)
+
+				cost, ok := costs[user]
+				if !ok {
+					cost = &UserCost{User: user, Projects: make(map[string]float64)}
+					costs[user] = cost
+				}
+				cost.Cost += bill.TotalCost
+				cost.GPUHours += bill.UsageDetails.GPUHours
+				cost.Projects[project.Name] += bill.TotalCost
+			}
+		}
+	}
+
+	result := make([]*UserCost, 0, len(costs))
+	for _, cost := range costs {
+		result = append(result, cost)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Cost != result[j].Cost {
+			return result[i].Cost > result[j].Cost
+		}
+		return result[i].User < result[j].User
+	})
+	return result, nil
+}
+
+// teamUserNames maps the label values of a team's known users, its owners and project
+// members, back to their usernames
+func (s *BillingService) teamUserNames(ctx context.Context, teamName string, projects []*Project) map[string]string {
+	names := make(map[string]string)
+	add := func(username string) {
+		if username != "" {
+			names[UserLabelValue(username)] = username
+		}
+	}
+
+	if team, err := s.tenantSvc.Get(ctx, teamName); err == nil {
+		for _, owner := range team.Owners {
+			if owner.Kind == "User" {
+				add(owner.Name)
+			}
+		}
+	}
+	for _, project := range projects {
+		full, err := s.projectSvc.Get(ctx, project.Name)
+		if err != nil {
+			continue
+		}
+		for _, member := range full.Members {
+			add(member.User)
+		}
+	}
+	return names
+}
@@ -0,0 +1,397 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/bison/api-server/pkg/logger"
+)
+
+// UserLimitsConfigMap stores the per-user limits of each team, keyed by team name
+const UserLimitsConfigMap = "bison-user-limits"
+
+// UserBlockNoticeInterval is how often the team owners are told about rejected pods of the same user
+const UserBlockNoticeInterval = time.Hour
+
+// UserLimit caps what one user may spend and hold within a team. Reaching a limit alerts the
+// team owners; with Enforce, new pods created by the user are also rejected at admission.
+type UserLimit struct {
+	User         string    `json:"user"`
+	MonthlySpend float64   `json:"monthlySpend"` // Spend allowed per calendar month; 0 = no limit
+	MaxGPUs      float64   `json:"maxGPUs"`      // Accelerators the user's pods may hold at once; 0 = no limit
+	Enforce      bool      `json:"enforce"`      // Reject new pods from the user at a limit instead of only alerting
+	UpdatedAt    time.Time `json:"updatedAt,omitempty"`
+	UpdatedBy    string    `json:"updatedBy,omitempty"`
+
+	// Tracking, maintained by the limit check
+	Month        string     `json:"month,omitempty"` // UTC month the tracking applies to, e.g. 2026-10
+	Spent        float64    `json:"spent"`
+	GPUs         float64    `json:"gpus"` // Accelerators held at the last check
+	CheckedAt    *time.Time `json:"checkedAt,omitempty"`
+	SpendAlerted bool       `json:"spendAlerted"`        // Spend notice already sent this month
+	GPUAlerted   bool       `json:"gpuAlerted"`          // GPU notice sent; cleared once the user is below the limit again
+	BlockedAt    *time.Time `json:"blockedAt,omitempty"` // Last time a pod from the user was rejected
+}
+
+// Validate checks a user limit
+func (l *UserLimit) Validate() error {
+	if l.MonthlySpend < 0 || l.MaxGPUs < 0 {
+		return fmt.Errorf("limits must not be negative")
+	}
+	if l.MonthlySpend == 0 && l.MaxGPUs == 0 {
+		return fmt.Errorf("at least one of monthly spend and max GPUs must be set")
+	}
+	return nil
+}
+
+// keepTracking copies the tracking state of a stored limit into an updated one
+func (l *UserLimit) keepTracking(stored *UserLimit) {
+	l.Month = stored.Month
+	l.Spent = stored.Spent
+	l.GPUs = stored.GPUs
+	l.CheckedAt = stored.CheckedAt
+	l.SpendAlerted = stored.SpendAlerted
+	l.GPUAlerted = stored.GPUAlerted
+	l.BlockedAt = stored.BlockedAt
+}
+
+// spendReached reports whether the user's tracked spend for the current month reached the limit
+func (l *UserLimit) spendReached(month string) bool {
+	return l.MonthlySpend > 0 && l.Month == month && l.Spent >= l.MonthlySpend
+}
+
+// GetUserLimits returns the per-user limits of a team
+func (s *BillingService) GetUserLimits(ctx context.Context, teamName string) ([]*UserLimit, error) {
+	entries, err := s.store.Load(ctx, UserLimitsConfigMap)
+	if err != nil {
+		return nil, err
+	}
+
+	limits, err := parseUserLimits(entries[teamName])
+	if err != nil {
+		logger.Error("Failed to unmarshal user limits", "team", teamName, "error", err)
+		return nil, err
+	}
+	return limits, nil
+}
+
+// GetUserLimit returns the limit of a user within a team, or nil if the user has none
+func (s *BillingService) GetUserLimit(ctx context.Context, teamName, user string) (*UserLimit, error) {
+	limits, err := s.GetUserLimits(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	for _, limit := range limits {
+		if limit.User == user {
+			return limit, nil
+		}
+	}
+	return nil, nil
+}
+
+// SetUserLimit creates or replaces the limit of a user within a team
+func (s *BillingService) SetUserLimit(ctx context.Context, teamName, user string, limit *UserLimit, operator string) (*UserLimit, error) {
+	logger.Info("Setting user limit", "team", teamName, "user", user, "monthlySpend", limit.MonthlySpend, "maxGPUs", limit.MaxGPUs, "enforce", limit.Enforce)
+
+	if _, err := s.tenantSvc.Get(ctx, teamName); err != nil {
+		return nil, err
+	}
+
+	limit.User = user
+	limit.UpdatedAt = time.Now()
+	limit.UpdatedBy = operator
+
+	var saved UserLimit
+	err := s.modifyUserLimits(ctx, teamName, func(limits []*UserLimit) []*UserLimit {
+		saved = *limit
+		for i, stored := range limits {
+			if stored.User == user {
+				saved.keepTracking(stored)
+				limits[i] = &saved
+				return limits
+			}
+		}
+		return append(limits, &saved)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+// DeleteUserLimit removes the limit of a user within a team
+func (s *BillingService) DeleteUserLimit(ctx context.Context, teamName, user string) error {
+	logger.Info("Deleting user limit", "team", teamName, "user", user)
+
+	return s.modifyUserLimits(ctx, teamName, func(limits []*UserLimit) []*UserLimit {
+		kept := make([]*UserLimit, 0, len(limits))
+		for _, limit := range limits {
+			if limit.User != user {
+				kept = append(kept, limit)
+			}
+		}
+		return kept
+	})
+}
+
+// CheckUserLimits updates the month-to-date spend and held accelerators of every limited
+// user, and alerts the team owners when a user reaches a limit. Admission rejects pods from
+// enforced users based on the spend recorded here.
+func (s *BillingService) CheckUserLimits(ctx context.Context) error {
+	entries, err := s.store.Load(ctx, UserLimitsConfigMap)
+	if err != nil {
+		return err
+	}
+
+	teams := make([]string, 0, len(entries))
+	for teamName := range entries {
+		teams = append(teams, teamName)
+	}
+	sort.Strings(teams)
+
+	for _, teamName := range teams {
+		limits, err := parseUserLimits(entries[teamName])
+		if err != nil {
+			logger.Error("Failed to unmarshal user limits", "team", teamName, "error", err)
+			continue
+		}
+		if len(limits) == 0 {
+			continue
+		}
+		if err := s.checkTeamUserLimits(ctx, teamName, limits); err != nil {
+			logger.Error("Failed to check user limits", "team", teamName, "error", err)
+		}
+	}
+	return nil
+}
+
+// checkTeamUserLimits checks the limits of one team's users and saves their tracking
+func (s *BillingService) checkTeamUserLimits(ctx context.Context, teamName string, limits []*UserLimit) error {
+	team, err := s.tenantSvc.Get(ctx, teamName)
+	if err != nil {
+		return fmt.Errorf("failed to get team: %w", err)
+	}
+	costs, err := s.GetTeamUserCosts(ctx, teamName, "month")
+	if err != nil {
+		return fmt.Errorf("failed to get user costs: %w", err)
+	}
+	gpus, err := s.teamUserGPUs(ctx, teamName)
+	if err != nil {
+		return fmt.Errorf("failed to count GPUs in use: %w", err)
+	}
+
+	spent := make(map[string]float64)
+	for _, cost := range costs {
+		spent[cost.User] += cost.Cost
+	}
+
+	now := time.Now()
+	month := now.UTC().Format("2006-01")
+	for _, limit := range limits {
+		if limit.Month != month {
+			limit.Month = month
+			limit.SpendAlerted = false
+		}
+		limit.Spent = spent[limit.User]
+		if label := UserLabelValue(limit.User); label != limit.User {
+			// Usage sources that only report labels attribute unknown users by label value
+			limit.Spent += spent[label]
+		}
+		limit.GPUs = gpus[limit.User]
+		limit.CheckedAt = &now
+
+		if limit.spendReached(month) && !limit.SpendAlerted {
+			limit.SpendAlerted = true
+			reason := fmt.Sprintf("has spent %.2f of the %.2f monthly limit", limit.Spent, limit.MonthlySpend)
+			if err := s.alertSvc.NotifyUserLimit(ctx, teamName, team.Owners, limit, reason); err != nil {
+				logger.Error("Failed to send user spend notice", "team", teamName, "user", limit.User, "error", err)
+			}
+		}
+
+		gpuReached := limit.MaxGPUs > 0 && limit.GPUs >= limit.MaxGPUs
+		if gpuReached && !limit.GPUAlerted {
+			limit.GPUAlerted = true
+			reason := fmt.Sprintf("holds %g of the %g GPUs allowed at once", limit.GPUs, limit.MaxGPUs)
+			if err := s.alertSvc.NotifyUserLimit(ctx, teamName, team.Owners, limit, reason); err != nil {
+				logger.Error("Failed to send user GPU notice", "team", teamName, "user", limit.User, "error", err)
+			}
+		} else if !gpuReached {
+			limit.GPUAlerted = false
+		}
+	}
+
+	checked := make(map[string]*UserLimit, len(limits))
+	for _, limit := range limits {
+		checked[limit.User] = limit
+	}
+	return s.modifyUserLimits(ctx, teamName, func(stored []*UserLimit) []*UserLimit {
+		for _, limit := range stored {
+			if update, ok := checked[limit.User]; ok {
+				blockedAt := limit.BlockedAt
+				limit.keepTracking(update)
+				limit.BlockedAt = blockedAt
+			}
+		}
+		return stored
+	})
+}
+
+// AdmitUserPod decides whether a new pod created by a user may start in one of a team's
+// projects, given the accelerators it requests. It returns why the pod is rejected, or ""
+// to admit it. Only enforced limits reject pods; spend is the one recorded by the last check.
+// Rejections of dry-run requests are not recorded or alerted.
+func (s *BillingService) AdmitUserPod(ctx context.Context, teamName, user string, pod *corev1.Pod, dryRun bool) (string, error) {
+	limit, err := s.GetUserLimit(ctx, teamName, user)
+	if err != nil || limit == nil || !limit.Enforce {
+		return "", err
+	}
+
+	var reason string
+	if limit.spendReached(time.Now().UTC().Format("2006-01")) {
+		reason = fmt.Sprintf("user %s has spent %.2f of the %.2f monthly limit in team %s", user, limit.Spent, limit.MonthlySpend, teamName)
+	} else if limit.MaxGPUs > 0 {
+		requested := s.acceleratorFilter(ctx).count(pod)
+		if requested == 0 {
+			return "", nil
+		}
+		gpus, err := s.teamUserGPUs(ctx, teamName)
+		if err != nil {
+			return "", err
+		}
+		if gpus[user]+requested > limit.MaxGPUs {
+			reason = fmt.Sprintf("user %s holds %g of the %g GPUs allowed at once in team %s and the pod requests %g more", user, gpus[user], limit.MaxGPUs, teamName, requested)
+		}
+	}
+	if reason == "" {
+		return "", nil
+	}
+
+	logger.Info("Rejecting pod over user limit", "team", teamName, "user", user, "namespace", pod.Namespace, "reason", reason)
+	if !dryRun {
+		s.noticeBlockedPod(ctx, teamName, user, reason)
+	}
+	return reason, nil
+}
+
+// noticeBlockedPod records a rejected pod and alerts the team owners, at most once per
+// UserBlockNoticeInterval for the same user. The alert is sent in the background so
+// admission is not held up by slow notification channels.
+func (s *BillingService) noticeBlockedPod(ctx context.Context, teamName, user, reason string) {
+	now := time.Now()
+	var notify *UserLimit
+	err := s.modifyUserLimits(ctx, teamName, func(limits []*UserLimit) []*UserLimit {
+		notify = nil
+		for _, limit := range limits {
+			if limit.User != user {
+				continue
+			}
+			if limit.BlockedAt == nil || now.Sub(*limit.BlockedAt) >= UserBlockNoticeInterval {
+				limit.BlockedAt = &now
+				copied := *limit
+				notify = &copied
+			}
+		}
+		return limits
+	})
+	if err != nil {
+		logger.Warn("Failed to record rejected pod", "team", teamName, "user", user, "error", err)
+		return
+	}
+	if notify == nil {
+		return
+	}
+
+	go func() {
+		ctx := context.Background()
+		team, err := s.tenantSvc.Get(ctx, teamName)
+		if err != nil {
+			logger.Error("Failed to get team for rejected pod notice", "team", teamName, "error", err)
+			return
+		}
+		if err := s.alertSvc.NotifyUserLimit(ctx, teamName, team.Owners, notify, "had a new pod rejected: "+reason); err != nil {
+			logger.Error("Failed to send rejected pod notice", "team", teamName, "user", user, "error", err)
+		}
+	}()
+}
+
+// teamUserGPUs sums the accelerators held by the pending and running pods in a team's
+// projects, by the user who created them
+func (s *BillingService) teamUserGPUs(ctx context.Context, teamName string) (map[string]float64, error) {
+	projects, err := s.projectSvc.ListByTeam(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := s.acceleratorFilter(ctx)
+	gpus := make(map[string]float64)
+	for _, project := range projects {
+		pods, err := s.k8sClient.ListPods(ctx, project.Name, LabelUser)
+		if err != nil {
+			return nil, err
+		}
+		for i := range pods.Items {
+			pod := &pods.Items[i]
+			if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+				continue
+			}
+			if user := pod.Annotations[AnnotationCreatedBy]; user != "" {
+				gpus[user] += filter.count(pod)
+			}
+		}
+	}
+	return gpus, nil
+}
+
+// count returns how many units of the selected resources a pod holds
+func (f acceleratorFilter) count(pod *corev1.Pod) float64 {
+	var total float64
+	for name, quantity := range podRequests(pod) {
+		if f == nil || f[string(name)] {
+			total += quantity.AsApproximateFloat64()
+		}
+	}
+	return total
+}
+
+// modifyUserLimits applies mutate to a team's stored limits. mutate may be run again if
+// another writer updated the limits first.
+func (s *BillingService) modifyUserLimits(ctx context.Context, teamName string, mutate func(limits []*UserLimit) []*UserLimit) error {
+	return s.store.Modify(ctx, UserLimitsConfigMap, func(entries map[string]string) error {
+		limits, err := parseUserLimits(entries[teamName])
+		if err != nil {
+			return err
+		}
+
+		limits = mutate(limits)
+		if len(limits) == 0 {
+			delete(entries, teamName)
+			return nil
+		}
+		sort.Slice(limits, func(i, j int) bool {
+			return limits[i].User < limits[j].User
+		})
+		raw, err := json.Marshal(limits)
+		if err != nil {
+			return fmt.Errorf("failed to marshal user limits: %w", err)
+		}
+		entries[teamName] = string(raw)
+		return nil
+	})
+}
+
+// parseUserLimits decodes a team's stored limits
+func parseUserLimits(data string) ([]*UserLimit, error) {
+	limits := make([]*UserLimit, 0)
+	if data == "" {
+		return limits, nil
+	}
+	if err := json.Unmarshal([]byte(data), &limits); err != nil {
+		return nil, fmt.Errorf("failed to parse user limits: %w", err)
+	}
+	return limits, nil
+}
@@ -7,19 +7,14 @@ import (
 	"strings"
 	"time"
 
-	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-
-	"github.com/bison/api-server/internal/k8s"
 	"github.com/bison/api-server/internal/opencost"
+	"github.com/bison/api-server/internal/store"
 	"github.com/bison/api-server/pkg/logger"
 )
 
 const (
-	usersConfigMapName      = "bison-users"
-	usersConfigMapNamespace = "bison-system"
-	usersDataKey            = "users.json"
+	usersConfigMapName = "bison-users"
+	usersDataKey       = "users.json"
 )
 
 // User represents a user in the system
@@ -63,14 +58,14 @@ type UserProjectDetail struct {
 
 // UserService handles user operations
 type UserService struct {
-	k8sClient      *k8s.Client
+	store          store.Store
 	opencostClient *opencost.Client
 }
 
 // NewUserService creates a new UserService
-func NewUserService(k8sClient *k8s.Client, opencostClient *opencost.Client) *UserService {
+func NewUserService(st store.Store, opencostClient *opencost.Client) *UserService {
 	return &UserService{
-		k8sClient:      k8sClient,
+		store:          st,
 		opencostClient: opencostClient,
 	}
 }
@@ -171,18 +166,6 @@ func (s *UserService) GetDetail(ctx context.Context, email string, tenantSvc *Te
 func (s *UserService) Create(ctx context.Context, user *User) error {
 	logger.Info("Creating user", "email", user.Email)
 
-	userData, err := s.loadUserData(ctx)
-	if err != nil {
-		return err
-	}
-
-	// Check if user already exists
-	for _, u := range userData.Users {
-		if u.Email == user.Email {
-			return fmt.Errorf("user already exists: %s", user.Email)
-		}
-	}
-
 	// Set defaults
 	if user.Source == "" {
 		user.Source = "manual"
@@ -194,98 +177,85 @@ func (s *UserService) Create(ctx context.Context, user *User) error {
 		user.CreatedAt = time.Now().UTC().Format(time.RFC3339)
 	}
 
-	userData.Users = append(userData.Users, *user)
+	return s.modifyUserData(ctx, func(userData *UserData) error {
+		// Check if user already exists
+		for _, u := range userData.Users {
+			if u.Email == user.Email {
+				return fmt.Errorf("user already exists: %s", user.Email)
+			}
+		}
 
-	return s.saveUserData(ctx, userData)
+		userData.Users = append(userData.Users, *user)
+		return nil
+	})
 }
 
 // Update updates an existing user
 func (s *UserService) Update(ctx context.Context, email string, updates *User) error {
 	logger.Info("Updating user", "email", email)
 
-	userData, err := s.loadUserData(ctx)
-	if err != nil {
-		return err
-	}
-
-	found := false
-	for i, u := range userData.Users {
-		if u.Email == email {
-			// Preserve immutable fields
-			updates.Email = email
-			updates.CreatedAt = u.CreatedAt
-			if updates.Source == "" {
-				updates.Source = u.Source
-			}
-			if updates.LastLogin == "" {
-				updates.LastLogin = u.LastLogin
+	return s.modifyUserData(ctx, func(userData *UserData) error {
+		for i, u := range userData.Users {
+			if u.Email == email {
+				// Preserve immutable fields
+				updated := *updates
+				updated.Email = email
+				updated.CreatedAt = u.CreatedAt
+				if updated.Source == "" {
+					updated.Source = u.Source
+				}
+				if updated.LastLogin == "" {
+					updated.LastLogin = u.LastLogin
+				}
+				userData.Users[i] = updated
+				return nil
 			}
-			userData.Users[i] = *updates
-			found = true
-			break
 		}
-	}
 
-	if !found {
 		return fmt.Errorf("user not found: %s", email)
-	}
-
-	return s.saveUserData(ctx, userData)
+	})
 }
 
 // Delete deletes a user
 func (s *UserService) Delete(ctx context.Context, email string) error {
 	logger.Info("Deleting user", "email", email)
 
-	userData, err := s.loadUserData(ctx)
-	if err != nil {
-		return err
-	}
-
-	found := false
-	for i, u := range userData.Users {
-		if u.Email == email {
-			userData.Users = append(userData.Users[:i], userData.Users[i+1:]...)
-			found = true
-			break
+	return s.modifyUserData(ctx, func(userData *UserData) error {
+		for i, u := range userData.Users {
+			if u.Email == email {
+				userData.Users = append(userData.Users[:i], userData.Users[i+1:]...)
+				return nil
+			}
 		}
-	}
 
-	if !found {
 		return fmt.Errorf("user not found: %s", email)
-	}
-
-	return s.saveUserData(ctx, userData)
+	})
 }
 
 // UpdateLastLogin updates the last login time for a user
 func (s *UserService) UpdateLastLogin(ctx context.Context, email string) error {
 	logger.Debug("Updating last login", "email", email)
 
-	userData, err := s.loadUserData(ctx)
-	if err != nil {
-		return err
-	}
-
-	for i, u := range userData.Users {
-		if u.Email == email {
-			userData.Users[i].LastLogin = time.Now().UTC().Format(time.RFC3339)
-			return s.saveUserData(ctx, userData)
+	return s.modifyUserData(ctx, func(userData *UserData) error {
+		for i, u := range userData.Users {
+			if u.Email == email {
+				userData.Users[i].LastLogin = time.Now().UTC().Format(time.RFC3339)
+				return nil
+			}
 		}
-	}
 
-	// User not found - create if OIDC login
-	newUser := User{
-		Email:       email,
-		DisplayName: extractDisplayName(email),
-		Source:      "oidc",
-		Status:      "active",
-		CreatedAt:   time.Now().UTC().Format(time.RFC3339),
-		LastLogin:   time.Now().UTC().Format(time.RFC3339),
-	}
-	userData.Users = append(userData.Users, newUser)
-
-	return s.saveUserData(ctx, userData)
+		// User not found - create if OIDC login
+		newUser := User{
+			Email:       email,
+			DisplayName: extractDisplayName(email),
+			Source:      "oidc",
+			Status:      "active",
+			CreatedAt:   time.Now().UTC().Format(time.RFC3339),
+			LastLogin:   time.Now().UTC().Format(time.RFC3339),
+		}
+		userData.Users = append(userData.Users, newUser)
+		return nil
+	})
 }
 
 // SetStatus sets the status of a user (active/disabled)
@@ -296,19 +266,16 @@ func (s *UserService) SetStatus(ctx context.Context, email string, status string
 		return fmt.Errorf("invalid status: %s", status)
 	}
 
-	userData, err := s.loadUserData(ctx)
-	if err != nil {
-		return err
-	}
-
-	for i, u := range userData.Users {
-		if u.Email == email {
-			userData.Users[i].Status = status
-			return s.saveUserData(ctx, userData)
+	return s.modifyUserData(ctx, func(userData *UserData) error {
+		for i, u := range userData.Users {
+			if u.Email == email {
+				userData.Users[i].Status = status
+				return nil
+			}
 		}
-	}
 
-	return fmt.Errorf("user not found: %s", email)
+		return fmt.Errorf("user not found: %s", email)
+	})
 }
 
 // Search searches users by query
@@ -368,9 +335,9 @@ func (s *UserService) GetUsage(ctx context.Context, email, window string) (*Usag
 		return &UsageData{Name: email}, nil
 	}
 
-	// Find this user's usage
+	// Find this user's usage; pods carry the username sanitized to a label value
 	for _, summary := range summaries {
-		if summary.Name == email {
+		if summary.Name == email || summary.Name == UserLabelValue(email) {
 			return &UsageData{
 				Name:         summary.Name,
 				CPUCoreHours: summary.CPUCoreHours,
@@ -388,18 +355,14 @@ func (s *UserService) GetUsage(ctx context.Context, email, window string) (*Usag
 	return &UsageData{Name: email}, nil
 }
 
-// loadUserData loads user data from ConfigMap
+// loadUserData loads user data from the store
 func (s *UserService) loadUserData(ctx context.Context) (*UserData, error) {
-	cm, err := s.k8sClient.GetConfigMap(ctx, usersConfigMapNamespace, usersConfigMapName)
+	entries, err := s.store.Load(ctx, usersConfigMapName)
 	if err != nil {
-		if errors.IsNotFound(err) {
-			// Return empty data if ConfigMap doesn't exist
-			return &UserData{Users: []User{}}, nil
-		}
-		return nil, fmt.Errorf("failed to get users ConfigMap: %w", err)
+		return nil, fmt.Errorf("failed to load users: %w", err)
 	}
 
-	data := cm.Data[usersDataKey]
+	data := entries[usersDataKey]
 	if data == "" {
 		return &UserData{Users: []User{}}, nil
 	}
@@ -412,37 +375,28 @@ func (s *UserService) loadUserData(ctx context.Context) (*UserData, error) {
 	return &userData, nil
 }
 
-// saveUserData saves user data to ConfigMap
-func (s *UserService) saveUserData(ctx context.Context, userData *UserData) error {
-	data, err := json.Marshal(userData)
-	if err != nil {
-		return fmt.Errorf("failed to marshal users data: %w", err)
-	}
-
-	cm, err := s.k8sClient.GetConfigMap(ctx, usersConfigMapNamespace, usersConfigMapName)
-	if err != nil {
-		if errors.IsNotFound(err) {
-			// Create ConfigMap if it doesn't exist
-			newCM := &corev1.ConfigMap{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      usersConfigMapName,
-					Namespace: usersConfigMapNamespace,
-				},
-				Data: map[string]string{
-					usersDataKey: string(data),
-				},
+// modifyUserData applies mutate to the stored user data. The data is
+// re-read and mutate re-run whenever another writer updated it first.
+func (s *UserService) modifyUserData(ctx context.Context, mutate func(userData *UserData) error) error {
+	return s.store.Modify(ctx, usersConfigMapName, func(entries map[string]string) error {
+		userData := &UserData{Users: []User{}}
+		if data := entries[usersDataKey]; data != "" {
+			if err := json.Unmarshal([]byte(data), userData); err != nil {
+				return fmt.Errorf("failed to parse users data: %w", err)
 			}
-			return s.k8sClient.CreateConfigMap(ctx, usersConfigMapNamespace, newCM)
 		}
-		return fmt.Errorf("failed to get users ConfigMap: %w", err)
-	}
 
-	if cm.Data == nil {
-		cm.Data = make(map[string]string)
-	}
-	cm.Data[usersDataKey] = string(data)
+		if err := mutate(userData); err != nil {
+			return err
+		}
 
-	return s.k8sClient.UpdateConfigMap(ctx, usersConfigMapNamespace, cm)
+		data, err := json.Marshal(userData)
+		if err != nil {
+			return fmt.Errorf("failed to marshal users data: %w", err)
+		}
+		entries[usersDataKey] = string(data)
+		return nil
+	})
 }
 
 // Helper function to extract display name from email
@@ -0,0 +1,88 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/bison/api-server/internal/k8s"
+)
+
+// ConfigMapStore keeps each collection in a ConfigMap. Values share the
+// ConfigMap's 1 MiB object limit, so large collections are truncated by the
+// services that own them.
+type ConfigMapStore struct {
+	k8sClient *k8s.Client
+	namespace string
+}
+
+// NewConfigMapStore creates a store backed by ConfigMaps in namespace
+func NewConfigMapStore(k8sClient *k8s.Client, namespace string) *ConfigMapStore {
+	return &ConfigMapStore{
+		k8sClient: k8sClient,
+		namespace: namespace,
+	}
+}
+
+// Load returns the data of the collection's ConfigMap
+func (s *ConfigMapStore) Load(ctx context.Context, collection string) (map[string]string, error) {
+	cm, err := s.k8sClient.GetConfigMap(ctx, s.namespace, collection)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return map[string]string{}, nil
+		}
+		return nil, fmt.Errorf("failed to get configmap %s: %w", collection, err)
+	}
+
+	if cm.Data == nil {
+		return map[string]string{}, nil
+	}
+	return cm.Data, nil
+}
+
+// Modify updates the collection's ConfigMap, retrying on resourceVersion conflicts
+func (s *ConfigMapStore) Modify(ctx context.Context, collection string, mutate func(data map[string]string) error) error {
+	labels := map[string]string{
+		"app.kubernetes.io/name": "bison",
+	}
+	return s.k8sClient.ModifyConfigMap(ctx, s.namespace, collection, labels, func(cm *corev1.ConfigMap) error {
+		return mutate(cm.Data)
+	})
+}
+
+// Collections returns the names of the ConfigMaps in the namespace that start with prefix
+func (s *ConfigMapStore) Collections(ctx context.Context, prefix string) ([]string, error) {
+	list, err := s.k8sClient.ListConfigMaps(ctx, s.namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list configmaps: %w", err)
+	}
+
+	var collections []string
+	for _, cm := range list.Items {
+		if strings.HasPrefix(cm.Name, prefix) {
+			collections = append(collections, cm.Name)
+		}
+	}
+	return collections, nil
+}
+
+// Delete deletes the collection's ConfigMap
+func (s *ConfigMapStore) Delete(ctx context.Context, collection string) error {
+	if err := s.k8sClient.DeleteConfigMap(ctx, s.namespace, collection); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete configmap %s: %w", collection, err)
+	}
+	return nil
+}
+
+// Bounded is true: a ConfigMap holds at most 1 MiB
+func (s *ConfigMapStore) Bounded() bool {
+	return true
+}
+
+// Close is a no-op for the ConfigMap store
+func (s *ConfigMapStore) Close() error {
+	return nil
+}
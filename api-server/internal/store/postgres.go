@@ -0,0 +1,200 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib" // Registers the "pgx" database/sql driver
+
+	"github.com/bison/api-server/pkg/logger"
+)
+
+const postgresSchema = `
+CREATE TABLE IF NOT EXISTS bison_collections (
+	name TEXT PRIMARY KEY
+);
+CREATE TABLE IF NOT EXISTS bison_state (
+	collection TEXT NOT NULL,
+	key        TEXT NOT NULL,
+	value      TEXT NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY (collection, key)
+);
+`
+
+// PostgresStore keeps each value in its own row, so collections are not
+// bound by the ConfigMap size limit. Writers of the same collection are
+// serialized by a row lock on the collection.
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore connects to a PostgreSQL-compatible database and creates
+// the schema if needed
+func NewPostgresStore(dsn string) (*PostgresStore, error) {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	if _, err := db.ExecContext(ctx, postgresSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create schema: %w", err)
+	}
+
+	logger.Info("Postgres store initialized")
+	return &PostgresStore{db: db}, nil
+}
+
+// Load returns all values of a collection
+func (s *PostgresStore) Load(ctx context.Context, collection string) (map[string]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT key, value FROM bison_state WHERE collection = $1`, collection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", collection, err)
+	}
+	defer rows.Close()
+
+	return scanValues(rows)
+}
+
+// Modify applies mutate inside a transaction holding the collection lock and
+// writes only the keys that changed
+func (s *PostgresStore) Modify(ctx context.Context, collection string, mutate func(data map[string]string) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `INSERT INTO bison_collections (name) VALUES ($1) ON CONFLICT DO NOTHING`, collection); err != nil {
+		return fmt.Errorf("failed to register collection %s: %w", collection, err)
+	}
+	if _, err := tx.ExecContext(ctx, `SELECT name FROM bison_collections WHERE name = $1 FOR UPDATE`, collection); err != nil {
+		return fmt.Errorf("failed to lock collection %s: %w", collection, err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT key, value FROM bison_state WHERE collection = $1`, collection)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", collection, err)
+	}
+	before, err := scanValues(rows)
+	rows.Close()
+	if err != nil {
+		return err
+	}
+
+	after := make(map[string]string, len(before))
+	for key, value := range before {
+		after[key] = value
+	}
+	if err := mutate(after); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for key, value := range after {
+		if old, ok := before[key]; ok && old == value {
+			continue
+		}
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO bison_state (collection, key, value, updated_at) VALUES ($1, $2, $3, $4)
+			ON CONFLICT (collection, key) DO UPDATE SET value = EXCLUDED.value, updated_at = EXCLUDED.updated_at`,
+			collection, key, value, now)
+		if err != nil {
+			return fmt.Errorf("failed to write %s/%s: %w", collection, key, err)
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; ok {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM bison_state WHERE collection = $1 AND key = $2`, collection, key); err != nil {
+			return fmt.Errorf("failed to delete %s/%s: %w", collection, key, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit %s: %w", collection, err)
+	}
+	return nil
+}
+
+// Collections returns the names of the registered collections that start with prefix
+func (s *PostgresStore) Collections(ctx context.Context, prefix string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT name FROM bison_collections WHERE left(name, length($1)) = $1`, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collections: %w", err)
+	}
+	defer rows.Close()
+
+	var collections []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		collections = append(collections, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+	return collections, nil
+}
+
+// Delete removes a collection's values and registration while holding its lock
+func (s *PostgresStore) Delete(ctx context.Context, collection string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT name FROM bison_collections WHERE name = $1 FOR UPDATE`, collection); err != nil {
+		return fmt.Errorf("failed to lock collection %s: %w", collection, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM bison_state WHERE collection = $1`, collection); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", collection, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM bison_collections WHERE name = $1`, collection); err != nil {
+		return fmt.Errorf("failed to unregister collection %s: %w", collection, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit %s: %w", collection, err)
+	}
+	return nil
+}
+
+// Bounded is false: each value is its own row
+func (s *PostgresStore) Bounded() bool {
+	return false
+}
+
+// Close closes the database connection pool
+func (s *PostgresStore) Close() error {
+	return s.db.Close()
+}
+
+func scanValues(rows *sql.Rows) (map[string]string, error) {
+	data := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		data[key] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+	return data, nil
+}
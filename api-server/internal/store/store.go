@@ -0,0 +1,85 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bison/api-server/internal/config"
+	"github.com/bison/api-server/internal/k8s"
+)
+
+// Storage backends
+const (
+	BackendConfigMap = "configmap"
+	BackendPostgres  = "postgres"
+)
+
+// Namespace holds the ConfigMaps of the ConfigMap backend
+const Namespace = "bison-system"
+
+// Store persists Bison state as named collections of keyed string values.
+// A collection maps to one ConfigMap in the ConfigMap backend, so collection
+// names are the historical ConfigMap names.
+type Store interface {
+	// Load returns all values of a collection; a missing collection is empty
+	Load(ctx context.Context, collection string) (map[string]string, error)
+
+	// Modify applies mutate to the values of a collection atomically. Keys
+	// added, changed or deleted in the map are persisted. mutate may be called
+	// more than once when concurrent writers conflict, so it must not have
+	// side effects outside the map.
+	Modify(ctx context.Context, collection string, mutate func(data map[string]string) error) error
+
+	// Collections returns the names of the stored collections that start with prefix
+	Collections(ctx context.Context, prefix string) ([]string, error)
+
+	// Delete removes a collection and all its values; a missing collection is
+	// not an error
+	Delete(ctx context.Context, collection string) error
+
+	// Bounded reports whether a collection's size is limited, in which case
+	// services cap the history they keep in it
+	Bounded() bool
+
+	// Close releases backend resources
+	Close() error
+}
+
+// New creates the store selected by the configuration
+func New(cfg *config.Config, k8sClient *k8s.Client) (Store, error) {
+	switch cfg.StorageBackend {
+	case BackendConfigMap:
+		return NewConfigMapStore(k8sClient, Namespace), nil
+	case BackendPostgres:
+		return NewPostgresStore(cfg.StorageDSN)
+	default:
+		return nil, fmt.Errorf("unknown storage backend: %s", cfg.StorageBackend)
+	}
+}
+
+// Copy copies the given collections from src to dst, overwriting keys that
+// already exist in dst. It returns the number of keys copied.
+func Copy(ctx context.Context, src, dst Store, collections []string) (int, error) {
+	copied := 0
+	for _, collection := range collections {
+		data, err := src.Load(ctx, collection)
+		if err != nil {
+			return copied, fmt.Errorf("failed to load %s: %w", collection, err)
+		}
+		if len(data) == 0 {
+			continue
+		}
+
+		err = dst.Modify(ctx, collection, func(existing map[string]string) error {
+			for key, value := range data {
+				existing[key] = value
+			}
+			return nil
+		})
+		if err != nil {
+			return copied, fmt.Errorf("failed to write %s: %w", collection, err)
+		}
+		copied += len(data)
+	}
+	return copied, nil
+}
@@ -49,7 +49,9 @@ const BillingConfig: React.FC = () => {
   const handleSave = async () => {
     const values = await form.validateFields();
 
+    // Keep settings not edited on this page (e.g. time-of-use bands)
     updateMutation.mutate({
+      ...configData?.data,
       ...values,
       pricing: configData?.data?.pricing || {},
     });
@@ -46,6 +46,7 @@ const SECTION_LABELS: Record<string, string> = {
   resources: '资源配置',
   controlPlane: '控制面配置',
   initScripts: '节点初始化脚本',
+  teamPricing: '团队定价策略',
 };
 
 const ALL_SECTIONS = Object.keys(SECTION_LABELS);
@@ -256,7 +257,7 @@ const ConfigTransfer: React.FC = () => {
   };
 
   const renderSectionPreview = (sectionKey: string, preview: SectionPreview) => {
-    const isArraySection = sectionKey === 'resources' || sectionKey === 'initScripts';
+    const isArraySection = sectionKey === 'resources' || sectionKey === 'initScripts' || sectionKey === 'teamPricing';
 
     return (
       <div>
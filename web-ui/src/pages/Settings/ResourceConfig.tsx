@@ -34,6 +34,7 @@ import {
   ResourceDefinition,
   ResourceCategory,
   DiscoveredResource,
+  BILLING_ONLY_RESOURCES,
 } from '../../services/api';
 
 const { Title, Text } = Typography;
@@ -152,7 +153,7 @@ const ResourceConfig: React.FC = () => {
       category: values.category || 'other',
       enabled: true,
       sortOrder: configs.length + 1,
-      showInQuota: true,
+      showInQuota: !BILLING_ONLY_RESOURCES.includes(values.name!),
       price: values.price || 0,
     };
     setConfigs(prev => [...prev, newConfig]);
@@ -491,7 +492,7 @@ const ResourceConfig: React.FC = () => {
             name="name"
             label="资源名称"
             rules={[{ required: true, message: '请输入资源名称' }]}
-            extra="K8s 资源名，例如: custom.io/resource"
+            extra="K8s 资源名，例如: custom.io/resource。计费专用资源: bison.io/persistent-storage（GB·时）、bison.io/network-egress（GB）、bison.io/load-balancer（个·时）"
           >
             <Input placeholder="资源名称" />
           </Form.Item>
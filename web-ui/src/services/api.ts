@@ -86,12 +86,17 @@ export interface OwnerRef {
 
 export type TeamMode = 'shared' | 'exclusive';
 
+// Reservation billing charges exclusive teams per node-hour of their pool instead of usage;
+// committed billing charges shared teams for their quota plus usage above it
+export type BillingMode = 'usage' | 'reservation' | 'committed';
+
 export interface Team {
   name: string;
   displayName: string;
   description?: string;
   owners: OwnerRef[];
   mode: TeamMode;
+  billingMode?: BillingMode; // Defaults to 'usage'
   exclusiveNodes?: string[];
   nodeSelector?: Record<string, string>;
   quota: Record<string, string>; // Dynamic quota
@@ -122,30 +127,152 @@ export interface Balance {
   teamName: string;
   amount: number;
   lastUpdated: string;
-  overdueAt?: string;           // When balance first went negative
+  overdueAt?: string;           // When balance first went beyond the credit limit
   estimatedOverdueAt?: string;  // Predicted time when balance will go negative
   dailyConsumption?: number;    // Average daily consumption
   graceRemaining?: string;      // Remaining grace period (e.g., "2天 3小时")
+  creditLimit?: number;         // Effective overdraft allowed below zero
+  gracePeriodValue?: number;    // Effective grace period
+  gracePeriodUnit?: string;     // "hours" or "days"
+  neverSuspend?: boolean;       // Never suspended automatically
+  paidAmount: number;           // Part of amount that is paid funds
+  grantedAmount: number;        // Part of amount that is unexpired credit grants
+}
+
+export interface CreditGrant {
+  id: string;
+  amount: number;
+  remaining: number;
+  source: string;
+  reason?: string;
+  expiresAt: string;
+  expiredAt?: string;
+  createdAt: string;
+  createdBy: string;
 }
 
+export interface CreditPolicy {
+  creditLimit: number;          // Overdraft allowed below zero before the grace period starts
+  gracePeriodValue?: number;    // Overrides the global grace period when gracePeriodUnit is set
+  gracePeriodUnit?: 'hours' | 'days';
+  neverSuspend: boolean;
+  updatedAt?: string;
+  updatedBy?: string;
+}
+
+export interface ProjectBudget {
+  project: string;
+  team: string;
+  monthlyAmount: number;
+  warnPercent: number;          // Warn team owners at this share of the budget; 0 disables the warning
+  hardStop: boolean;            // Suspend the project once spend reaches the budget
+  updatedAt?: string;
+  updatedBy?: string;
+  month?: string;
+  spent: number;
+  checkedAt?: string;
+  warned: boolean;
+  exceeded: boolean;
+  suspended: boolean;           // Suspended by its budget
+  suspendedAt?: string;
+}
+
+export interface UserCost {
+  user: string;                 // Creator recorded at admission; empty for unattributed pods
+  cost: number;
+  gpuHours: number;
+  projects: Record<string, number>;
+}
+
+export interface UserLimit {
+  user: string;
+  monthlySpend: number;         // 0 = no limit
+  maxGPUs: number;              // Accelerators held at once; 0 = no limit
+  enforce: boolean;             // Reject new pods from the user at a limit
+  updatedAt?: string;
+  updatedBy?: string;
+  month?: string;
+  spent: number;
+  gpus: number;
+  checkedAt?: string;
+  spendAlerted: boolean;
+  gpuAlerted: boolean;
+  blockedAt?: string;
+}
+
+export type AdjustmentType = 'refund' | 'credit' | 'manual_charge' | 'correction';
+
 export interface RechargeRecord {
   id: string;
   timestamp: string;
-  type: 'recharge' | 'deduction' | 'auto_recharge';
+  type: 'recharge' | 'deduction' | 'auto_recharge' | 'transfer_out' | 'transfer_in' | 'grant' | 'grant_expiry' | 'billing_replay' | AdjustmentType;
   amount: number;
   operator: string;
   reason?: string;
   balance: number;
+  refId?: string;  // Ledger entry an adjustment applies to, or the other side of a transfer
+  peer?: string;   // Other team of a transfer
+}
+
+export interface Adjustment {
+  type: AdjustmentType;
+  amount: number;   // Positive, except corrections which are signed
+  refId?: string;   // Required for refunds and corrections
+  reason: string;
 }
 
 export interface AutoRechargeConfig {
   enabled: boolean;
   amount: number;
+  trigger?: 'schedule' | 'threshold';
   schedule: 'weekly' | 'monthly';
   dayOfWeek?: number;
   dayOfMonth?: number;
   nextExecution: string;
   lastExecuted?: string;
+  threshold?: number;
+  monthlyCap?: number;
+  minIntervalMinutes?: number;
+  month?: string;
+  monthTotal?: number;
+}
+
+export interface SuspendedObject {
+  kind: string;        // Deployment, StatefulSet, Job, CronJob, Pod, ResourceQuota or a custom kind
+  name: string;
+  action: 'scale' | 'suspend' | 'delete' | 'freeze';
+  previous?: string;   // Value before suspension
+  group?: string;
+  version?: string;
+  resource?: string;
+  path?: string;
+}
+
+export interface ExemptedObject {
+  kind: string;    // Workload kind, or Namespace
+  name: string;
+  reason: string;
+}
+
+export interface SuspensionRecord {
+  namespace: string;
+  team: string;
+  strategy: 'scale-down' | 'freeze' | 'evict-gpu-only';
+  suspendedAt: string;
+  changes: SuspendedObject[];
+  exempted?: ExemptedObject[];  // Left running by the latest suspension
+}
+
+export interface TeamPricingPolicy {
+  team: string;
+  discountPercent: number;                     // Percent off every resource
+  resourceDiscounts?: Record<string, number>;  // Percent off per resource (or "<resource>:<model>")
+  priceOverrides?: Record<string, number>;     // Fixed price per unit per hour, 0 = free
+  validFrom?: string;
+  validUntil?: string;
+  remark?: string;
+  updatedAt?: string;
+  updatedBy?: string;
 }
 
 export const getTeamBalance = (name: string) =>
@@ -154,6 +281,14 @@ export const rechargeTeam = (name: string, data: { amount: number; remark?: stri
   api.post(`/teams/${name}/recharge`, data);
 export const getRechargeHistory = (name: string) =>
   api.get<{ items: RechargeRecord[] }>(`/teams/${name}/balance/history`);
+export const transferBalance = (name: string, data: { toTeam: string; amount: number; reason?: string }) =>
+  api.post<RechargeRecord>(`/teams/${name}/transfer`, data);
+export const getCreditGrants = (name: string) =>
+  api.get<{ items: CreditGrant[] }>(`/teams/${name}/grants`);
+export const grantCredit = (name: string, grant: { amount: number; source: string; expiresAt: string; reason?: string }) =>
+  api.post<CreditGrant>(`/teams/${name}/grants`, grant);
+export const adjustBalance = (name: string, adjustment: Adjustment) =>
+  api.post<RechargeRecord>(`/teams/${name}/adjustments`, adjustment);
 export const getTeamBill = (name: string, window = '7d') =>
   api.get(`/teams/${name}/bill`, { params: { window } });
 export const getAutoRechargeConfig = (name: string) =>
@@ -164,6 +299,22 @@ export const suspendTeam = (name: string) =>
   api.post(`/teams/${name}/suspend`);
 export const resumeTeam = (name: string) =>
   api.post(`/teams/${name}/resume`);
+export const getCreditPolicy = (name: string) =>
+  api.get<CreditPolicy>(`/teams/${name}/credit-policy`);
+export const updateCreditPolicy = (name: string, policy: CreditPolicy) =>
+  api.put<CreditPolicy>(`/teams/${name}/credit-policy`, policy);
+export const deleteCreditPolicy = (name: string) =>
+  api.delete(`/teams/${name}/credit-policy`);
+export const getTeamSuspension = (name: string) =>
+  api.get<{ items: SuspensionRecord[] }>(`/teams/${name}/suspension`);
+export const getTeamPricing = (name: string) =>
+  api.get<TeamPricingPolicy>(`/teams/${name}/pricing`);
+export const updateTeamPricing = (name: string, policy: TeamPricingPolicy) =>
+  api.put<TeamPricingPolicy>(`/teams/${name}/pricing`, policy);
+export const deleteTeamPricing = (name: string) =>
+  api.delete(`/teams/${name}/pricing`);
+export const listTeamPricing = () =>
+  api.get<{ items: TeamPricingPolicy[] }>('/settings/billing/team-pricing');
 
 // Project APIs (Namespaces)
 export interface ProjectMember {
@@ -211,6 +362,22 @@ export const getProjectUsage = (name: string) =>
   api.get<ProjectUsage>(`/projects/${name}/usage`);
 export const deleteProject = (name: string) => 
   api.delete(`/projects/${name}`);
+export const getProjectBudget = (name: string) =>
+  api.get<ProjectBudget>(`/projects/${name}/budget`);
+export const updateProjectBudget = (name: string, budget: Pick<ProjectBudget, 'monthlyAmount' | 'warnPercent' | 'hardStop'>) =>
+  api.put<ProjectBudget>(`/projects/${name}/budget`, budget);
+export const deleteProjectBudget = (name: string) =>
+  api.delete(`/projects/${name}/budget`);
+export const getTeamProjectBudgets = (name: string) =>
+  api.get<{ items: ProjectBudget[] }>(`/teams/${name}/project-budgets`);
+export const getTeamUserCosts = (name: string, window = 'month') =>
+  api.get<{ items: UserCost[] }>(`/teams/${name}/user-costs`, { params: { window } });
+export const getUserLimits = (name: string) =>
+  api.get<{ items: UserLimit[] }>(`/teams/${name}/user-limits`);
+export const updateUserLimit = (name: string, user: string, limit: Pick<UserLimit, 'monthlySpend' | 'maxGPUs' | 'enforce'>) =>
+  api.put<UserLimit>(`/teams/${name}/user-limits/${encodeURIComponent(user)}`, limit);
+export const deleteUserLimit = (name: string, user: string) =>
+  api.delete(`/teams/${name}/user-limits/${encodeURIComponent(user)}`);
 
 // Project Workloads APIs
 export interface WorkloadSummary {
@@ -309,6 +476,12 @@ export interface UsageData {
   ramCost: number;
   gpuCost: number;
   minutes: number;
+  pvGBHours?: number;
+  pvCost?: number;
+  networkGB?: number;         // Egress
+  networkCost?: number;
+  loadBalancerCost?: number;
+  overheadCost?: number;      // Share of idle and shared-namespace cost
 }
 
 export interface UsageReport {
@@ -477,6 +650,65 @@ export interface BillingConfig {
   pricing: Record<string, ResourcePrice>;
   gracePeriodValue?: number;  // Grace period value (e.g., 3)
   gracePeriodUnit?: string;   // Grace period unit: "hours" or "days"
+  timeOfUse?: TimeOfUsePricing;
+  overhead?: OverheadDistribution;
+  suspension?: SuspensionConfig;
+  invoicing?: InvoiceConfig;
+  reservationPricing?: Record<string, ResourcePrice>; // Quota price for committed billing, memory per GB
+}
+
+export interface InvoiceConfig {
+  enabled: boolean;
+  period?: 'monthly' | 'weekly';
+  timezone?: string;      // IANA zone periods are cut in; empty = UTC
+  numberPrefix?: string;  // Defaults to "INV"
+  autoFinalize?: boolean; // Lock invoices as soon as they are generated
+}
+
+export interface SuspendableResource {
+  group: string;
+  version: string;
+  resource: string;      // Plural resource name, e.g. pytorchjobs
+  kind: string;          // e.g. PyTorchJob
+  suspendPath?: string;  // Boolean field set to true, e.g. spec.runPolicy.suspend
+  replicasPath?: string; // Integer field set to 0
+}
+
+export interface SuspensionConfig {
+  strategy?: 'scale-down' | 'freeze' | 'evict-gpu-only';  // Default scale-down
+  customResources?: SuspendableResource[];  // Omit to use the built-in Kubeflow and Ray kinds
+  exemptions?: SuspensionExemption[];
+}
+
+export interface SuspensionExemption {
+  namespace: string;
+  kind?: string;    // e.g. Deployment; empty matches any kind
+  name?: string;    // Empty exempts the whole namespace
+  reason?: string;
+}
+
+export interface OverheadDistribution {
+  enabled: boolean;
+  distributeIdle: boolean;        // Include unallocated cluster capacity
+  sharedNamespaces: string[];     // e.g. kube-system, monitoring, bison-system
+  method: 'proportional' | 'weighted';
+  weights?: Record<string, number>;  // Team weights for the weighted method
+  applyToBilling: boolean;        // Also deduct the shares from team balances
+}
+
+export interface PriceBand {
+  name: string;        // e.g. "peak", "off-peak", "weekend"
+  days: 'all' | 'weekday' | 'weekend';
+  startHour: number;   // First hour of the band, 0-23
+  endHour: number;     // Exclusive end hour, 0-24; wraps past midnight if <= startHour
+  multiplier: number;  // Applied to base prices
+}
+
+export interface TimeOfUsePricing {
+  enabled: boolean;
+  timezone: string;    // IANA zone, e.g. "Asia/Shanghai"
+  holidays: string[];  // YYYY-MM-DD, billed as weekend days
+  bands: PriceBand[];
 }
 
 export const getBillingConfig = () => 
@@ -496,6 +728,7 @@ export interface NotifyChannel {
 export interface AlertConfig {
   balanceThreshold: number;
   channels: NotifyChannel[];
+  suspensionCountdown?: number[] | null;  // Hours before suspension to warn owners; null uses 72, 24, 1
 }
 
 export interface Alert {
@@ -508,6 +741,7 @@ export interface Alert {
   sent: boolean;
   sentAt?: string;
   channels?: string[];
+  recipients?: OwnerRef[];  // Team owners the alert is addressed to
 }
 
 export const getAlertConfig = () =>
@@ -562,7 +796,12 @@ export interface Report {
   generatedAt: string;
   totalCost: number;
   costByResource: Record<string, number>;
+  costByBand?: Record<string, number>;  // Cost by time-of-use band
+  discount?: number;                    // Savings from the team pricing policy
+  overheadCost?: number;                // Share of idle and shared-namespace cost
   usageSummary?: UsageData;
+  adjustments?: RechargeRecord[];       // Refunds, credits, manual charges and corrections in the window
+  netAdjustment?: number;
 }
 
 export interface TeamCostRank {
@@ -581,6 +820,82 @@ export interface SummaryReport {
   topTeams: TeamCostRank[];
 }
 
+// Billing simulation and replay APIs
+export interface TeamCharge {
+  team: string;
+  cost: number;
+  overheadCost?: number;
+  nodeHours?: number; // Reserved node-hours charged, for reservation billing
+  committedCost?: number; // Quota at reservation prices, for committed billing
+  burstCost?: number;     // Usage above the quota, for committed billing
+}
+
+export interface BilledPeriod {
+  period: string;
+  start: string;
+  end: string;
+  charges: TeamCharge[];
+  skipped?: { team: string; cost: number; reason: string }[];
+}
+
+export interface BillingRun {
+  start: string;
+  end: string;
+  dryRun: boolean;
+  periods: BilledPeriod[];
+  teams: TeamCharge[];
+  total: number;
+}
+
+export const simulateBilling = (start: string, end: string) =>
+  api.post<BillingRun>('/billing/simulate', { start, end });
+export const replayBilling = (start: string, end: string, reason?: string) =>
+  api.post<BillingRun>('/billing/replay', { start, end, reason });
+
+// Invoice APIs
+export interface InvoiceLineItem {
+  project: string;
+  node?: string; // Reserved node, for reservation-billed teams
+  committed?: boolean; // Committed quota, for committed-billed teams
+  resource: string;
+  cost: number;
+}
+
+export interface Invoice {
+  number: string;
+  team: string;
+  period: string;         // e.g. "2026-09" or "2026-W38"
+  periodStart: string;
+  periodEnd: string;
+  status: 'draft' | 'finalized';
+  currency: string;
+  currencySymbol: string;
+  lineItems: InvoiceLineItem[];
+  subtotal: number;
+  discount?: number;      // Already included in the line items
+  overheadCost?: number;
+  total: number;
+  openingBalance: number;
+  closingBalance: number;
+  charged: number;        // Usage deductions recorded in the period
+  recharges: RechargeRecord[];
+  adjustments?: RechargeRecord[];
+  generatedAt: string;
+  finalizedAt?: string;
+  finalizedBy?: string;
+}
+
+export const getInvoices = (team: string) =>
+  api.get<{ items: Invoice[] }>(`/teams/${team}/invoices`);
+export const generateInvoice = (team: string, date?: string) =>
+  api.post<Invoice>(`/teams/${team}/invoices`, { date });
+export const getInvoice = (team: string, number: string) =>
+  api.get<Invoice>(`/teams/${team}/invoices/${number}`);
+export const finalizeInvoice = (team: string, number: string) =>
+  api.post<Invoice>(`/teams/${team}/invoices/${number}/finalize`);
+export const downloadInvoice = (team: string, number: string, format: 'json' | 'csv' | 'pdf' = 'pdf') =>
+  api.get(`/teams/${team}/invoices/${number}/download`, { params: { format }, responseType: 'blob' });
+
 export const getTeamReport = (name: string, window = '30d') =>
   api.get<Report>(`/reports/team/${name}`, { params: { window } });
 export const exportTeamReport = (name: string, window = '30d') =>
@@ -739,8 +1054,17 @@ export interface ResourceDefinition {
   sortOrder: number;     // Sort order (lower = first)
   showInQuota: boolean;  // Whether to show in quota settings
   price: number;         // Price per unit per hour
+  productLabel?: string; // Node label holding the accelerator model, defaults to <name>.product
+  productPrices?: Record<string, number>;  // Price per unit per hour by accelerator model or StorageClass
 }
 
+// Billing-only resources, priced from OpenCost allocations
+export const BILLING_ONLY_RESOURCES = [
+  'bison.io/persistent-storage',  // per GB·hour, productPrices by StorageClass
+  'bison.io/network-egress',      // per GB sent
+  'bison.io/load-balancer',       // per load balancer hour
+];
+
 export interface DiscoveredResource {
   name: string;
   capacity: number;